@@ -0,0 +1,17 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers routers for the event-sourced history of accounts, version v1
+func RegisterAccountAggregateRoutes(router *mux.Router, aggregateService *service.AccountAggregateService, authorizer *auth.Authorizer) {
+	aggregateController := controller.NewAccountAggregateController(aggregateService)
+
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}/history", authorizer.RequireAccount(auth.PermissionAccountsRead, aggregateController.ListHistoryHandler)).Methods("GET")
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}/state", authorizer.RequireAccount(auth.PermissionAccountsRead, aggregateController.GetStateHandler)).Methods("GET")
+}
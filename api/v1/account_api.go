@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"internal-transfers/auth"
 	"internal-transfers/common"
 	"internal-transfers/controller"
 	"internal-transfers/service"
@@ -9,12 +10,10 @@ import (
 )
 
 // Registers routers for accounts, version v1
-func RegisterAccountRoutes(router *mux.Router, accountService *service.AccountService, auditLogger *common.AuditLogger) {
+func RegisterAccountRoutes(router *mux.Router, accountService *service.AccountService, auditLogger *common.AuditLogger, authorizer *auth.Authorizer) {
 	accountController := controller.NewAccountController(accountService, auditLogger)
+	accountController.Authorizer = authorizer
 
-	v1 := router.PathPrefix("/api/v1").Subrouter()
-	v1.HandleFunc("/accounts/{account_id}", accountController.UpdateAccountHandler).Methods("PUT")
-	router.HandleFunc("/api/v1/accounts/{account_id:[0-9]+}", accountController.GetAccountHandler).Methods("GET")
-	router.HandleFunc("/api/v1/accounts/{account_id:[0-9]+}", accountController.GetAccountHandler).Methods("GET")
-	router.HandleFunc("/api/v1/accounts", accountController.CreateAccountHandler).Methods("POST")
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}", authorizer.Require(auth.PermissionAccountsRead, accountController.GetAccountHandler)).Methods("GET")
+	router.Handle("/api/v1/accounts", authorizer.Require(auth.PermissionAccountsCreate, accountController.CreateAccountHandler)).Methods("POST")
 }
@@ -0,0 +1,17 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers routers for account event streams, version v1
+func RegisterAccountEventRoutes(router *mux.Router, accountEventService *service.AccountEventService, accountService *service.AccountService, authorizer *auth.Authorizer) {
+	accountEventController := controller.NewAccountEventController(accountEventService, accountService)
+
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}/events", authorizer.RequireAccount(auth.PermissionAccountsRead, accountEventController.StreamEventsHandler)).Methods("GET")
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}/events/ws", authorizer.RequireAccount(auth.PermissionAccountsRead, accountEventController.WebSocketHandler)).Methods("GET")
+}
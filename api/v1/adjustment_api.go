@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers routers for balance adjustments, version v1
+func RegisterAdjustmentRoutes(router *mux.Router, adjustmentService *service.AdjustmentService, approvalService *service.ApprovalService,
+	authorizer *auth.Authorizer) {
+	adjustmentController := controller.NewAdjustmentController(adjustmentService, approvalService)
+
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}/adjustments", authorizer.Require(auth.PermissionAdjustmentsCreate, adjustmentController.CreateAdjustmentHandler)).Methods("POST")
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}/adjustments", authorizer.RequireAccount(auth.PermissionAccountsRead, adjustmentController.ListAdjustmentsHandler)).Methods("GET")
+}
@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers routers for API key management, version v1
+func RegisterAPIKeyRoutes(router *mux.Router, apiKeyService *service.APIKeyService, authorizer *auth.Authorizer) {
+	apiKeyController := controller.NewAPIKeyController(apiKeyService)
+
+	router.Handle("/api/v1/admin/api-keys", authorizer.Require(auth.PermissionAdminWrite, apiKeyController.CreateAPIKeyHandler)).Methods("POST")
+	router.Handle("/api/v1/admin/api-keys", authorizer.Require(auth.PermissionAdminRead, apiKeyController.ListAPIKeysHandler)).Methods("GET")
+	router.Handle("/api/v1/admin/api-keys/{key_id:[0-9]+}", authorizer.Require(auth.PermissionAdminWrite, apiKeyController.RevokeAPIKeyHandler)).Methods("DELETE")
+}
@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers routers for the maker-checker approval workflow, version v1
+func RegisterApprovalRoutes(router *mux.Router, approvalService *service.ApprovalService, authorizer *auth.Authorizer) {
+	approvalController := controller.NewApprovalController(approvalService)
+
+	router.Handle("/api/v1/approvals", authorizer.Require(auth.PermissionApprovalsRead, approvalController.ListOperationsHandler)).Methods("GET")
+	router.Handle("/api/v1/approvals/{operation_id:[0-9]+}", authorizer.Require(auth.PermissionApprovalsRead, approvalController.GetOperationHandler)).Methods("GET")
+	router.Handle("/api/v1/approvals/{operation_id:[0-9]+}/approve", authorizer.Require(auth.PermissionApprovalsDecide, approvalController.ApproveOperationHandler)).Methods("POST")
+	router.Handle("/api/v1/approvals/{operation_id:[0-9]+}/reject", authorizer.Require(auth.PermissionApprovalsDecide, approvalController.RejectOperationHandler)).Methods("POST")
+}
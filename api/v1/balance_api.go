@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers routers for historical balances, version v1
+func RegisterBalanceRoutes(router *mux.Router, balanceService *service.BalanceService, authorizer *auth.Authorizer) {
+	balanceController := controller.NewBalanceController(balanceService)
+
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}/balance", authorizer.RequireAccount(auth.PermissionAccountsRead, balanceController.GetBalanceHandler)).Methods("GET")
+}
@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+// Registers routers for database monitoring, version v1
+func RegisterDatabaseRoutes(router *mux.Router, db *sqlx.DB, authorizer *auth.Authorizer) {
+	databaseController := controller.NewDatabaseController(db.Stats)
+
+	router.Handle("/api/v1/admin/database/pool", authorizer.Require(auth.PermissionAdminRead, databaseController.GetPoolStatsHandler)).Methods("GET")
+}
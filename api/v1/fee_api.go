@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers admin routers for fee schedules, version v1
+func RegisterFeeRoutes(router *mux.Router, feeService *service.FeeService, authorizer *auth.Authorizer) {
+	feeController := controller.NewFeeController(feeService)
+
+	v1 := router.PathPrefix("/api/v1/admin").Subrouter()
+	v1.Handle("/fee-schedules", authorizer.Require(auth.PermissionAdminRead, feeController.ListFeeSchedulesHandler)).Methods("GET")
+	v1.Handle("/fee-schedules", authorizer.Require(auth.PermissionAdminWrite, feeController.CreateFeeScheduleHandler)).Methods("POST")
+	v1.Handle("/fee-schedules/{fee_schedule_id:[0-9]+}", authorizer.Require(auth.PermissionAdminRead, feeController.GetFeeScheduleHandler)).Methods("GET")
+}
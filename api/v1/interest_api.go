@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers admin routers for account interest, version v1
+func RegisterInterestRoutes(router *mux.Router, interestService *service.InterestService, authorizer *auth.Authorizer) {
+	interestController := controller.NewInterestController(interestService)
+
+	v1 := router.PathPrefix("/api/v1/admin").Subrouter()
+	v1.Handle("/accounts/{account_id:[0-9]+}/interest", authorizer.Require(auth.PermissionAdminRead, interestController.GetInterestConfigHandler)).Methods("GET")
+	v1.Handle("/accounts/{account_id:[0-9]+}/interest", authorizer.Require(auth.PermissionAdminWrite, interestController.ConfigureInterestHandler)).Methods("PUT")
+	v1.Handle("/accounts/{account_id:[0-9]+}/interest/accruals", authorizer.Require(auth.PermissionAdminRead, interestController.ListAccrualsHandler)).Methods("GET")
+}
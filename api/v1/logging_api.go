@@ -0,0 +1,17 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"internal-transfers/controller"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers routers for the application log settings, version v1
+func RegisterLoggingRoutes(router *mux.Router, auditLogger common.Auditor, authorizer *auth.Authorizer) {
+	loggingController := controller.NewLoggingController(common.LogLevel, auditLogger)
+
+	router.Handle("/api/v1/admin/log-level", authorizer.Require(auth.PermissionAdminRead, loggingController.GetLogLevelHandler)).Methods("GET")
+	router.Handle("/api/v1/admin/log-level", authorizer.Require(auth.PermissionAdminWrite, loggingController.SetLogLevelHandler)).Methods("PUT")
+}
@@ -0,0 +1,26 @@
+package v1
+
+import (
+	_ "embed"
+	"internal-transfers/controller"
+	"internal-transfers/openapi"
+
+	"github.com/gorilla/mux"
+)
+
+// The OpenAPI document for every /api/v1 route, kept in sync with the Register*Routes functions by the unit tests
+//
+//go:embed openapi.json
+var OpenAPIDocument []byte
+
+// Parses the embedded OpenAPI document, for validating request bodies
+func LoadOpenAPI() (*openapi.Document, error) {
+	return openapi.Load(OpenAPIDocument)
+}
+
+// Registers the router serving the OpenAPI document, version v1. Any authenticated caller may read it.
+func RegisterOpenAPIRoutes(router *mux.Router) {
+	openAPIController := controller.NewOpenAPIController(OpenAPIDocument)
+
+	router.HandleFunc("/api/v1/openapi.json", openAPIController.GetDocumentHandler).Methods("GET")
+}
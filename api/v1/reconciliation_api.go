@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers admin routers for balance reconciliation, version v1
+func RegisterReconciliationRoutes(router *mux.Router, reconciliationService *service.ReconciliationService, authorizer *auth.Authorizer) {
+	reconciliationController := controller.NewReconciliationController(reconciliationService)
+
+	v1 := router.PathPrefix("/api/v1/admin").Subrouter()
+	v1.Handle("/reconciliations", authorizer.Require(auth.PermissionAdminRead, reconciliationController.ListRunsHandler)).Methods("GET")
+	v1.Handle("/reconciliations", authorizer.Require(auth.PermissionAdminWrite, reconciliationController.RunReconciliationHandler)).Methods("POST")
+	v1.Handle("/reconciliations/{run_id:[0-9]+}", authorizer.Require(auth.PermissionAdminRead, reconciliationController.GetRunHandler)).Methods("GET")
+	v1.Handle("/reconciliations/{run_id:[0-9]+}/mismatches/{mismatch_id:[0-9]+}/approve", authorizer.Require(auth.PermissionAdminWrite, reconciliationController.ApproveCorrectionHandler)).Methods("POST")
+}
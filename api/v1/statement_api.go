@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers routers for account statements, version v1
+func RegisterStatementRoutes(router *mux.Router, statementService *service.StatementService, authorizer *auth.Authorizer) {
+	statementController := controller.NewStatementController(statementService)
+
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}/statements", authorizer.RequireAccount(auth.PermissionAccountsRead, statementController.GetStatementHandler)).Methods("GET")
+}
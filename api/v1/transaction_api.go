@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"internal-transfers/auth"
 	"internal-transfers/controller"
+	"internal-transfers/ratelimit"
 	"internal-transfers/service"
 
 	"github.com/gorilla/mux"
@@ -15,12 +17,20 @@ type TransactionRequest struct {
 	Amount               decimal.Decimal `json:"amount"`
 }
 
-// Registers routers for transactions, version v1
-func RegisterTransactionRoutes(router *mux.Router, transactionService *service.TransactionService) {
+// Registers routers for transactions, version v1. Requests are checked for an HMAC signature when a verifier is given,
+// and transfers are rate limited by source account when a limiter is given.
+func RegisterTransactionRoutes(router *mux.Router, transactionService *service.TransactionService, approvalService *service.ApprovalService,
+	authorizer *auth.Authorizer, signatureVerifier *auth.SignatureVerifier, accountLimiter *ratelimit.Limiter) {
 	transactionController := &controller.TransactionController{
-		Service: transactionService,
+		Service:        transactionService,
+		Approvals:      approvalService,
+		Authorizer:     authorizer,
+		AccountLimiter: accountLimiter,
 	}
 
 	v1 := router.PathPrefix("/api/v1").Subrouter()
-	v1.HandleFunc("/transactions", transactionController.CreateTransactionHandler).Methods("POST")
+	if signatureVerifier != nil {
+		v1.Use(signatureVerifier.Middleware)
+	}
+	v1.Handle("/transactions", authorizer.Require(auth.PermissionTransfersCreate, transactionController.CreateTransactionHandler)).Methods("POST")
 }
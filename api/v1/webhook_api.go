@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Registers routers for webhook endpoints and deliveries, version v1
+func RegisterWebhookRoutes(router *mux.Router, webhookService *service.WebhookService, authorizer *auth.Authorizer) {
+	webhookController := controller.NewWebhookController(webhookService)
+
+	router.Handle("/api/v1/admin/webhooks", authorizer.Require(auth.PermissionAdminWrite, webhookController.CreateEndpointHandler)).Methods("POST")
+	router.Handle("/api/v1/admin/webhooks", authorizer.Require(auth.PermissionAdminRead, webhookController.ListEndpointsHandler)).Methods("GET")
+	router.Handle("/api/v1/admin/webhooks/{endpoint_id:[0-9]+}", authorizer.Require(auth.PermissionAdminWrite, webhookController.DisableEndpointHandler)).Methods("DELETE")
+	router.Handle("/api/v1/admin/webhooks/{endpoint_id:[0-9]+}/replay", authorizer.Require(auth.PermissionAdminWrite, webhookController.ReplayEventsHandler)).Methods("POST")
+	router.Handle("/api/v1/admin/webhooks/deliveries", authorizer.Require(auth.PermissionAdminRead, webhookController.ListDeliveriesHandler)).Methods("GET")
+	router.Handle("/api/v1/admin/webhooks/deliveries/{delivery_id:[0-9]+}/replay", authorizer.Require(auth.PermissionAdminWrite, webhookController.ReplayDeliveryHandler)).Methods("POST")
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// Prefix of every generated API key, used to tell keys apart from JWTs in a bearer header
+const APIKeyPrefix = "itk_"
+
+// Number of leading characters of a key stored in clear so operators can recognise it
+const APIKeyDisplayLength = 12
+
+// Generates a new random API key
+func GenerateAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("could not generate API key: %w", err)
+	}
+	return APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// Hashes an API key for storage and lookup, keys themselves are never stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns the part of a key that is safe to store and display
+func APIKeyDisplayPrefix(key string) string {
+	if len(key) <= APIKeyDisplayLength {
+		return key
+	}
+	return key[:APIKeyDisplayLength]
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Roles a principal can hold
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAuditor  = "auditor"
+	RoleAdmin    = "admin"
+)
+
+// Permissions required by the routes
+const (
+	PermissionAccountsRead      = "accounts:read"
+	PermissionAccountsCreate    = "accounts:create"
+	PermissionTransfersCreate   = "transfers:create"
+	PermissionAdjustmentsCreate = "adjustments:create"
+	PermissionApprovalsRead     = "approvals:read"
+	PermissionApprovalsDecide   = "approvals:decide"
+	PermissionAdminRead         = "admin:read"
+	PermissionAdminWrite        = "admin:write"
+)
+
+// Roles in the order they are documented
+var Roles = []string{RoleViewer, RoleOperator, RoleAuditor, RoleAdmin}
+
+var rolePermissions = map[string][]string{
+	RoleViewer:   {PermissionAccountsRead},
+	RoleOperator: {PermissionAccountsRead, PermissionTransfersCreate},
+	RoleAuditor:  {PermissionAccountsRead, PermissionApprovalsRead, PermissionAdminRead},
+	RoleAdmin: {PermissionAccountsRead, PermissionAccountsCreate, PermissionTransfersCreate, PermissionAdjustmentsCreate,
+		PermissionApprovalsRead, PermissionApprovalsDecide, PermissionAdminRead, PermissionAdminWrite},
+}
+
+// Roles that reach every account, all other roles are limited to the principal's linked accounts
+var unscopedRoles = map[string]bool{
+	RoleAuditor: true,
+	RoleAdmin:   true,
+}
+
+// Checks that a role name is known
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+// Enforces route permissions and account scoping on authenticated requests, auditing every denial.
+// A nil Authorizer allows everything, for handlers used without authentication.
+type Authorizer struct {
+	AuditLogger common.Auditor
+}
+
+func NewAuthorizer(auditLogger common.Auditor) *Authorizer {
+	return &Authorizer{
+		AuditLogger: auditLogger,
+	}
+}
+
+// Wraps a handler so it only runs for principals holding the permission
+func (authorizer *Authorizer) Require(permission string, handler http.HandlerFunc) http.Handler {
+	if authorizer == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if authorizer.checkPermission(writer, request, permission) {
+			handler(writer, request)
+		}
+	})
+}
+
+// Wraps a handler so it only runs for principals holding the permission and access to the {account_id} in its path
+func (authorizer *Authorizer) RequireAccount(permission string, handler http.HandlerFunc) http.Handler {
+	if authorizer == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if !authorizer.checkPermission(writer, request, permission) {
+			return
+		}
+		accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+		if err != nil {
+			http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+			return
+		}
+		if authorizer.CheckAccount(writer, request, accountID) {
+			handler(writer, request)
+		}
+	})
+}
+
+// Reports whether the request's principal may act on the account. On denial it writes
+// 403 Forbidden, audits the attempt and returns false.
+func (authorizer *Authorizer) CheckAccount(writer http.ResponseWriter, request *http.Request, accountID int) bool {
+	if authorizer == nil {
+		return true
+	}
+	principal := PrincipalFromContext(request.Context())
+	if principal == nil {
+		http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if principal.CanAccessAccount(accountID) {
+		return true
+	}
+	authorizer.deny(writer, request, principal, fmt.Sprintf("account %d is not linked to the principal", accountID))
+	return false
+}
+
+func (authorizer *Authorizer) checkPermission(writer http.ResponseWriter, request *http.Request, permission string) bool {
+	principal := PrincipalFromContext(request.Context())
+	if principal == nil {
+		http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if principal.HasPermission(permission) {
+		return true
+	}
+	authorizer.deny(writer, request, principal, fmt.Sprintf("missing permission %s", permission))
+	return false
+}
+
+func (authorizer *Authorizer) deny(writer http.ResponseWriter, request *http.Request, principal *Principal, reason string) {
+	authorizer.auditDenial(request.Context(), principal, request.Method, request.URL.Path, reason)
+	http.Error(writer, "Forbidden", http.StatusForbidden)
+}
+
+func (authorizer *Authorizer) auditDenial(ctx context.Context, principal *Principal, method string, path string, reason string) {
+	if authorizer.AuditLogger != nil {
+		authorizer.AuditLogger.LogActionWithContext(ctx, "Authorization Denied", fmt.Sprintf("Principal: %s, Roles: %v, Method: %s, Path: %s, Reason: %s",
+			principal.ID, principal.Roles, method, path, reason))
+	}
+}
+
+// Returned by the context checks, which serve transports other than HTTP
+var (
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// Checks that the principal in ctx holds the permission. Denials are audited with the transport and the operation,
+// e.g. "gRPC" and the full method name.
+func (authorizer *Authorizer) CheckPermissionContext(ctx context.Context, transport string, operation string, permission string) error {
+	if authorizer == nil {
+		return nil
+	}
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return ErrUnauthenticated
+	}
+	if principal.HasPermission(permission) {
+		return nil
+	}
+	authorizer.auditDenial(ctx, principal, transport, operation, fmt.Sprintf("missing permission %s", permission))
+	return ErrPermissionDenied
+}
+
+// Checks that the principal in ctx may act on the account, auditing denials like CheckPermissionContext
+func (authorizer *Authorizer) CheckAccountContext(ctx context.Context, transport string, operation string, accountID int) error {
+	if authorizer == nil {
+		return nil
+	}
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return ErrUnauthenticated
+	}
+	if principal.CanAccessAccount(accountID) {
+		return nil
+	}
+	authorizer.auditDenial(ctx, principal, transport, operation, fmt.Sprintf("account %d is not linked to the principal", accountID))
+	return ErrPermissionDenied
+}
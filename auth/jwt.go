@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms accepted on bearer tokens, symmetric algorithms are deliberately excluded
+var jwtSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Clock skew tolerated when checking token lifetimes
+const jwtLeeway = 30 * time.Second
+
+// Verifies JWT bearer tokens against locally configured public keys
+type JWTVerifier struct {
+	// Public keys by key ID, a key loaded without an ID is stored under ""
+	Keys map[string]crypto.PublicKey
+	// Required iss and aud claims, not checked when empty
+	Issuer   string
+	Audience string
+}
+
+func NewJWTVerifier(issuer string, audience string) *JWTVerifier {
+	return &JWTVerifier{
+		Keys:     map[string]crypto.PublicKey{},
+		Issuer:   issuer,
+		Audience: audience,
+	}
+}
+
+// Claims read from a bearer token
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Name       string   `json:"name,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+	AccountIDs []int    `json:"account_ids,omitempty"`
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// Loads the signing keys of a JWKS document
+func (verifier *JWTVerifier) LoadJWKS(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read JWKS file: %w", err)
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("could not parse JWKS file %s: %w", path, err)
+	}
+
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return fmt.Errorf("invalid key %q in %s: %w", key.KeyID, path, err)
+		}
+		verifier.Keys[key.KeyID] = publicKey
+	}
+	return nil
+}
+
+// Loads a PEM encoded public key or certificate, identified by the file name without its extension
+func (verifier *JWTVerifier) LoadPublicKeyPEM(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read public key file: %w", err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	var publicKey crypto.PublicKey
+	switch block.Type {
+	case "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("could not parse certificate %s: %w", path, err)
+		}
+		publicKey = certificate.PublicKey
+	case "RSA PUBLIC KEY":
+		publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return fmt.Errorf("could not parse public key %s: %w", path, err)
+	}
+
+	keyID := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	verifier.Keys[keyID] = publicKey
+	return nil
+}
+
+// Verifies a token's signature, lifetime, issuer and audience and returns its subject as a principal
+// with the roles and account_ids claims
+func (verifier *JWTVerifier) Verify(tokenString string) (*Principal, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(jwtSigningMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if verifier.Issuer != "" {
+		options = append(options, jwt.WithIssuer(verifier.Issuer))
+	}
+	if verifier.Audience != "" {
+		options = append(options, jwt.WithAudience(verifier.Audience))
+	}
+
+	claims := &tokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, verifier.keyFor, options...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid token: missing subject")
+	}
+
+	return &Principal{ID: claims.Subject, Name: claims.Name, Method: MethodJWT, Roles: claims.Roles, AccountIDs: claims.AccountIDs}, nil
+}
+
+// Selects the verification key named by the token's kid header, a single configured key is used for tokens without one
+func (verifier *JWTVerifier) keyFor(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+	if key, found := verifier.Keys[keyID]; found {
+		return key, nil
+	}
+	if keyID == "" && len(verifier.Keys) == 1 {
+		for _, key := range verifier.Keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", keyID)
+}
+
+func (key jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch key.KeyType {
+	case "RSA":
+		n, err := decodeJWKInt(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(key.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch key.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", key.Curve)
+		}
+		x, err := decodeJWKInt(key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(key.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if key.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", key.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", key.KeyType)
+	}
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(decoded) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
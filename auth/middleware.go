@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"net/http"
+	"strings"
+)
+
+// Header carrying an API key, keys are also accepted as bearer tokens
+const APIKeyHeader = "X-API-Key"
+
+// Header naming the operator when authentication is disabled
+const OperatorIDHeader = "X-Operator-ID"
+
+var errMissingCredentials = errors.New("missing credentials")
+
+// Returned when credentials could not be checked, as opposed to being invalid
+var ErrCredentialLookup = errors.New("could not look up credentials")
+
+// Looks up API keys by hash, implemented by persistence.APIKeyRepository
+type APIKeyStore interface {
+	GetAPIKeyByHashWithContext(ctx context.Context, keyHash string) (*model.APIKey, error)
+}
+
+// Authenticates requests with an API key, a JWT bearer token or a verified client certificate and attaches the
+// principal to the request context
+type Authenticator struct {
+	APIKeys APIKeyStore
+	// Verifies bearer tokens, only API keys are accepted when nil
+	JWT *JWTVerifier
+	// Identities of verified mutual TLS client certificates by subject, used when a request carries no other credentials
+	ClientCertificates map[string]ClientCertificateIdentity
+	AuditLogger        common.Auditor
+}
+
+func NewAuthenticator(apiKeys APIKeyStore, jwtVerifier *JWTVerifier, auditLogger common.Auditor) *Authenticator {
+	return &Authenticator{
+		APIKeys:     apiKeys,
+		JWT:         jwtVerifier,
+		AuditLogger: auditLogger,
+	}
+}
+
+// Rejects requests without valid credentials with 401 Unauthorized
+func (authenticator *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		principal, err := authenticator.Authenticate(request)
+		if errors.Is(err, ErrCredentialLookup) {
+			common.LogErrorWithContext(request.Context(), "Error looking up credentials", "error", err)
+			http.Error(writer, "Authentication is temporarily unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			if authenticator.AuditLogger != nil {
+				authenticator.AuditLogger.LogActionWithContext(request.Context(), "Authentication Failed", fmt.Sprintf("Method: %s, Path: %s, Remote Address: %s, Reason: %v",
+					request.Method, request.URL.Path, request.RemoteAddr, err))
+			}
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="internal-transfers"`)
+			http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(writer, request.WithContext(WithPrincipal(request.Context(), principal)))
+	})
+}
+
+// Returns the principal for the request's credentials
+func (authenticator *Authenticator) Authenticate(request *http.Request) (*Principal, error) {
+	return authenticator.AuthenticateCredentials(request.Context(), request.Header.Get(APIKeyHeader), request.Header.Get("Authorization"), request.TLS)
+}
+
+// Returns the principal for an API key, an Authorization header value or, when neither is given, the verified client
+// certificate of the connection. Used for transports other than HTTP, e.g. with gRPC metadata.
+func (authenticator *Authenticator) AuthenticateCredentials(ctx context.Context, apiKey string, authorization string, connection *tls.ConnectionState) (*Principal, error) {
+	if apiKey != "" {
+		return authenticator.authenticateAPIKey(ctx, apiKey)
+	}
+
+	scheme, credentials, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(credentials) == "" {
+		principal, err := authenticator.authenticateClientCertificate(connection)
+		if err != nil || principal != nil {
+			return principal, err
+		}
+		return nil, errMissingCredentials
+	}
+	credentials = strings.TrimSpace(credentials)
+
+	if strings.HasPrefix(credentials, APIKeyPrefix) {
+		return authenticator.authenticateAPIKey(ctx, credentials)
+	}
+	if authenticator.JWT == nil {
+		return nil, errors.New("bearer tokens are not accepted")
+	}
+	return authenticator.JWT.Verify(credentials)
+}
+
+func (authenticator *Authenticator) authenticateAPIKey(ctx context.Context, key string) (*Principal, error) {
+	apiKey, err := authenticator.APIKeys.GetAPIKeyByHashWithContext(ctx, HashAPIKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCredentialLookup, err)
+	}
+	if apiKey == nil {
+		return nil, fmt.Errorf("unknown API key %s", APIKeyDisplayPrefix(key))
+	}
+	if apiKey.RevokedAt != nil {
+		return nil, fmt.Errorf("API key %d was revoked", apiKey.KeyID)
+	}
+
+	accountIDs := make([]int, 0, len(apiKey.AccountIDs))
+	for _, accountID := range apiKey.AccountIDs {
+		accountIDs = append(accountIDs, int(accountID))
+	}
+	return &Principal{
+		ID:         fmt.Sprintf("api-key:%d", apiKey.KeyID),
+		Name:       apiKey.Name,
+		Method:     MethodAPIKey,
+		Roles:      apiKey.Roles,
+		AccountIDs: accountIDs,
+	}, nil
+}
+
+// Attaches an admin principal named by the X-Operator-ID header to every request, used when authentication
+// is disabled so authorization and audit records keep working
+func AnonymousMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		principal := &Principal{ID: request.Header.Get(OperatorIDHeader), Name: "anonymous", Method: MethodNone, Roles: []string{RoleAdmin}}
+		next.ServeHTTP(writer, request.WithContext(WithPrincipal(request.Context(), principal)))
+	})
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Ways a principal can be authenticated
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+	// Verified mutual TLS client certificate
+	MethodClientCertificate = "client_certificate"
+	// Authentication is disabled
+	MethodNone = "none"
+)
+
+// The authenticated caller of a request
+type Principal struct {
+	// Stable identifier used in audit records, api-key:<key_id> for API keys, the token subject for JWTs
+	// and cert:<subject> for client certificates
+	ID     string
+	Name   string
+	Method string
+	Roles  []string
+	// Accounts the principal may read and debit, only consulted for roles limited to linked accounts
+	AccountIDs []int
+}
+
+// Reports whether any of the principal's roles grants the permission
+func (principal *Principal) HasPermission(permission string) bool {
+	for _, role := range principal.Roles {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether the principal may act on the account, either through an unscoped role or a link to it
+func (principal *Principal) CanAccessAccount(accountID int) bool {
+	for _, role := range principal.Roles {
+		if unscopedRoles[role] {
+			return true
+		}
+	}
+	return slices.Contains(principal.AccountIDs, accountID)
+}
+
+type principalContextKey struct{}
+
+// Returns a copy of ctx carrying the authenticated principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// Returns the principal attached by the authentication middleware, nil for unauthenticated requests
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Client certificate verification modes
+const (
+	// Every connection must present a certificate signed by the client CA bundle
+	ClientAuthRequire = "require"
+	// Certificates are verified when presented, callers may still authenticate with API keys or JWTs
+	ClientAuthVerifyIfGiven = "verify_if_given"
+)
+
+// Roles and linked accounts granted to a client certificate subject
+type ClientCertificateIdentity struct {
+	Name       string   `json:"name"`
+	Roles      []string `json:"roles"`
+	AccountIDs []int    `json:"account_ids"`
+}
+
+// Reads client certificate identities from a JSON object keyed by certificate subject (e.g. "CN=payments-svc,O=Acme")
+func LoadClientCertificateIdentities(path string) (map[string]ClientCertificateIdentity, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read client certificate identities: %w", err)
+	}
+
+	var identities map[string]ClientCertificateIdentity
+	if err := json.Unmarshal(content, &identities); err != nil {
+		return nil, fmt.Errorf("could not parse client certificate identities %s: %w", path, err)
+	}
+	for subject, identity := range identities {
+		for _, role := range identity.Roles {
+			if !ValidRole(role) {
+				return nil, fmt.Errorf("client certificate %q has unknown role %q", subject, role)
+			}
+		}
+	}
+	return identities, nil
+}
+
+// Returns the principal for the verified client certificate of the connection, nil when none was presented
+func (authenticator *Authenticator) authenticateClientCertificate(connection *tls.ConnectionState) (*Principal, error) {
+	if connection == nil || len(connection.VerifiedChains) == 0 {
+		return nil, nil
+	}
+
+	subject := connection.VerifiedChains[0][0].Subject.String()
+	identity, found := authenticator.ClientCertificates[subject]
+	if !found {
+		return nil, fmt.Errorf("client certificate %q is not mapped to a principal", subject)
+	}
+	name := identity.Name
+	if name == "" {
+		name = subject
+	}
+	return &Principal{
+		ID:         "cert:" + subject,
+		Name:       name,
+		Method:     MethodClientCertificate,
+		Roles:      identity.Roles,
+		AccountIDs: identity.AccountIDs,
+	}, nil
+}
+
+// Serves TLS with the certificate, key and client CA bundle read from disk, re-read on Reload.
+// New handshakes pick up reloaded files while established connections keep their session.
+type TLSReloader struct {
+	CertFile string
+	KeyFile  string
+	// PEM bundle of CAs trusted to sign client certificates, client certificates are not requested when empty
+	ClientCAFile string
+	// Base settings (minimum version, cipher suites, client auth) applied to every handshake
+	Base *tls.Config
+
+	certificate atomic.Pointer[tls.Certificate]
+	clientCAs   atomic.Pointer[x509.CertPool]
+}
+
+// Loads the certificate and client CA bundle, failing when any of the files is unusable
+func NewTLSReloader(certFile string, keyFile string, clientCAFile string, base *tls.Config) (*TLSReloader, error) {
+	reloader := &TLSReloader{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: clientCAFile,
+		Base:         base,
+	}
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// Re-reads the certificate, key and client CA bundle. The previous files stay in use when any of them fails to load.
+func (reloader *TLSReloader) Reload() error {
+	certificate, err := tls.LoadX509KeyPair(reloader.CertFile, reloader.KeyFile)
+	if err != nil {
+		return fmt.Errorf("could not load TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if reloader.ClientCAFile != "" {
+		content, err := os.ReadFile(reloader.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("could not read client CA bundle: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(content) {
+			return fmt.Errorf("client CA bundle %s contains no certificates", reloader.ClientCAFile)
+		}
+	}
+
+	reloader.certificate.Store(&certificate)
+	reloader.clientCAs.Store(clientCAs)
+	return nil
+}
+
+// Returns the server TLS configuration, resolving the current certificate and client CAs on each handshake.
+// NextProtos set on the returned configuration, e.g. h2 for gRPC, are offered in every handshake.
+func (reloader *TLSReloader) TLSConfig() *tls.Config {
+	config := reloader.Base.Clone()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshake := reloader.Base.Clone()
+		handshake.Certificates = []tls.Certificate{*reloader.certificate.Load()}
+		handshake.ClientCAs = reloader.clientCAs.Load()
+		handshake.NextProtos = config.NextProtos
+		return handshake, nil
+	}
+	// Checked by ListenAndServeTLS before GetConfigForClient is consulted
+	config.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return reloader.certificate.Load(), nil
+	}
+	return config
+}
+
+// Builds the base server TLS settings from a minimum version (1.2 or 1.3), comma separated cipher suite names
+// and a client certificate mode. Cipher suites only apply to TLS 1.2, Go does not allow configuring TLS 1.3 suites.
+func NewServerTLSConfig(minVersion string, cipherSuites string, clientAuth string) (*tls.Config, error) {
+	config := &tls.Config{}
+
+	switch minVersion {
+	case "", "1.2":
+		config.MinVersion = tls.VersionTLS12
+	case "1.3":
+		config.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported minimum TLS version %q, expected 1.2 or 1.3", minVersion)
+	}
+
+	if cipherSuites != "" {
+		suitesByName := map[string]uint16{}
+		for _, suite := range tls.CipherSuites() {
+			suitesByName[suite.Name] = suite.ID
+		}
+		for _, name := range strings.Split(cipherSuites, ",") {
+			id, found := suitesByName[strings.TrimSpace(name)]
+			if !found {
+				return nil, fmt.Errorf("unknown or insecure cipher suite %q", strings.TrimSpace(name))
+			}
+			config.CipherSuites = append(config.CipherSuites, id)
+		}
+	}
+
+	switch clientAuth {
+	case "":
+		config.ClientAuth = tls.NoClientCert
+	case ClientAuthRequire:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	case ClientAuthVerifyIfGiven:
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, errors.New("client certificate mode must be require or verify_if_given")
+	}
+	return config, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"internal-transfers/config"
+	"internal-transfers/persistence"
+	"log"
+)
+
+// Builds the request authenticator. API keys are always accepted; JWT bearer tokens are accepted
+// when auth.jwt_jwks_file or auth.jwt_public_key_files configures their verification keys, and verified client
+// certificates when tls.client_identities_file maps their subjects to roles.
+func newAuthenticator(cfg *config.Config, apiKeyRepo *persistence.APIKeyRepository, auditLogger *common.AuditLogger) *auth.Authenticator {
+	verifier := auth.NewJWTVerifier(cfg.Auth.JWTIssuer, cfg.Auth.JWTAudience)
+
+	if path := cfg.Auth.JWTJWKSFile; path != "" {
+		if err := verifier.LoadJWKS(path); err != nil {
+			log.Fatalf("Could not load JWT_JWKS_FILE: %v", err)
+		}
+	}
+	for _, path := range cfg.Auth.JWTPublicKeyFiles {
+		if err := verifier.LoadPublicKeyPEM(path); err != nil {
+			log.Fatalf("Could not load JWT_PUBLIC_KEY_FILES: %v", err)
+		}
+	}
+
+	var authenticator *auth.Authenticator
+	if len(verifier.Keys) == 0 {
+		authenticator = auth.NewAuthenticator(apiKeyRepo, nil, auditLogger)
+	} else {
+		authenticator = auth.NewAuthenticator(apiKeyRepo, verifier, auditLogger)
+	}
+
+	if path := cfg.TLS.ClientIdentitiesFile; path != "" {
+		identities, err := auth.LoadClientCertificateIdentities(path)
+		if err != nil {
+			log.Fatalf("Could not load TLS_CLIENT_IDENTITIES_FILE: %v", err)
+		}
+		authenticator.ClientCertificates = identities
+	}
+	return authenticator
+}
+
+// Builds the HMAC request signature verifier for transfer calls from auth.hmac_clients_file, nil when signing is not configured
+func newSignatureVerifier(cfg *config.Config, auditLogger *common.AuditLogger) *auth.SignatureVerifier {
+	if cfg.Auth.HMACClientsFile == "" {
+		return nil
+	}
+
+	secrets, err := auth.LoadSigningSecrets(cfg.Auth.HMACClientsFile)
+	if err != nil {
+		log.Fatalf("Could not load HMAC_CLIENTS_FILE: %v", err)
+	}
+	return auth.NewSignatureVerifier(secrets, cfg.Auth.HMACMaxSkew, cfg.Auth.HMACRequired, auditLogger)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/auth"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"os"
+	"strings"
+)
+
+// Issues an admin API key from the command line, so the first key can be created before any client can authenticate.
+// Prints the key, which is not shown again.
+func runCreateAPIKeyCommand(apiKeyService *service.APIKeyService, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: create-api-key <name>")
+		return 2
+	}
+
+	input := model.CreateAPIKeyInput{Name: strings.Join(args, " "), Roles: []string{auth.RoleAdmin}}
+	created, err := apiKeyService.CreateAPIKey(context.Background(), input, "command-line")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not create API key: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("API key %d (%s) created. Store it now, it cannot be shown again:\n%s\n", created.KeyID, created.Name, created.Key)
+	return 0
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"internal-transfers/auth"
+	"internal-transfers/config"
+	"internal-transfers/rpc"
+	"log"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Starts the gRPC server on grpc.addr, over TLS with the HTTP server's certificates when they are configured.
+// Returns nil when grpc.addr is empty.
+func startGRPC(grpcConfig config.GRPCConfig, tlsReloader *auth.TLSReloader, interceptor *rpc.Interceptor,
+	accounts *rpc.AccountServer, transfers *rpc.TransferServer) *grpc.Server {
+	if grpcConfig.Addr == "" {
+		return nil
+	}
+
+	var options []grpc.ServerOption
+	if tlsReloader != nil {
+		tlsConfig := tlsReloader.TLSConfig()
+		// gRPC clients require h2 to be negotiated
+		tlsConfig.NextProtos = []string{"h2"}
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := rpc.NewServer(interceptor, accounts, transfers, grpcConfig.Reflection, options...)
+
+	listener, err := net.Listen("tcp", grpcConfig.Addr)
+	if err != nil {
+		log.Fatalf("Could not start gRPC server: %v", err)
+	}
+	go func() {
+		slog.Info("gRPC server starting", "addr", grpcConfig.Addr, "tls", tlsReloader != nil)
+		if err := server.Serve(listener); err != nil {
+			log.Fatalf("Could not start gRPC server: %v", err)
+		}
+	}()
+	return server
+}
+
+// Stops the gRPC server like http.Server.Shutdown: refuses new calls and waits for running ones until ctx ends
+func shutdownGRPC(server *grpc.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return waitOrAbort(ctx, func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				server.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, server.Stop)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"internal-transfers/common"
+	"internal-transfers/config"
+	"internal-transfers/controller"
+	"internal-transfers/metrics"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Wraps the API router with request IDs, access logs and request metrics, and serves the probes and, unless metrics
+// are off or have their own listener, GET /metrics next to it. These sit outside the router so orchestrators and
+// scrapers need no credentials, and their requests are neither logged nor counted as API requests.
+func newRootHandler(router *mux.Router, metricsConfig config.MetricsConfig, healthController *controller.HealthController) http.Handler {
+	var api http.Handler = router
+	if metricsConfig.Enabled {
+		api = metrics.InstrumentRouter(router)
+	}
+
+	handler := http.NewServeMux()
+	handler.Handle("/", common.RequestIDMiddleware(common.AccessLogMiddleware(api)))
+	handler.HandleFunc("GET /healthz", healthController.LivenessHandler)
+	handler.HandleFunc("GET /readyz", healthController.ReadinessHandler)
+	if metricsConfig.Enabled && metricsConfig.Addr == "" {
+		handler.Handle("GET /metrics", metrics.Default.Handler())
+	}
+	return handler
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/config"
+	"internal-transfers/migrations"
+	"internal-transfers/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Builds the readiness checks: the database answers a ping, its schema is not behind this binary (unless
+// db.allow_outdated_schema is set) and the audit log can be written
+func newHealthService(cfg *config.Config, db *sqlx.DB, migrator *migrations.Migrator, auditLogger *common.AuditLogger) *service.HealthService {
+	healthService := service.NewHealthService(cfg.Timeouts.HealthCheck)
+	healthService.Register("database", db.PingContext)
+	healthService.Register("migrations", func(ctx context.Context) error {
+		current, err := migrator.CurrentVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if current < migrator.LatestVersion() && !cfg.DB.AllowOutdatedSchema {
+			return fmt.Errorf("%w: at version %d, expected %d", migrations.ErrSchemaOutdated, current, migrator.LatestVersion())
+		}
+		return nil
+	})
+	healthService.Register("audit_log", func(ctx context.Context) error {
+		return auditLogger.CheckWritable()
+	})
+	return healthService
+}
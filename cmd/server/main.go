@@ -1,61 +1,345 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	v1 "internal-transfers/api/v1"
+	"internal-transfers/auth"
 	"internal-transfers/common"
+	"internal-transfers/config"
+	"internal-transfers/controller"
+	"internal-transfers/jobs"
+	"internal-transfers/migrations"
 	"internal-transfers/persistence"
+	"internal-transfers/ratelimit"
+	"internal-transfers/rpc"
 	"internal-transfers/service"
+	"internal-transfers/tracing"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 // Tasks:
-// 1. Establishes a connection to the database.
-// 2. Initializes an audit logger.
-// 3. Sets up the repositories for account and transaction data.
-// 4. Initializes services for account and transaction logic.
-// 5. Registers the routes for account and transaction API endpoints.
-// 6. Starts the HTTP server on port 8080.
-// 7. Handles graceful server shutdown upon receiving a termination signal (SIGINT, SIGTERM).
+// 1. Loads the configuration from the YAML file, environment and flags (see config.Load) and sets up JSON logging.
+// 2. Sets up tracing (see tracing.Setup) and establishes a connection to the database, waiting for it to come up, and
+//    sizes the connection pool. Runs the migrate command, or checks that the schema is up to date (applying
+//    migrations when configured).
+// 3. Initializes an audit logger.
+// 4. Sets up the repositories for account and transaction data.
+// 5. Initializes services for account and transaction logic.
+// 6. Runs a one-off subcommand instead of serving when one is given (migrate, reconcile, create-api-key,
+//    rebuild-accounts), flags follow the command.
+// 7. Starts the background jobs (interest accrual and posting, balance snapshots, monthly statements, reconciliation,
+//    approval expiry, webhook dispatch, account aggregate snapshots).
+// 8. Registers the routes for account and transaction API endpoints behind authentication, authorization and rate
+//    limits, with request IDs and access logs. Account events are streamed over Server-Sent Events and WebSocket,
+//    live from the transactions committed to the database by any instance.
+//    Request bodies are validated against the OpenAPI document, which is served on /api/v1/openapi.json.
+//    Domain events written to the outbox are delivered to the webhook endpoints registered by admins. Account
+//    history and past states are replayed from the account event store.
+// 9. Starts the HTTP server on http.addr (default :8080), over TLS or mutual TLS when configured, reloading
+//    certificates on SIGHUP. Serves the /healthz and /readyz probes, and Prometheus metrics on /metrics or on
+//    metrics.addr when set. Starts the gRPC server (see the rpc package) on grpc.addr when set, with the same TLS
+//    settings and credentials.
+// 10. Shuts down gracefully upon a termination signal (SIGINT, SIGTERM) within shutdown.timeout: fails readiness, ends
+//     the event streams, stops accepting requests and waits for in-flight ones, gRPC calls and transfers (aborting
+//     them past the deadline), stops the background jobs, flushes traces and the audit log and closes the database,
+//     logging each step.
 
 func main() {
-	db, err := persistence.ConnectToDB()
+	// The first argument names a one-off command unless it is a flag
+	command, args := "", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	cfg, args, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Could not load configuration: %v", err)
+	}
+	if err := common.SetupLogging(cfg.Log.Format, cfg.Log.Level, os.Stderr); err != nil {
+		log.Fatalf("Could not set up logging: %v", err)
+	}
+	service.OperationTimeout = cfg.Timeouts.Operation
+	service.RetryAttempts = cfg.Retries.Attempts
+	service.RetryDelay = cfg.Retries.Delay
+
+	shutdownTracing, err := tracing.Setup(cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Could not set up tracing: %v", err)
+	}
+
+	db, err := persistence.ConnectToDB(cfg.DB)
 	if err != nil {
 		log.Fatalf("Could not connect to the database: %v", err)
 	}
-	defer db.Close()
 
-	auditLogger, err := common.NewAuditLogger()
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Could not load migrations: %v", err)
+	}
+	if command == "migrate" {
+		exitCode := runMigrateCommand(migrator, args)
+		db.Close()
+		os.Exit(exitCode)
+	}
+	if err := ensureSchema(migrator, cfg.DB.MigrateOnStart, cfg.DB.AllowOutdatedSchema); err != nil {
+		log.Fatalf("%v (run the migrate up command, or set DB_MIGRATE_ON_START)", err)
+	}
+
+	auditLogger, err := common.NewAuditLogger(cfg.Audit.LogFile)
 	if err != nil {
 		log.Fatalf("Could not initialize audit logger: %v", err)
 	}
 
 	accountRepo := persistence.NewAccountRepository(db)
 	transactionRepo := persistence.NewTransactionRepository(db)
+	feeScheduleRepo := persistence.NewFeeScheduleRepository(db)
+	interestRepo := persistence.NewInterestRepository(db)
+	snapshotRepo := persistence.NewBalanceSnapshotRepository(db)
+	reconciliationRepo := persistence.NewReconciliationRepository(db)
+	adjustmentRepo := persistence.NewAdjustmentRepository(db)
+	approvalRepo := persistence.NewApprovalRepository(db)
+	apiKeyRepo := persistence.NewAPIKeyRepository(db)
+	outboxRepo := persistence.NewOutboxRepository(db)
+	webhookRepo := persistence.NewWebhookRepository(db)
+	eventStoreRepo := persistence.NewAccountEventStoreRepository(db)
+
+	// Fees, interest, reconciliation corrections and adjustments are only enabled when their system accounts are configured
+	feeAccountID := cfg.Features.FeeRevenueAccountID
+	interestExpenseAccountID := cfg.Features.InterestExpenseAccountID
+	suspenseAccountID := cfg.Features.SuspenseAccountID
 
 	accountService := service.NewAccountService(accountRepo, auditLogger)
+	feeService := service.NewFeeService(feeScheduleRepo, auditLogger, feeAccountID)
 	transactionService := service.NewTransactionService(accountRepo, transactionRepo, auditLogger)
+	transactionService.FeeService = feeService
+	transactionService.RegisterSystemAccount(feeAccountID)
+	transactionService.RegisterSystemAccount(interestExpenseAccountID)
+	transactionService.RegisterSystemAccount(suspenseAccountID)
+	transactionFeed := service.NewTransactionFeed()
+	transactionService.Feed = transactionFeed
+	transactionService.Outbox = outboxRepo
+	interestService := service.NewInterestService(interestRepo, accountRepo, transactionRepo, transactionService, auditLogger,
+		interestExpenseAccountID)
+	balanceService := service.NewBalanceService(accountRepo, transactionRepo, snapshotRepo)
+
+	statementService := service.NewStatementService(accountRepo, transactionRepo, balanceService, auditLogger,
+		cfg.Features.StatementOutputDir, cfg.Features.StatementFormats)
+	reconciliationService := service.NewReconciliationService(reconciliationRepo, auditLogger, suspenseAccountID)
+	adjustmentService := service.NewAdjustmentService(adjustmentRepo, accountRepo, auditLogger, suspenseAccountID)
+	accountEventService := service.NewAccountEventService(accountRepo, transactionService)
+	approvalService := service.NewApprovalService(approvalRepo, accountRepo, transactionService, adjustmentService, auditLogger,
+		cfg.Features.ApprovalThreshold, cfg.Features.ApprovalTimeout)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, auditLogger)
+	webhookService := service.NewWebhookService(webhookRepo, auditLogger, cfg.Webhooks.Timeout, cfg.Webhooks.MaxAttempts,
+		cfg.Webhooks.Backoff, cfg.Webhooks.MaxBackoff)
+	webhookService.AllowPrivateEndpoints = cfg.Webhooks.AllowPrivateEndpoints
+	aggregateService := service.NewAccountAggregateService(eventStoreRepo, accountRepo, auditLogger, cfg.EventStore.SnapshotEvery)
+
+	if command != "" {
+		var exitCode int
+		switch command {
+		case "reconcile":
+			exitCode = runReconcileCommand(reconciliationService)
+		case "create-api-key":
+			exitCode = runCreateAPIKeyCommand(apiKeyService, args)
+		case "rebuild-accounts":
+			exitCode = runRebuildAccountsCommand(aggregateService, args)
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown command %q, expected migrate, reconcile, create-api-key or rebuild-accounts\n", command)
+			exitCode = 2
+		}
+		db.Close()
+		os.Exit(exitCode)
+	}
+
+	scheduler := jobs.NewScheduler()
+	scheduler.Register(jobs.Job{
+		Name:       "interest-accrual",
+		Interval:   cfg.Jobs.InterestAccrualInterval,
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			// Accrue on closing balances, so only days that have ended
+			return interestService.AccrueInterest(ctx, time.Now().UTC().AddDate(0, 0, -1))
+		},
+	})
+	scheduler.Register(jobs.Job{
+		Name:     "interest-posting",
+		Interval: cfg.Jobs.InterestPostingInterval,
+		Run:      interestService.PostInterest,
+	})
+	scheduler.Register(jobs.Job{
+		Name:       "balance-snapshot",
+		Interval:   cfg.Jobs.BalanceSnapshotInterval,
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			// Leave a grace period so transfers still committing across midnight land before the day is closed
+			return balanceService.SnapshotBalances(ctx, time.Now().UTC().Add(-5*time.Minute).AddDate(0, 0, -1))
+		},
+	})
+	scheduler.Register(jobs.Job{
+		Name:       "monthly-statements",
+		Interval:   cfg.Jobs.StatementGenerationInterval,
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			return statementService.GenerateMonthlyStatements(ctx, time.Now())
+		},
+	})
+	scheduler.Register(jobs.Job{
+		Name:     "reconciliation",
+		Interval: cfg.Jobs.ReconciliationInterval,
+		Run: func(ctx context.Context) error {
+			_, err := reconciliationService.Reconcile(ctx)
+			return err
+		},
+	})
+	scheduler.Register(jobs.Job{
+		Name:       "approval-expiry",
+		Interval:   cfg.Jobs.ApprovalExpiryInterval,
+		RunOnStart: true,
+		Run:        approvalService.ExpireOperations,
+	})
+	scheduler.Register(jobs.Job{
+		Name:       "webhook-dispatch",
+		Interval:   cfg.Jobs.WebhookDispatchInterval,
+		RunOnStart: true,
+		Run:        webhookService.Dispatch,
+	})
+	scheduler.Register(jobs.Job{
+		Name:     "aggregate-snapshots",
+		Interval: cfg.Jobs.AggregateSnapshotInterval,
+		Run:      aggregateService.SnapshotAccounts,
+	})
+	scheduler.Start(context.Background())
+
+	// Feeds the event streams with the transactions committed by every instance sharing the database
+	feedCtx, stopFeed := context.WithCancel(context.Background())
+	feedDone := make(chan struct{})
+	go func() {
+		defer close(feedDone)
+		transactionFeed.Follow(feedCtx, transactionRepo, cfg.Jobs.TransactionFeedInterval)
+	}()
 
 	router := mux.NewRouter()
+	router.Use(tracing.Middleware)
+
+	rateLimitStore := ratelimit.NewMemoryStore()
+	var ipLimiter *ratelimit.Limiter
+	if ipLimit := ratelimit.PerMinute(cfg.RateLimit.IPPerMinute, cfg.RateLimit.IPBurst); ipLimit.Enabled() {
+		ipLimiter = ratelimit.NewIPLimiter(rateLimitStore, ipLimit)
+		router.Use(ipLimiter.Middleware)
+	}
+
+	var authenticator *auth.Authenticator
+	if cfg.Auth.Disabled {
+		slog.Warn("AUTH_DISABLED is set, every route is served without authentication")
+		router.Use(auth.AnonymousMiddleware)
+	} else {
+		authenticator = newAuthenticator(cfg, apiKeyRepo, auditLogger)
+		router.Use(authenticator.Middleware)
+	}
+	authorizer := auth.NewAuthorizer(auditLogger)
 
-	v1.RegisterAccountRoutes(router, accountService, auditLogger)
+	clientLimit := ratelimit.PerMinute(cfg.RateLimit.ClientPerMinute, cfg.RateLimit.ClientBurst)
+	var clientLimiter *ratelimit.Limiter
+	if clientLimit.Enabled() {
+		clientLimiter = ratelimit.NewClientLimiter(rateLimitStore, clientLimit)
+		router.Use(clientLimiter.Middleware)
+	}
+	var accountLimiter *ratelimit.Limiter
+	accountLimit := ratelimit.PerMinute(cfg.RateLimit.AccountPerMinute, cfg.RateLimit.AccountBurst)
+	if accountLimit.Enabled() {
+		accountLimiter = ratelimit.NewSourceAccountLimiter(rateLimitStore, accountLimit)
+	}
+	openAPIDocument, err := v1.LoadOpenAPI()
+	if err != nil {
+		log.Fatalf("Error loading the OpenAPI document: %v", err)
+	}
+	router.Use(openAPIDocument.ValidationMiddleware)
+
+	v1.RegisterAccountRoutes(router, accountService, auditLogger, authorizer)
+
+	signatureVerifier := newSignatureVerifier(cfg, auditLogger)
+	v1.RegisterTransactionRoutes(router, transactionService, approvalService, authorizer, signatureVerifier, accountLimiter)
+
+	v1.RegisterFeeRoutes(router, feeService, authorizer)
+
+	v1.RegisterInterestRoutes(router, interestService, authorizer)
+
+	v1.RegisterBalanceRoutes(router, balanceService, authorizer)
+
+	v1.RegisterStatementRoutes(router, statementService, authorizer)
+
+	v1.RegisterAccountEventRoutes(router, accountEventService, accountService, authorizer)
+
+	v1.RegisterAccountAggregateRoutes(router, aggregateService, authorizer)
+
+	v1.RegisterReconciliationRoutes(router, reconciliationService, authorizer)
+
+	v1.RegisterAdjustmentRoutes(router, adjustmentService, approvalService, authorizer)
 
-	v1.RegisterTransactionRoutes(router, transactionService)
+	v1.RegisterApprovalRoutes(router, approvalService, authorizer)
+
+	v1.RegisterAPIKeyRoutes(router, apiKeyService, authorizer)
+
+	v1.RegisterWebhookRoutes(router, webhookService, authorizer)
+
+	v1.RegisterDatabaseRoutes(router, db, authorizer)
+
+	v1.RegisterLoggingRoutes(router, auditLogger, authorizer)
+
+	v1.RegisterOpenAPIRoutes(router)
+
+	metricsServer := startMetrics(db, cfg.Metrics)
+	healthService := newHealthService(cfg, db, migrator, auditLogger)
 
 	server := &http.Server{
-		Addr:    ":8080",
-		Handler: router,
+		Addr:              cfg.HTTP.Addr,
+		Handler:           newRootHandler(router, cfg.Metrics, controller.NewHealthController(healthService)),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	tlsReloader := newTLSReloader(cfg.TLS)
+	if tlsReloader != nil {
+		server.TLSConfig = tlsReloader.TLSConfig()
+		reloadTLSOnSIGHUP(tlsReloader)
 	}
 
+	interceptor := rpc.NewInterceptor(authenticator, authorizer, auditLogger)
+	interceptor.SignatureVerifier = signatureVerifier
+	interceptor.IPLimiter = ipLimiter
+	interceptor.ClientLimiter = clientLimiter
+	interceptor.AccountLimiter = accountLimiter
+	grpcServer := startGRPC(cfg.GRPC, tlsReloader, interceptor,
+		rpc.NewAccountServer(accountService, auditLogger, authorizer),
+		rpc.NewTransferServer(transactionService, approvalService, authorizer))
+
 	go func() {
-		fmt.Println("Server starting on :8080...")
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		slog.Info("server starting", "addr", server.Addr, "tls", tlsReloader != nil)
+		if tlsReloader != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Could not start server: %v", err)
 		}
 	}()
@@ -63,11 +347,47 @@ func main() {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 
-	<-signalChan
+	received := <-signalChan
+	slog.Info("received signal", "signal", received.String())
+
+	slog.Info("shutting down", "timeout", cfg.Shutdown.Timeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+	defer cancel()
 
-	fmt.Println("Shutting down server gracefully...")
-	if err := server.Shutdown(nil); err != nil {
-		log.Fatalf("Server Shutdown failed: %v", err)
+	steps := []shutdownStep{
+		{"mark not ready", func(ctx context.Context) error {
+			healthService.StartDraining()
+			return waitForDrain(ctx, cfg.Shutdown.DrainDelay)
+		}},
+		// Ends the event streams, which would otherwise hold the HTTP and gRPC servers open until the deadline
+		{"close event streams", func(ctx context.Context) error {
+			stopFeed()
+			<-feedDone
+			transactionFeed.Close()
+			return nil
+		}},
+		{"stop accepting requests and finish in-flight ones", func(ctx context.Context) error {
+			return waitOrAbort(ctx, server.Shutdown, func() { server.Close() })
+		}},
+	}
+	if grpcServer != nil {
+		steps = append(steps, shutdownStep{"stop gRPC server", shutdownGRPC(grpcServer)})
+	}
+	steps = append(steps,
+		shutdownStep{"stop background jobs", scheduler.Shutdown},
+		shutdownStep{"finish in-flight transfers", func(ctx context.Context) error {
+			return waitOrAbort(ctx, transactionService.WaitForTransfers, transactionService.AbortTransfers)
+		}},
+	)
+	if metricsServer != nil {
+		steps = append(steps, shutdownStep{"stop metrics listener", metricsServer.Shutdown})
+	}
+	steps = append(steps,
+		shutdownStep{"flush traces", shutdownTracing},
+		shutdownStep{"flush audit log", func(ctx context.Context) error { return auditLogger.Close() }},
+		shutdownStep{"close database", func(ctx context.Context) error { return db.Close() }},
+	)
+	if !runShutdown(shutdownCtx, steps) {
+		os.Exit(1)
 	}
-	fmt.Println("Server gracefully stopped.")
 }
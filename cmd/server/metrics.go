@@ -0,0 +1,35 @@
+package main
+
+import (
+	"internal-transfers/config"
+	"internal-transfers/metrics"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Registers the connection pool gauges and starts the separate metrics listener when one is configured.
+// Returns the listener's server so it can be shut down with the API server, nil when there is none.
+func startMetrics(db *sqlx.DB, metricsConfig config.MetricsConfig) *http.Server {
+	if !metricsConfig.Enabled {
+		return nil
+	}
+	metrics.RegisterDBStats(metrics.Default, db.Stats)
+
+	if metricsConfig.Addr == "" {
+		return nil
+	}
+	handler := http.NewServeMux()
+	handler.Handle("GET /metrics", metrics.Default.Handler())
+	server := &http.Server{Addr: metricsConfig.Addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		slog.Info("metrics listener starting", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Could not start metrics listener: %v", err)
+		}
+	}()
+	return server
+}
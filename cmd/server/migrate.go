@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/migrations"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = "Usage: migrate up | down | status | to <version>"
+
+// Applies, rolls back or lists schema migrations. Exits 1 when a migration fails and 2 on invalid usage.
+func runMigrateCommand(migrator *migrations.Migrator, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	ctx := context.Background()
+
+	var ran []migrations.Migration
+	var err error
+	switch args[0] {
+	case "up":
+		ran, err = migrator.Up(ctx)
+	case "down":
+		ran, err = migrator.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		target, parseErr := strconv.Atoi(args[1])
+		if parseErr != nil {
+			fmt.Fprintf(os.Stderr, "Invalid version %q\n", args[1])
+			return 2
+		}
+		ran, err = migrator.To(ctx, target)
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	for _, migration := range ran {
+		fmt.Printf("Ran %04d_%s (%s)\n", migration.Version, migration.Name, args[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+		return 1
+	}
+	if len(ran) == 0 {
+		fmt.Println("Nothing to migrate")
+	}
+	return printMigrationStatus(ctx, migrator)
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrations.Migrator) int {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not read migration status: %v\n", err)
+		return 1
+	}
+
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, applied)
+	}
+	return 0
+}
+
+// Makes sure the schema matches this binary before serving: applies pending migrations when configured to,
+// otherwise refuses to start on an outdated schema unless that is explicitly allowed
+func ensureSchema(migrator *migrations.Migrator, migrateOnStart bool, allowOutdated bool) error {
+	ctx := context.Background()
+	if migrateOnStart {
+		ran, err := migrator.Up(ctx)
+		for _, migration := range ran {
+			slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+		}
+		return err
+	}
+
+	err := migrator.CheckSchema(ctx)
+	if err != nil && allowOutdated {
+		slog.Warn("starting anyway because the outdated schema is allowed", "error", err)
+		return nil
+	}
+	return err
+}
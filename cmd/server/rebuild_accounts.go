@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/service"
+	"os"
+	"time"
+)
+
+// Regenerates the accounts table from the account event store and prints the accounts that changed. With the
+// dry-run argument nothing is written and the command exits non-zero when rows differ from their events, so it can
+// audit the table from scripts and cron jobs.
+func runRebuildAccountsCommand(aggregateService *service.AccountAggregateService, args []string) int {
+	dryRun := false
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && args[0] == "dry-run":
+		dryRun = true
+	default:
+		fmt.Fprintln(os.Stderr, "Usage: rebuild-accounts [dry-run]")
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	rebuild, err := aggregateService.RebuildProjection(ctx, dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Rebuilding accounts failed: %v\n", err)
+		return 2
+	}
+
+	verb := "rewritten"
+	if dryRun {
+		verb = "would be rewritten"
+	}
+	fmt.Printf("Accounts rebuilt from events: %d replayed, %d %s\n", rebuild.AccountsReplayed, len(rebuild.Differences), verb)
+	for _, difference := range rebuild.Differences {
+		replayed := difference.Replayed
+		if difference.Stored == nil {
+			fmt.Printf("  account %d: missing, replayed balance %s\n", difference.AccountID, replayed.Balance.String())
+			continue
+		}
+		fmt.Printf("  account %d: stored balance %s, replayed %s (type %s, initial balance %s)\n", difference.AccountID,
+			difference.Stored.Balance.String(), replayed.Balance.String(), replayed.AccountType, replayed.InitialBalance.String())
+	}
+	if len(rebuild.AccountsWithoutEvents) > 0 {
+		fmt.Printf("  accounts without events, left as they are: %v\n", rebuild.AccountsWithoutEvents)
+	}
+
+	if dryRun && len(rebuild.Differences) > 0 {
+		return 1
+	}
+	return 0
+}
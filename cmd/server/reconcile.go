@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/service"
+	"os"
+	"time"
+)
+
+// Runs one reconciliation and prints its report. Exits non-zero when mismatches are found,
+// so the command can gate scripts and cron jobs.
+func runReconcileCommand(reconciliationService *service.ReconciliationService) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	run, err := reconciliationService.Reconcile(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Reconciliation failed: %v\n", err)
+		return 2
+	}
+
+	fmt.Printf("Reconciliation run %d: %d accounts checked, %d mismatches\n", run.RunID, run.AccountsChecked, run.MismatchCount)
+	for _, mismatch := range run.Mismatches {
+		fmt.Printf("  mismatch %d, account %d: stored %s, expected %s, ledger %s, difference %s\n    %s\n",
+			mismatch.MismatchID, mismatch.AccountID, mismatch.StoredBalance.String(), mismatch.ExpectedBalance.String(),
+			mismatch.LedgerBalance.String(), mismatch.Difference.String(), mismatch.Details)
+	}
+
+	if run.MismatchCount > 0 {
+		return 1
+	}
+	return 0
+}
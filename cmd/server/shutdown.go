@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Time given to transfers cancelled at the shutdown deadline to roll back
+const abortGracePeriod = 5 * time.Second
+
+// One step of the shutdown sequence
+type shutdownStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Runs the steps in order, sharing the deadline of ctx, and logs the outcome of each. A failed step does not stop
+// the sequence, later steps still release their resources. Returns whether every step succeeded.
+func runShutdown(ctx context.Context, steps []shutdownStep) bool {
+	started := time.Now()
+	succeeded := true
+	for i, step := range steps {
+		stepStarted := time.Now()
+		err := step.run(ctx)
+		attrs := []any{"step", i + 1, "of", len(steps), "name", step.name, "duration_ms", time.Since(stepStarted).Milliseconds()}
+		if err != nil {
+			succeeded = false
+			slog.Error("shutdown step failed", append(attrs, "error", err)...)
+			continue
+		}
+		slog.Info("shutdown step completed", attrs...)
+	}
+	slog.Info("shutdown finished", "succeeded", succeeded, "duration_ms", time.Since(started).Milliseconds())
+	return succeeded
+}
+
+// Waits for the drain delay so load balancers see the failing readiness probe before the listener closes
+func waitForDrain(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Waits for work to finish until the deadline and, past it, aborts it and gives it a short grace period to clean up
+func waitOrAbort(ctx context.Context, wait func(ctx context.Context) error, abort func()) error {
+	err := wait(ctx)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	abort()
+	graceCtx, cancel := context.WithTimeout(context.Background(), abortGracePeriod)
+	defer cancel()
+	if graceErr := wait(graceCtx); graceErr != nil {
+		return errors.New("deadline passed and aborted work did not finish")
+	}
+	return errors.New("deadline passed, remaining work was aborted")
+}
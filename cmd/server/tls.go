@@ -0,0 +1,52 @@
+package main
+
+import (
+	"internal-transfers/auth"
+	"internal-transfers/config"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// Builds the TLS certificate reloader, nil when the server should speak plain HTTP.
+// A client CA bundle enables mutual TLS, with tls.client_auth choosing whether a client certificate is required.
+func newTLSReloader(tlsConfig config.TLSConfig) *auth.TLSReloader {
+	if tlsConfig.CertFile == "" {
+		return nil
+	}
+
+	clientAuth := ""
+	if tlsConfig.ClientCAFile != "" {
+		clientAuth = tlsConfig.ClientAuth
+	}
+	base, err := auth.NewServerTLSConfig(tlsConfig.MinVersion, strings.Join(tlsConfig.CipherSuites, ","), clientAuth)
+	if err != nil {
+		log.Fatalf("Invalid TLS settings: %v", err)
+	}
+
+	reloader, err := auth.NewTLSReloader(tlsConfig.CertFile, tlsConfig.KeyFile, tlsConfig.ClientCAFile, base)
+	if err != nil {
+		log.Fatalf("Could not load TLS files: %v", err)
+	}
+	return reloader
+}
+
+// Reloads the TLS certificate, key and client CA bundle whenever the process receives SIGHUP.
+// A failed reload is logged and the previous files stay in use.
+func reloadTLSOnSIGHUP(reloader *auth.TLSReloader) {
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+
+	go func() {
+		for range reloadChan {
+			if err := reloader.Reload(); err != nil {
+				slog.Warn("TLS reload failed, keeping the current certificate", "error", err)
+				continue
+			}
+			slog.Info("TLS certificate reloaded")
+		}
+	}()
+}
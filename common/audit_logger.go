@@ -1,30 +1,98 @@
 package common
 
 import (
+	"context"
 	"fmt"
+	"internal-transfers/metrics"
 	"log"
 	"os"
+	"sync/atomic"
 )
 
+// Records audit actions, implemented by AuditLogger and mocked in tests
+type Auditor interface {
+	LogAction(action string, details string)
+	// Logs an action taken while serving a request, adding the request ID of ctx
+	LogActionWithContext(ctx context.Context, action string, details string)
+}
+
 // Responsible for logging audit actions
 type AuditLogger struct {
 	logger *log.Logger
+	file   *os.File
+	path   string
+	// Error of the latest write, nil once a write succeeds again
+	lastWriteError atomic.Pointer[error]
+	closed         atomic.Bool
 }
 
-func NewAuditLogger() (*AuditLogger, error) {
-	logFile, err := os.OpenFile("audit.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+// Opens the audit log file for appending, creating it when missing
+func NewAuditLogger(path string) (*AuditLogger, error) {
+	logFile, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
 	if err != nil {
-		return nil, fmt.Errorf("error opening log file: %v", err)
+		return nil, fmt.Errorf("error opening log file: %w", err)
 	}
 	return &AuditLogger{
 		logger: log.New(logFile, "", log.LstdFlags),
+		file:   logFile,
+		path:   path,
 	}, nil
 }
 
+// Flushes the audit log to disk and closes it, records logged afterwards are lost and counted as write failures
+func (a *AuditLogger) Close() error {
+	if a.file == nil || a.closed.Swap(true) {
+		return nil
+	}
+	if err := a.file.Sync(); err != nil {
+		a.file.Close()
+		return fmt.Errorf("error flushing audit log: %w", err)
+	}
+	return a.file.Close()
+}
+
+// Reports whether audit records can be written: the latest write succeeded and the file can still be opened
+// for appending
+func (a *AuditLogger) CheckWritable() error {
+	if a.logger == nil {
+		return fmt.Errorf("audit logger is not initialized")
+	}
+	if a.closed.Load() {
+		return fmt.Errorf("audit log is closed")
+	}
+	if err := a.lastWriteError.Load(); err != nil {
+		return fmt.Errorf("last audit write failed: %w", *err)
+	}
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("audit log is not writable: %w", err)
+	}
+	return file.Close()
+}
+
 // Logs a specific action
 func (a *AuditLogger) LogAction(action string, details string) {
+	a.write(action, details)
+}
+
+// Logs a specific action with the request ID of ctx appended to its details
+func (a *AuditLogger) LogActionWithContext(ctx context.Context, action string, details string) {
+	if requestID := RequestID(ctx); requestID != "" {
+		details = fmt.Sprintf("%s, Request ID: %s", details, requestID)
+	}
+	a.write(action, details)
+}
+
+func (a *AuditLogger) write(action string, details string) {
 	if a.logger != nil {
-		a.logger.Printf("%s: %s\n", action, details)
+		err := a.logger.Output(3, fmt.Sprintf("%s: %s\n", action, details))
+		if err != nil {
+			a.lastWriteError.Store(&err)
+			metrics.AuditWriteFailures.Inc()
+			LogError(fmt.Sprintf("Could not write audit record %q: %v", action, err))
+			return
+		}
+		a.lastWriteError.Store(nil)
 	} else {
 		fmt.Printf("AuditLogger is not initialized properly. Action: %s, Details: %s\n", action, details)
 	}
@@ -1,6 +1,9 @@
 package common
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type AccountError struct {
 	Message string
@@ -9,3 +12,16 @@ type AccountError struct {
 func (accountError *AccountError) Error() string {
 	return fmt.Sprintf("AccountError: %s", accountError.Message)
 }
+
+// Returned when caller supplied input is rejected, handlers map it to 400 Bad Request
+type ValidationError struct {
+	Message string
+}
+
+func (validationError *ValidationError) Error() string {
+	return fmt.Sprintf("ValidationError: %s", validationError.Message)
+}
+
+// Returned when a debit would take an account below zero, by the balance check before a transfer and by the guarded
+// balance update posting it
+var ErrInsufficientBalance = errors.New("insufficient balance in source account")
@@ -1,15 +1,94 @@
 package common
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Level of the application logger, changeable at runtime through the admin API
+var LogLevel = new(slog.LevelVar)
+
+// Installs a slog logger writing JSON (or text) lines at LogLevel as the default logger. Messages of the standard
+// log package go through it too, at info level.
+func SetupLogging(format string, level string, output io.Writer) error {
+	parsed, err := ParseLogLevel(level)
+	if err != nil {
+		return err
+	}
+	LogLevel.Set(parsed)
+
+	options := &slog.HandlerOptions{Level: LogLevel}
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(output, options)
+	case "text":
+		handler = slog.NewTextHandler(output, options)
+	default:
+		return fmt.Errorf("unknown log format %q, expected json or text", format)
+	}
+	slog.SetDefault(slog.New(&contextHandler{Handler: handler}))
+	return nil
+}
+
+// Parses debug, info, warn or error
+func ParseLogLevel(level string) (slog.Level, error) {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", level)
+	}
+	return parsed, nil
+}
+
+// Formats a level the way ParseLogLevel accepts it
+func FormatLogLevel(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// Adds the request ID and trace ID found in the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (handler *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	return handler.Handler.Handle(ctx, record)
+}
+
+func (handler *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: handler.Handler.WithAttrs(attrs)}
+}
+
+func (handler *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: handler.Handler.WithGroup(name)}
+}
+
 // Used for logging general information
 func LogInfo(message string) {
-	log.Println("INFO:", message)
+	slog.Info(message)
 }
 
 // Used for logging errors
 func LogError(message string) {
-	log.Println("ERROR:", message)
+	slog.Error(message)
+}
+
+// Logs general information with the request ID of ctx
+func LogInfoWithContext(ctx context.Context, message string, attrs ...any) {
+	slog.InfoContext(ctx, message, attrs...)
+}
+
+// Logs an error with the request ID of ctx
+func LogErrorWithContext(ctx context.Context, message string, attrs ...any) {
+	slog.ErrorContext(ctx, message, attrs...)
 }
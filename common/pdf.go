@@ -0,0 +1,118 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page in points, text set in 9pt Courier so columns line up
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 40
+	pdfFontSize   = 9
+	pdfLeading    = 12
+)
+
+// Minimal text-only PDF writer used to render reports locally without external services.
+// Lines are laid out top to bottom and flow onto new pages automatically.
+type PDFDocument struct {
+	pages [][]string
+}
+
+func NewPDFDocument() *PDFDocument {
+	return &PDFDocument{pages: [][]string{{}}}
+}
+
+// Appends a line of text, starting a new page when the current one is full
+func (doc *PDFDocument) AddLine(text string) {
+	linesPerPage := (pdfPageHeight - 2*pdfMargin) / pdfLeading
+	last := len(doc.pages) - 1
+	if len(doc.pages[last]) >= linesPerPage {
+		doc.NewPage()
+		last++
+	}
+	doc.pages[last] = append(doc.pages[last], text)
+}
+
+// Starts a new page, unless the current page is still empty
+func (doc *PDFDocument) NewPage() {
+	if len(doc.pages[len(doc.pages)-1]) > 0 {
+		doc.pages = append(doc.pages, []string{})
+	}
+}
+
+// Serialises the document as PDF 1.4
+func (doc *PDFDocument) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	startObject := func() int {
+		offsets = append(offsets, buf.Len())
+		id := len(offsets)
+		fmt.Fprintf(&buf, "%d 0 obj\n", id)
+		return id
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Object numbers: 1 catalog, 2 page tree, 3 font, then a page and its content stream per page
+	startObject()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	kids := make([]string, len(doc.pages))
+	for i := range doc.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	startObject()
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(doc.pages))
+
+	startObject()
+	buf.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>\nendobj\n")
+
+	for i, lines := range doc.pages {
+		startObject()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+			pdfPageWidth, pdfPageHeight, 5+2*i)
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escapePDFText(line))
+		}
+		content.WriteString("ET\n")
+
+		startObject()
+		fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n", content.Len())
+		buf.Write(content.Bytes())
+		buf.WriteString("endstream\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// Escapes PDF string delimiters and replaces characters outside printable ASCII
+func escapePDFText(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r < 32 || r > 126:
+			builder.WriteRune('?')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
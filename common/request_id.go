@@ -0,0 +1,121 @@
+package common
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Header carrying the request ID, accepted from clients and returned on every response
+const RequestIDHeader = "X-Request-ID"
+
+// Incoming request IDs are kept when they look like an ID, anything else is replaced so it cannot forge log lines
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestIDKey struct{}
+
+// Returns ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// Returns the request ID of ctx, empty outside a request
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Takes the request ID from X-Request-ID or generates one, adds it to the request context and the response
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requestID := NormalizeRequestID(request.Header.Get(RequestIDHeader))
+		writer.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(writer, request.WithContext(WithRequestID(request.Context(), requestID)))
+	})
+}
+
+// Returns the client's request ID when it looks like an ID, a newly generated one otherwise
+func NormalizeRequestID(requestID string) string {
+	if requestIDPattern.MatchString(requestID) {
+		return requestID
+	}
+	return newRequestID()
+}
+
+func newRequestID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// Logs one line per request with its status, size and duration. Wrapped by RequestIDMiddleware so the line carries
+// the request ID.
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		recorder := &accessRecorder{ResponseWriter: writer, status: http.StatusOK}
+		started := time.Now()
+		next.ServeHTTP(recorder, request)
+
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(request.Context(), level, "request",
+			"method", request.Method,
+			"path", request.URL.Path,
+			"status", recorder.status,
+			"bytes", recorder.bytes,
+			"duration_ms", float64(time.Since(started).Microseconds())/1000,
+			"remote_addr", request.RemoteAddr,
+			"user_agent", request.UserAgent())
+	})
+}
+
+// Remembers the status code and counts the body bytes written by the handler
+type accessRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (recorder *accessRecorder) WriteHeader(status int) {
+	if !recorder.wroteHeader {
+		recorder.status = status
+		recorder.wroteHeader = true
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *accessRecorder) Write(body []byte) (int, error) {
+	recorder.wroteHeader = true
+	written, err := recorder.ResponseWriter.Write(body)
+	recorder.bytes += written
+	return written, err
+}
+
+func (recorder *accessRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+func (recorder *accessRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Lets WebSocket upgrades take over the connection, recorded as 101 Switching Protocols
+func (recorder *accessRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buffer, err := http.NewResponseController(recorder.ResponseWriter).Hijack()
+	if err == nil && !recorder.wroteHeader {
+		recorder.status = http.StatusSwitchingProtocols
+		recorder.wroteHeader = true
+	}
+	return conn, buffer, err
+}
@@ -0,0 +1,307 @@
+package config
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Settings of the server and its one-off commands. Each setting can come from the YAML file, the environment
+// variable named by its env tag, or the command-line flag named after its YAML path (db.host is --db-host).
+type Config struct {
+	DB         DBConfig         `yaml:"db"`
+	HTTP       HTTPConfig       `yaml:"http"`
+	GRPC       GRPCConfig       `yaml:"grpc"`
+	TLS        TLSConfig        `yaml:"tls"`
+	Auth       AuthConfig       `yaml:"auth"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
+	Timeouts   TimeoutConfig    `yaml:"timeouts"`
+	Shutdown   ShutdownConfig   `yaml:"shutdown"`
+	Retries    RetryConfig      `yaml:"retries"`
+	Audit      AuditConfig      `yaml:"audit"`
+	Log        LogConfig        `yaml:"log"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	Features   FeatureConfig    `yaml:"features"`
+	Webhooks   WebhookConfig    `yaml:"webhooks"`
+	EventStore EventStoreConfig `yaml:"event_store"`
+	Jobs       JobConfig        `yaml:"jobs"`
+}
+
+// PostgreSQL connection, pool and startup settings
+type DBConfig struct {
+	// Full connection string, either a postgres:// URL or key=value pairs. Used as-is instead of the fields below
+	// up to SSLKey when set.
+	DSN      string `yaml:"dsn" env:"DATABASE_URL"`
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	// disable, require, verify-ca or verify-full
+	SSLMode     string `yaml:"sslmode" env:"DB_SSLMODE"`
+	SSLRootCert string `yaml:"sslrootcert" env:"DB_SSLROOTCERT"`
+	SSLCert     string `yaml:"sslcert" env:"DB_SSLCERT"`
+	SSLKey      string `yaml:"sslkey" env:"DB_SSLKEY"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+
+	// Attempts to reach the database at startup, waiting ConnectBackoff after the first failure and doubling
+	// the wait up to ConnectMaxBackoff
+	ConnectAttempts   int           `yaml:"connect_attempts" env:"DB_CONNECT_ATTEMPTS"`
+	ConnectBackoff    time.Duration `yaml:"connect_backoff" env:"DB_CONNECT_BACKOFF"`
+	ConnectMaxBackoff time.Duration `yaml:"connect_max_backoff" env:"DB_CONNECT_MAX_BACKOFF"`
+
+	// Applies pending migrations at startup instead of refusing to start on an outdated schema
+	MigrateOnStart bool `yaml:"migrate_on_start" env:"DB_MIGRATE_ON_START"`
+	// Starts on an outdated schema with a warning, for rolling deployments that migrate separately
+	AllowOutdatedSchema bool `yaml:"allow_outdated_schema" env:"DB_ALLOW_OUTDATED_SCHEMA"`
+}
+
+// HTTP server settings
+type HTTPConfig struct {
+	Addr              string        `yaml:"addr" env:"LISTEN_ADDR"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+}
+
+// gRPC server settings, it shares the TLS and authentication settings of the HTTP server
+type GRPCConfig struct {
+	// Listen address of the gRPC server, e.g. :9443. The gRPC server is off when empty.
+	Addr string `yaml:"addr" env:"GRPC_ADDR"`
+	// Registers the reflection service so tools such as grpcurl can discover the API
+	Reflection bool `yaml:"reflection" env:"GRPC_REFLECTION"`
+}
+
+// TLS and mutual TLS settings, the server speaks plain HTTP when no certificate is set
+type TLSConfig struct {
+	CertFile             string   `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile              string   `yaml:"key_file" env:"TLS_KEY_FILE"`
+	MinVersion           string   `yaml:"min_version" env:"TLS_MIN_VERSION"`
+	CipherSuites         []string `yaml:"cipher_suites" env:"TLS_CIPHER_SUITES"`
+	ClientCAFile         string   `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	ClientAuth           string   `yaml:"client_auth" env:"TLS_CLIENT_AUTH"`
+	ClientIdentitiesFile string   `yaml:"client_identities_file" env:"TLS_CLIENT_IDENTITIES_FILE"`
+}
+
+// Authentication and request signing settings
+type AuthConfig struct {
+	// Serves every route without authentication, for local development only
+	Disabled          bool          `yaml:"disabled" env:"AUTH_DISABLED"`
+	JWTIssuer         string        `yaml:"jwt_issuer" env:"JWT_ISSUER"`
+	JWTAudience       string        `yaml:"jwt_audience" env:"JWT_AUDIENCE"`
+	JWTJWKSFile       string        `yaml:"jwt_jwks_file" env:"JWT_JWKS_FILE"`
+	JWTPublicKeyFiles []string      `yaml:"jwt_public_key_files" env:"JWT_PUBLIC_KEY_FILES"`
+	HMACClientsFile   string        `yaml:"hmac_clients_file" env:"HMAC_CLIENTS_FILE"`
+	HMACRequired      bool          `yaml:"hmac_required" env:"HMAC_REQUIRED"`
+	HMACMaxSkew       time.Duration `yaml:"hmac_max_skew" env:"HMAC_MAX_SKEW"`
+}
+
+// Token bucket limits, a zero per-minute rate disables the limit
+type RateLimitConfig struct {
+	// Applied by remote IP before authentication, so it also limits failed authentication attempts
+	IPPerMinute      int `yaml:"ip_per_minute" env:"RATE_LIMIT_IP_PER_MINUTE"`
+	IPBurst          int `yaml:"ip_burst" env:"RATE_LIMIT_IP_BURST"`
+	ClientPerMinute  int `yaml:"client_per_minute" env:"RATE_LIMIT_CLIENT_PER_MINUTE"`
+	ClientBurst      int `yaml:"client_burst" env:"RATE_LIMIT_CLIENT_BURST"`
+	AccountPerMinute int `yaml:"account_per_minute" env:"RATE_LIMIT_ACCOUNT_PER_MINUTE"`
+	AccountBurst     int `yaml:"account_burst" env:"RATE_LIMIT_ACCOUNT_BURST"`
+}
+
+type TimeoutConfig struct {
+	// Deadline of each service operation against the database
+	Operation time.Duration `yaml:"operation" env:"OPERATION_TIMEOUT"`
+	// Deadline of each readiness check (database ping, schema version, audit log)
+	HealthCheck time.Duration `yaml:"health_check" env:"HEALTH_CHECK_TIMEOUT"`
+}
+
+// Graceful shutdown after SIGINT or SIGTERM
+type ShutdownConfig struct {
+	// Deadline of the whole shutdown; requests and transfers still running past it are aborted
+	Timeout time.Duration `yaml:"timeout" env:"SHUTDOWN_TIMEOUT"`
+	// Pause between failing readiness and closing the listener, so load balancers stop sending requests first.
+	// Counts towards Timeout.
+	DrainDelay time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+}
+
+// Retries of account and transfer operations that fail with a timeout or deadlock
+type RetryConfig struct {
+	Attempts int           `yaml:"attempts" env:"RETRY_ATTEMPTS"`
+	Delay    time.Duration `yaml:"delay" env:"RETRY_DELAY"`
+}
+
+type AuditConfig struct {
+	LogFile string `yaml:"log_file" env:"AUDIT_LOG_FILE"`
+}
+
+// Application log, the level can also be changed at runtime through PUT /api/v1/admin/log-level
+type LogConfig struct {
+	// debug, info, warn or error
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// json or text
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+// Prometheus metrics endpoint
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled" env:"METRICS_ENABLED"`
+	// Serves /metrics on its own plain HTTP listener instead of the API address when set, e.g. 127.0.0.1:9090
+	Addr string `yaml:"addr" env:"METRICS_ADDR"`
+}
+
+// OpenTelemetry tracing
+type TracingConfig struct {
+	// Where spans go: none, otlp (OTLP over HTTP), stdout or file
+	Exporter    string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	// Collector URL, e.g. http://localhost:4318/v1/traces; the exporter's OTEL_EXPORTER_OTLP_* variables apply when empty
+	OTLPEndpoint string `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	// File the file exporter appends spans to, one JSON object per line
+	File             string `yaml:"file" env:"TRACING_FILE"`
+	RedactAccountIDs bool   `yaml:"redact_account_ids" env:"TRACING_REDACT_ACCOUNT_IDS"`
+	RedactAmounts    bool   `yaml:"redact_amounts" env:"TRACING_REDACT_AMOUNTS"`
+}
+
+// Settings of optional features. Fees, interest, reconciliation corrections and adjustments are only enabled
+// when their system accounts are configured.
+type FeatureConfig struct {
+	FeeRevenueAccountID      int             `yaml:"fee_revenue_account_id" env:"FEE_REVENUE_ACCOUNT_ID"`
+	InterestExpenseAccountID int             `yaml:"interest_expense_account_id" env:"INTEREST_EXPENSE_ACCOUNT_ID"`
+	SuspenseAccountID        int             `yaml:"suspense_account_id" env:"SUSPENSE_ACCOUNT_ID"`
+	StatementFormats         []string        `yaml:"statement_formats" env:"STATEMENT_FORMATS"`
+	StatementOutputDir       string          `yaml:"statement_output_dir" env:"STATEMENT_OUTPUT_DIR"`
+	ApprovalThreshold        decimal.Decimal `yaml:"approval_threshold" env:"APPROVAL_THRESHOLD"`
+	ApprovalTimeout          time.Duration   `yaml:"approval_timeout" env:"APPROVAL_TIMEOUT"`
+}
+
+// Delivery of outbox events to webhook endpoints
+type WebhookConfig struct {
+	// Deadline of each delivery attempt
+	Timeout time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT"`
+	// Attempts before a delivery moves to the dead letters
+	MaxAttempts int `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	// Wait after the first failed attempt, doubled after each further one up to max_backoff
+	Backoff    time.Duration `yaml:"backoff" env:"WEBHOOK_BACKOFF"`
+	MaxBackoff time.Duration `yaml:"max_backoff" env:"WEBHOOK_MAX_BACKOFF"`
+	// Allows http endpoints and private, loopback or link-local addresses, for local development only
+	AllowPrivateEndpoints bool `yaml:"allow_private_endpoints" env:"WEBHOOK_ALLOW_PRIVATE_ENDPOINTS"`
+}
+
+// Account event store
+type EventStoreConfig struct {
+	// Events recorded since an account's latest snapshot before the snapshot job takes a new one
+	SnapshotEvery int `yaml:"snapshot_every" env:"EVENT_STORE_SNAPSHOT_EVERY"`
+}
+
+// Intervals of the background jobs
+type JobConfig struct {
+	InterestAccrualInterval     time.Duration `yaml:"interest_accrual_interval" env:"INTEREST_ACCRUAL_INTERVAL"`
+	InterestPostingInterval     time.Duration `yaml:"interest_posting_interval" env:"INTEREST_POSTING_INTERVAL"`
+	BalanceSnapshotInterval     time.Duration `yaml:"balance_snapshot_interval" env:"BALANCE_SNAPSHOT_INTERVAL"`
+	StatementGenerationInterval time.Duration `yaml:"statement_generation_interval" env:"STATEMENT_GENERATION_INTERVAL"`
+	ReconciliationInterval      time.Duration `yaml:"reconciliation_interval" env:"RECONCILIATION_INTERVAL"`
+	ApprovalExpiryInterval      time.Duration `yaml:"approval_expiry_interval" env:"APPROVAL_EXPIRY_INTERVAL"`
+	WebhookDispatchInterval     time.Duration `yaml:"webhook_dispatch_interval" env:"WEBHOOK_DISPATCH_INTERVAL"`
+	AggregateSnapshotInterval   time.Duration `yaml:"aggregate_snapshot_interval" env:"AGGREGATE_SNAPSHOT_INTERVAL"`
+	TransactionFeedInterval     time.Duration `yaml:"transaction_feed_interval" env:"TRANSACTION_FEED_INTERVAL"`
+}
+
+// Returns the configuration used when nothing overrides a setting
+func Default() *Config {
+	return &Config{
+		DB: DBConfig{
+			Host:              "localhost",
+			Port:              5432,
+			SSLMode:           "disable",
+			MaxOpenConns:      25,
+			MaxIdleConns:      10,
+			ConnMaxLifetime:   30 * time.Minute,
+			ConnMaxIdleTime:   5 * time.Minute,
+			ConnectAttempts:   10,
+			ConnectBackoff:    time.Second,
+			ConnectMaxBackoff: 30 * time.Second,
+		},
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+		},
+		GRPC: GRPCConfig{
+			Reflection: true,
+		},
+		TLS: TLSConfig{
+			MinVersion: "1.2",
+			ClientAuth: "require",
+		},
+		Auth: AuthConfig{
+			HMACMaxSkew: 5 * time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			IPPerMinute:      3000,
+			IPBurst:          500,
+			ClientPerMinute:  1200,
+			ClientBurst:      200,
+			AccountPerMinute: 120,
+			AccountBurst:     20,
+		},
+		Timeouts: TimeoutConfig{
+			Operation:   30 * time.Second,
+			HealthCheck: 2 * time.Second,
+		},
+		Shutdown: ShutdownConfig{
+			Timeout:    30 * time.Second,
+			DrainDelay: 5 * time.Second,
+		},
+		Retries: RetryConfig{
+			Attempts: 2,
+			Delay:    2 * time.Second,
+		},
+		Audit: AuditConfig{
+			LogFile: "audit.log",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "internal-transfers",
+			File:        "traces.json",
+		},
+		Features: FeatureConfig{
+			StatementFormats:  []string{"pdf"},
+			ApprovalThreshold: decimal.Zero,
+			ApprovalTimeout:   24 * time.Hour,
+		},
+		Webhooks: WebhookConfig{
+			Timeout:     10 * time.Second,
+			MaxAttempts: 10,
+			Backoff:     30 * time.Second,
+			MaxBackoff:  time.Hour,
+		},
+		EventStore: EventStoreConfig{
+			SnapshotEvery: 100,
+		},
+		Jobs: JobConfig{
+			InterestAccrualInterval:     time.Hour,
+			InterestPostingInterval:     24 * time.Hour,
+			BalanceSnapshotInterval:     time.Hour,
+			StatementGenerationInterval: 24 * time.Hour,
+			ReconciliationInterval:      24 * time.Hour,
+			ApprovalExpiryInterval:      5 * time.Minute,
+			WebhookDispatchInterval:     5 * time.Second,
+			AggregateSnapshotInterval:   time.Hour,
+			TransactionFeedInterval:     500 * time.Millisecond,
+		},
+	}
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// A leaf setting of Config
+type setting struct {
+	// YAML path, e.g. db.host
+	path  string
+	env   string
+	value reflect.Value
+}
+
+// Command-line flag of the setting, its YAML path with dashes (db.host is --db-host)
+func (s setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.path)
+}
+
+type flagValue struct {
+	setting setting
+	raw     string
+}
+
+// Loads the configuration from, in increasing precedence, the defaults, the YAML file named by --config or
+// CONFIG_FILE, the dotenv file named by --env-file or ENV_FILE, environment variables and command-line flags, then
+// validates it. The process environment is only read, dotenv variables are not exported to it.
+// Returns the arguments left after the flags.
+func Load(args []string) (*Config, []string, error) {
+	config := Default()
+	settings := collectSettings(reflect.ValueOf(config).Elem(), "")
+
+	flagSet := flag.NewFlagSet("internal-transfers", flag.ContinueOnError)
+	configFile := flagSet.String("config", os.Getenv("CONFIG_FILE"), "YAML configuration file")
+	envFile := flagSet.String("env-file", os.Getenv("ENV_FILE"), "dotenv file of environment variables, those already set take precedence")
+	var flagValues []flagValue
+	for _, s := range settings {
+		s := s
+		usage := fmt.Sprintf("sets %s", s.path)
+		if s.env != "" {
+			usage += fmt.Sprintf(" (env %s)", s.env)
+		}
+		record := func(raw string) error {
+			flagValues = append(flagValues, flagValue{setting: s, raw: raw})
+			return nil
+		}
+		if s.value.Kind() == reflect.Bool {
+			flagSet.BoolFunc(s.flagName(), usage, record)
+		} else {
+			flagSet.Func(s.flagName(), usage, record)
+		}
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(config, *configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	dotenv := map[string]string{}
+	if *envFile != "" {
+		var err error
+		if dotenv, err = godotenv.Read(*envFile); err != nil {
+			return nil, nil, fmt.Errorf("could not read env file %s: %w", *envFile, err)
+		}
+	}
+
+	for _, s := range settings {
+		if s.env == "" {
+			continue
+		}
+		raw, found := os.LookupEnv(s.env)
+		if !found {
+			raw, found = dotenv[s.env]
+		}
+		if found && raw != "" {
+			if err := setValue(s.value, raw); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %w", s.env, err)
+			}
+		}
+	}
+
+	for _, flagValue := range flagValues {
+		if err := setValue(flagValue.setting.value, flagValue.raw); err != nil {
+			return nil, nil, fmt.Errorf("invalid --%s: %w", flagValue.setting.flagName(), err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return config, flagSet.Args(), nil
+}
+
+// Reads the YAML file over the current settings, unknown keys are rejected so typos do not go unnoticed
+func loadFile(config *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Lists the leaf settings of a config struct with their YAML paths
+func collectSettings(value reflect.Value, prefix string) []setting {
+	var settings []setting
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		path := prefix + strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if field.Type.Kind() == reflect.Struct && field.Type != decimalType {
+			settings = append(settings, collectSettings(value.Field(i), path+".")...)
+			continue
+		}
+		settings = append(settings, setting{path: path, env: field.Tag.Get("env"), value: value.Field(i)})
+	}
+	return settings
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	decimalType  = reflect.TypeOf(decimal.Decimal{})
+)
+
+// Parses a raw environment or flag value into a setting. Lists are comma separated.
+func setValue(value reflect.Value, raw string) error {
+	switch {
+	case value.Type() == durationType:
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(parsed))
+	case value.Type() == decimalType:
+		parsed, err := decimal.NewFromString(raw)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(parsed))
+	case value.Kind() == reflect.String:
+		value.SetString(raw)
+	case value.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(parsed))
+	case value.Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"internal-transfers/model"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Checks the settings together, reporting every problem at once with the setting and its environment variable
+func (config *Config) Validate() error {
+	var problems []string
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	required := func(value string, name string) {
+		if strings.TrimSpace(value) == "" {
+			problem("%s is required", name)
+		}
+	}
+	positive := func(value time.Duration, name string) {
+		if value <= 0 {
+			problem("%s must be a positive duration", name)
+		}
+	}
+	notNegative := func(value int, name string) {
+		if value < 0 {
+			problem("%s must not be negative", name)
+		}
+	}
+
+	if config.DB.DSN == "" {
+		required(config.DB.Host, "db.host (DB_HOST)")
+		required(config.DB.User, "db.user (DB_USER)")
+		required(config.DB.Name, "db.name (DB_NAME)")
+		if config.DB.Port < 1 || config.DB.Port > 65535 {
+			problem("db.port (DB_PORT) must be between 1 and 65535")
+		}
+		if !slices.Contains([]string{"disable", "require", "verify-ca", "verify-full"}, config.DB.SSLMode) {
+			problem("db.sslmode (DB_SSLMODE) must be disable, require, verify-ca or verify-full")
+		}
+		if (config.DB.SSLCert == "") != (config.DB.SSLKey == "") {
+			problem("db.sslcert (DB_SSLCERT) and db.sslkey (DB_SSLKEY) must be set together")
+		}
+	}
+	if config.DB.MaxOpenConns < 1 {
+		problem("db.max_open_conns (DB_MAX_OPEN_CONNS) must be at least 1")
+	}
+	notNegative(config.DB.MaxIdleConns, "db.max_idle_conns (DB_MAX_IDLE_CONNS)")
+	if config.DB.MaxIdleConns > config.DB.MaxOpenConns {
+		problem("db.max_idle_conns (DB_MAX_IDLE_CONNS) must not exceed db.max_open_conns")
+	}
+	positive(config.DB.ConnMaxLifetime, "db.conn_max_lifetime (DB_CONN_MAX_LIFETIME)")
+	positive(config.DB.ConnMaxIdleTime, "db.conn_max_idle_time (DB_CONN_MAX_IDLE_TIME)")
+	if config.DB.ConnectAttempts < 1 {
+		problem("db.connect_attempts (DB_CONNECT_ATTEMPTS) must be at least 1")
+	}
+	positive(config.DB.ConnectBackoff, "db.connect_backoff (DB_CONNECT_BACKOFF)")
+	if config.DB.ConnectMaxBackoff < config.DB.ConnectBackoff {
+		problem("db.connect_max_backoff (DB_CONNECT_MAX_BACKOFF) must not be shorter than db.connect_backoff")
+	}
+
+	required(config.HTTP.Addr, "http.addr (LISTEN_ADDR)")
+	positive(config.HTTP.ReadHeaderTimeout, "http.read_header_timeout (HTTP_READ_HEADER_TIMEOUT)")
+	positive(config.HTTP.ReadTimeout, "http.read_timeout (HTTP_READ_TIMEOUT)")
+	positive(config.HTTP.WriteTimeout, "http.write_timeout (HTTP_WRITE_TIMEOUT)")
+	positive(config.HTTP.IdleTimeout, "http.idle_timeout (HTTP_IDLE_TIMEOUT)")
+
+	if config.GRPC.Addr != "" && config.GRPC.Addr == config.HTTP.Addr {
+		problem("grpc.addr (GRPC_ADDR) must differ from http.addr (LISTEN_ADDR)")
+	}
+
+	if (config.TLS.CertFile == "") != (config.TLS.KeyFile == "") {
+		problem("tls.cert_file (TLS_CERT_FILE) and tls.key_file (TLS_KEY_FILE) must be set together")
+	}
+	if config.TLS.ClientCAFile != "" && config.TLS.CertFile == "" {
+		problem("tls.client_ca_file (TLS_CLIENT_CA_FILE) requires tls.cert_file and tls.key_file")
+	}
+	if !slices.Contains([]string{"1.2", "1.3"}, config.TLS.MinVersion) {
+		problem("tls.min_version (TLS_MIN_VERSION) must be 1.2 or 1.3")
+	}
+	if !slices.Contains([]string{"require", "verify_if_given"}, config.TLS.ClientAuth) {
+		problem("tls.client_auth (TLS_CLIENT_AUTH) must be require or verify_if_given")
+	}
+
+	if config.Auth.HMACRequired && config.Auth.HMACClientsFile == "" {
+		problem("auth.hmac_required (HMAC_REQUIRED) requires auth.hmac_clients_file (HMAC_CLIENTS_FILE)")
+	}
+	positive(config.Auth.HMACMaxSkew, "auth.hmac_max_skew (HMAC_MAX_SKEW)")
+
+	notNegative(config.RateLimit.IPPerMinute, "rate_limit.ip_per_minute (RATE_LIMIT_IP_PER_MINUTE)")
+	notNegative(config.RateLimit.IPBurst, "rate_limit.ip_burst (RATE_LIMIT_IP_BURST)")
+	notNegative(config.RateLimit.ClientPerMinute, "rate_limit.client_per_minute (RATE_LIMIT_CLIENT_PER_MINUTE)")
+	notNegative(config.RateLimit.ClientBurst, "rate_limit.client_burst (RATE_LIMIT_CLIENT_BURST)")
+	notNegative(config.RateLimit.AccountPerMinute, "rate_limit.account_per_minute (RATE_LIMIT_ACCOUNT_PER_MINUTE)")
+	notNegative(config.RateLimit.AccountBurst, "rate_limit.account_burst (RATE_LIMIT_ACCOUNT_BURST)")
+
+	positive(config.Timeouts.Operation, "timeouts.operation (OPERATION_TIMEOUT)")
+	positive(config.Timeouts.HealthCheck, "timeouts.health_check (HEALTH_CHECK_TIMEOUT)")
+	positive(config.Shutdown.Timeout, "shutdown.timeout (SHUTDOWN_TIMEOUT)")
+	if config.Shutdown.DrainDelay < 0 {
+		problem("shutdown.drain_delay (SHUTDOWN_DRAIN_DELAY) must not be negative")
+	} else if config.Shutdown.DrainDelay >= config.Shutdown.Timeout {
+		problem("shutdown.drain_delay (SHUTDOWN_DRAIN_DELAY) must be shorter than shutdown.timeout")
+	}
+	if config.Retries.Attempts < 1 {
+		problem("retries.attempts (RETRY_ATTEMPTS) must be at least 1")
+	}
+	if config.Retries.Delay < 0 {
+		problem("retries.delay (RETRY_DELAY) must not be negative")
+	}
+
+	required(config.Audit.LogFile, "audit.log_file (AUDIT_LOG_FILE)")
+
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, strings.ToLower(config.Log.Level)) {
+		problem("log.level (LOG_LEVEL) must be debug, info, warn or error")
+	}
+	if !slices.Contains([]string{"json", "text"}, config.Log.Format) {
+		problem("log.format (LOG_FORMAT) must be json or text")
+	}
+
+	if !slices.Contains([]string{"none", "otlp", "stdout", "file"}, config.Tracing.Exporter) {
+		problem("tracing.exporter (TRACING_EXPORTER) must be none, otlp, stdout or file")
+	}
+	required(config.Tracing.ServiceName, "tracing.service_name (OTEL_SERVICE_NAME)")
+	if config.Tracing.Exporter == "file" {
+		required(config.Tracing.File, "tracing.file (TRACING_FILE)")
+	}
+
+	notNegative(config.Features.FeeRevenueAccountID, "features.fee_revenue_account_id (FEE_REVENUE_ACCOUNT_ID)")
+	notNegative(config.Features.InterestExpenseAccountID, "features.interest_expense_account_id (INTEREST_EXPENSE_ACCOUNT_ID)")
+	notNegative(config.Features.SuspenseAccountID, "features.suspense_account_id (SUSPENSE_ACCOUNT_ID)")
+	for _, format := range config.Features.StatementFormats {
+		if !slices.Contains([]string{model.StatementFormatCSV, model.StatementFormatJSON, model.StatementFormatPDF}, format) {
+			problem("features.statement_formats (STATEMENT_FORMATS) has unknown format %q, expected csv, json or pdf", format)
+		}
+	}
+	if config.Features.ApprovalThreshold.IsNegative() {
+		problem("features.approval_threshold (APPROVAL_THRESHOLD) must not be negative")
+	}
+	positive(config.Features.ApprovalTimeout, "features.approval_timeout (APPROVAL_TIMEOUT)")
+
+	positive(config.Webhooks.Timeout, "webhooks.timeout (WEBHOOK_TIMEOUT)")
+	if config.Webhooks.MaxAttempts < 1 {
+		problem("webhooks.max_attempts (WEBHOOK_MAX_ATTEMPTS) must be at least 1")
+	}
+	positive(config.Webhooks.Backoff, "webhooks.backoff (WEBHOOK_BACKOFF)")
+	if config.Webhooks.MaxBackoff < config.Webhooks.Backoff {
+		problem("webhooks.max_backoff (WEBHOOK_MAX_BACKOFF) must not be shorter than webhooks.backoff")
+	}
+
+	if config.EventStore.SnapshotEvery < 1 {
+		problem("event_store.snapshot_every (EVENT_STORE_SNAPSHOT_EVERY) must be at least 1")
+	}
+
+	positive(config.Jobs.InterestAccrualInterval, "jobs.interest_accrual_interval (INTEREST_ACCRUAL_INTERVAL)")
+	positive(config.Jobs.InterestPostingInterval, "jobs.interest_posting_interval (INTEREST_POSTING_INTERVAL)")
+	positive(config.Jobs.BalanceSnapshotInterval, "jobs.balance_snapshot_interval (BALANCE_SNAPSHOT_INTERVAL)")
+	positive(config.Jobs.StatementGenerationInterval, "jobs.statement_generation_interval (STATEMENT_GENERATION_INTERVAL)")
+	positive(config.Jobs.ReconciliationInterval, "jobs.reconciliation_interval (RECONCILIATION_INTERVAL)")
+	positive(config.Jobs.ApprovalExpiryInterval, "jobs.approval_expiry_interval (APPROVAL_EXPIRY_INTERVAL)")
+	positive(config.Jobs.WebhookDispatchInterval, "jobs.webhook_dispatch_interval (WEBHOOK_DISPATCH_INTERVAL)")
+	positive(config.Jobs.AggregateSnapshotInterval, "jobs.aggregate_snapshot_interval (AGGREGATE_SNAPSHOT_INTERVAL)")
+	positive(config.Jobs.TransactionFeedInterval, "jobs.transaction_feed_interval (TRANSACTION_FEED_INTERVAL)")
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
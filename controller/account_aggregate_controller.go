@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handles the HTTP requests for the event-sourced history of accounts
+type AccountAggregateController struct {
+	Service *service.AccountAggregateService
+}
+
+func NewAccountAggregateController(aggregateService *service.AccountAggregateService) *AccountAggregateController {
+	return &AccountAggregateController{
+		Service: aggregateService,
+	}
+}
+
+// Handles the GET /api/v1/accounts/{account_id}/history?after_version=<version>&limit=<n> request, listing the
+// account's stored events in version order
+func (aggregateController *AccountAggregateController) ListHistoryHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return
+	}
+	var afterVersion int64
+	if value := request.URL.Query().Get("after_version"); value != "" {
+		if afterVersion, err = strconv.ParseInt(value, 10, 64); err != nil {
+			http.Error(writer, "Invalid after_version, expected a number", http.StatusBadRequest)
+			return
+		}
+	}
+	var limit int
+	if value := request.URL.Query().Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			http.Error(writer, "Invalid limit, expected a number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := aggregateController.Service.ListAccountEvents(accountID, afterVersion, limit)
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error fetching account history", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, events)
+}
+
+// Handles the GET /api/v1/accounts/{account_id}/state?version=<version>|as_of=<RFC3339 timestamp> request, replaying
+// the account up to that point, or up to its latest event when neither is given
+func (aggregateController *AccountAggregateController) GetStateHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return
+	}
+	var version int64
+	if value := request.URL.Query().Get("version"); value != "" {
+		if version, err = strconv.ParseInt(value, 10, 64); err != nil {
+			http.Error(writer, "Invalid version, expected a number", http.StatusBadRequest)
+			return
+		}
+	}
+	var asOf *time.Time
+	if value := request.URL.Query().Get("as_of"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(writer, "Invalid as_of timestamp, expected RFC 3339 (e.g. 2025-03-12T15:04:05Z)", http.StatusBadRequest)
+			return
+		}
+		asOf = &parsed
+	}
+
+	aggregate, err := aggregateController.Service.LoadAccount(accountID, version, asOf)
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error replaying account", err)
+		return
+	}
+	if aggregate == nil {
+		http.Error(writer, fmt.Sprintf("Account with ID %d not found", accountID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, aggregate)
+}
@@ -2,10 +2,11 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"internal-transfers/auth"
 	"internal-transfers/common"
 	"internal-transfers/model"
-	"internal-transfers/service"
 	"net/http"
 	"strconv"
 
@@ -13,13 +14,21 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// Account operations used by the handlers, implemented by service.AccountService
+type AccountOperations interface {
+	GetAccountByID(accountID int) (*model.Account, error)
+	CreateAccount(account model.Account) error
+}
+
 // Handles the HTTP requests for account related operations
 type AccountController struct {
-	Service     *service.AccountService
-	AuditLogger *common.AuditLogger
+	Service     AccountOperations
+	AuditLogger common.Auditor
+	// Limits reads to accounts the caller is linked to, every account is allowed when nil
+	Authorizer *auth.Authorizer
 }
 
-func NewAccountController(accountService *service.AccountService, auditLogger *common.AuditLogger) *AccountController {
+func NewAccountController(accountService AccountOperations, auditLogger common.Auditor) *AccountController {
 	return &AccountController{
 		Service:     accountService,
 		AuditLogger: auditLogger,
@@ -36,9 +45,13 @@ func (ac *AccountController) GetAccountHandler(writer http.ResponseWriter, reque
 		return
 	}
 
+	if !ac.Authorizer.CheckAccount(writer, request, id) {
+		return
+	}
+
 	account, err := ac.Service.GetAccountByID(id)
 	if err != nil {
-		http.Error(writer, fmt.Sprintf("Error fetching account: %v", err), http.StatusInternalServerError)
+		writeServerError(writer, request, "Error fetching account", err)
 		return
 	}
 
@@ -50,7 +63,7 @@ func (ac *AccountController) GetAccountHandler(writer http.ResponseWriter, reque
 	writer.Header().Set("Content-Type", "application/json")
 	writer.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(writer).Encode(account); err != nil {
-		http.Error(writer, fmt.Sprintf("Error encoding account data: %v", err), http.StatusInternalServerError)
+		writeServerError(writer, request, "Error encoding account data", err)
 	}
 }
 
@@ -68,7 +81,7 @@ func (accountController *AccountController) CreateAccountHandler(writer http.Res
 
 	existingAccount, err := accountController.Service.GetAccountByID(input.AccountID)
 	if err != nil {
-		http.Error(writer, fmt.Sprintf("Error checking account: %v", err), http.StatusInternalServerError)
+		writeServerError(writer, r, "Error checking account", err)
 		return
 	}
 	if existingAccount != nil {
@@ -83,47 +96,22 @@ func (accountController *AccountController) CreateAccountHandler(writer http.Res
 	}
 
 	newAccount := model.NewAccount(input.AccountID, initialBalance)
+	if input.AccountType != "" {
+		newAccount.AccountType = input.AccountType
+	}
 
 	if err := accountController.Service.CreateAccount(*newAccount); err != nil {
-		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, r, "Error creating account", err)
 		return
 	}
 
-	accountController.AuditLogger.LogAction("CreateAccount", fmt.Sprintf("Account created with ID: %d", input.AccountID))
+	accountController.AuditLogger.LogActionWithContext(r.Context(), "CreateAccount", fmt.Sprintf("Account created with ID: %d", input.AccountID))
 
 	writer.WriteHeader(http.StatusCreated)
 	writer.Write([]byte("Account created successfully"))
 }
-
-// Handles the PUT /v1/accounts/{account_id} request
-func (accountController *AccountController) UpdateAccountHandler(writer http.ResponseWriter, request *http.Request) {
-	var input model.UpdateAccountInput
-	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
-		http.Error(writer, "Invalid input", http.StatusBadRequest)
-		return
-	}
-
-	if input.AccountID == 0 {
-		http.Error(writer, "Please provide a valid account_id", http.StatusBadRequest)
-		return
-	}
-
-	existingAccount, err := accountController.Service.GetAccountByID(input.AccountID)
-	if err != nil {
-		http.Error(writer, fmt.Sprintf("Error checking account: %v", err), http.StatusInternalServerError)
-		return
-	}
-	if existingAccount == nil {
-		http.Error(writer, "Account not found", http.StatusNotFound)
-		return
-	}
-
-	if err := accountController.Service.UpdateAccountBalance(input.AccountID, input.Balance); err != nil {
-		http.Error(writer, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	accountController.AuditLogger.LogAction("UpdateAccount", fmt.Sprintf("Account updated with ID: %d", input.AccountID))
-
-	writer.WriteHeader(http.StatusOK)
-}
@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// Interval of keep-alive comments (Server-Sent Events) and pings (WebSocket) on event streams
+const eventStreamKeepAlive = 15 * time.Second
+
+// Deadline of each WebSocket write, a client not reading for longer is disconnected
+const webSocketWriteTimeout = 10 * time.Second
+
+// Reconnection delay suggested to EventSource clients, in milliseconds
+const eventStreamRetryMillis = 2000
+
+var webSocketUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// Handles the HTTP requests streaming the transaction and balance events of an account
+type AccountEventController struct {
+	Service *service.AccountEventService
+	// Looks the account up before streaming, so unknown accounts get 404
+	Accounts AccountOperations
+}
+
+func NewAccountEventController(accountEventService *service.AccountEventService, accounts AccountOperations) *AccountEventController {
+	return &AccountEventController{
+		Service:  accountEventService,
+		Accounts: accounts,
+	}
+}
+
+// Handles the GET /api/v1/accounts/{account_id}/events request, streaming the account's events as Server-Sent Events.
+// Resumes after the Last-Event-ID header (sent by EventSource on reconnect) or the last_event_id query parameter.
+func (accountEventController *AccountEventController) StreamEventsHandler(writer http.ResponseWriter, request *http.Request) {
+	lastEventID := request.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = request.URL.Query().Get("last_event_id")
+	}
+	accountID, afterID, ok := accountEventController.streamRequest(writer, request, lastEventID)
+	if !ok {
+		return
+	}
+
+	// Streams outlive the server's write timeout
+	responseController := http.NewResponseController(writer)
+	if err := responseController.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		writeServerError(writer, request, "Error starting event stream", err)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+	fmt.Fprintf(writer, "retry: %d\n\n", eventStreamRetryMillis)
+	responseController.Flush()
+
+	ctx, cancel := context.WithCancel(request.Context())
+	defer cancel()
+	events, done := accountEventController.startStream(ctx, accountID, afterID)
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+		select {
+		case event := <-events:
+			err = writeServerSentEvent(writer, event)
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(writer, ": keep-alive\n\n")
+		case err := <-done:
+			if message, reconnect := streamEndMessage(ctx, request, err); reconnect {
+				data, _ := json.Marshal(map[string]string{"error": message})
+				fmt.Fprintf(writer, "event: error\ndata: %s\n\n", data)
+				responseController.Flush()
+			}
+			return
+		}
+		if err == nil {
+			err = responseController.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Handles the GET /api/v1/accounts/{account_id}/events/ws request, upgrading it to a WebSocket that carries one JSON
+// model.AccountEvent per text message. Resumes after the last_event_id query parameter. Messages from the client are
+// ignored; the server closes with 1013 (try again later) when the client falls behind or the server shuts down.
+func (accountEventController *AccountEventController) WebSocketHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, afterID, ok := accountEventController.streamRequest(writer, request, request.URL.Query().Get("last_event_id"))
+	if !ok {
+		return
+	}
+
+	connection, err := webSocketUpgrader.Upgrade(writer, request, nil)
+	if err != nil {
+		return // the upgrader has answered with an HTTP error
+	}
+	defer connection.Close()
+
+	ctx, cancel := context.WithCancel(request.Context())
+	defer cancel()
+	// Reads until the client goes away, answering its pings and noting its pongs
+	go func() {
+		defer cancel()
+		connection.SetReadLimit(4096)
+		connection.SetReadDeadline(time.Now().Add(2 * eventStreamKeepAlive))
+		connection.SetPongHandler(func(string) error {
+			return connection.SetReadDeadline(time.Now().Add(2 * eventStreamKeepAlive))
+		})
+		for {
+			if _, _, err := connection.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	events, done := accountEventController.startStream(ctx, accountID, afterID)
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+		select {
+		case event := <-events:
+			connection.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+			err = connection.WriteJSON(event)
+		case <-keepAlive.C:
+			err = connection.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteTimeout))
+		case err := <-done:
+			code := websocket.CloseInternalServerErr
+			message, reconnect := streamEndMessage(ctx, request, err)
+			if reconnect {
+				code = websocket.CloseTryAgainLater
+			}
+			if ctx.Err() == nil {
+				connection.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, message), time.Now().Add(webSocketWriteTimeout))
+			}
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Parses the account ID and the event ID to resume after, and checks that the account exists. On failure it writes
+// the error response and returns false.
+func (accountEventController *AccountEventController) streamRequest(writer http.ResponseWriter, request *http.Request, lastEventID string) (int, int, bool) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	afterID := 0
+	if lastEventID != "" {
+		afterID, err = strconv.Atoi(lastEventID)
+		if err != nil || afterID < 0 {
+			http.Error(writer, "Invalid last event ID, expected a transaction ID", http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+
+	account, err := accountEventController.Accounts.GetAccountByID(accountID)
+	if err != nil {
+		writeServerError(writer, request, "Error fetching account", err)
+		return 0, 0, false
+	}
+	if account == nil {
+		http.Error(writer, fmt.Sprintf("Account with ID %d not found", accountID), http.StatusNotFound)
+		return 0, 0, false
+	}
+	return accountID, afterID, true
+}
+
+// Runs the account's event stream until ctx ends, handing each event over on the first channel. The second
+// channel receives the stream's result when it ends.
+func (accountEventController *AccountEventController) startStream(ctx context.Context, accountID int, afterID int) (<-chan model.AccountEvent, <-chan error) {
+	events := make(chan model.AccountEvent)
+	done := make(chan error, 1)
+	go func() {
+		done <- accountEventController.Service.StreamAccountEvents(ctx, accountID, afterID, func(event model.AccountEvent) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return events, done
+}
+
+// Describes why a stream ended and whether the client should reconnect. Errors other than the client leaving, falling
+// behind or the server shutting down are logged.
+func streamEndMessage(ctx context.Context, request *http.Request, err error) (string, bool) {
+	switch {
+	case ctx.Err() != nil:
+		return "", false
+	case errors.Is(err, service.ErrFeedLagged), errors.Is(err, service.ErrFeedClosed):
+		return err.Error(), true
+	}
+	common.LogErrorWithContext(request.Context(), "Error streaming account events", "error", err, "path", request.URL.Path)
+	return "Error streaming account events", false
+}
+
+// Writes an event in the text/event-stream format, with the transaction ID as the event ID of transaction events
+func writeServerSentEvent(writer http.ResponseWriter, event model.AccountEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if event.EventID != 0 {
+		if _, err := fmt.Fprintf(writer, "id: %d\n", event.EventID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
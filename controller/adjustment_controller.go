@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Handles the HTTP requests for balance adjustments
+type AdjustmentController struct {
+	Service *service.AdjustmentService
+	// Holds adjustments for a second operator's approval, adjustments post immediately when nil
+	Approvals *service.ApprovalService
+}
+
+func NewAdjustmentController(adjustmentService *service.AdjustmentService, approvalService *service.ApprovalService) *AdjustmentController {
+	return &AdjustmentController{
+		Service:   adjustmentService,
+		Approvals: approvalService,
+	}
+}
+
+// Handles the POST /api/v1/accounts/{account_id}/adjustments request.
+// The operator is the authenticated principal, or X-Operator-ID and then operator_id in the body when
+// authentication is disabled.
+// When approvals are enabled the adjustment is held and 202 Accepted returns the pending operation.
+func (adjustmentController *AdjustmentController) CreateAdjustmentHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return
+	}
+
+	var input model.CreateAdjustmentInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		http.Error(writer, fmt.Sprintf("Invalid input: %v", err), http.StatusBadRequest)
+		return
+	}
+	if operatorID := requestOperatorID(request); operatorID != "" {
+		input.OperatorID = operatorID
+	}
+
+	if adjustmentController.Approvals != nil {
+		operation, err := adjustmentController.Approvals.SubmitAdjustment(request.Context(), accountID, input)
+		if err != nil {
+			writeOperationError(writer, request, err, "Error submitting adjustment")
+			return
+		}
+		writeJSON(writer, request, http.StatusAccepted, operation)
+		return
+	}
+
+	adjustment, err := adjustmentController.Service.CreateAdjustment(request.Context(), accountID, input)
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error posting adjustment", err)
+		return
+	}
+	if adjustment == nil {
+		http.Error(writer, fmt.Sprintf("Account with ID %d not found", accountID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusCreated, adjustment)
+}
+
+// Lists the adjustments made to an account
+func (adjustmentController *AdjustmentController) ListAdjustmentsHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return
+	}
+
+	adjustments, err := adjustmentController.Service.ListAdjustments(accountID)
+	if err != nil {
+		writeServerError(writer, request, "Error fetching adjustments", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, adjustments)
+}
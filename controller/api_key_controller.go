@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Handles the admin HTTP requests for API keys
+type APIKeyController struct {
+	Service *service.APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService *service.APIKeyService) *APIKeyController {
+	return &APIKeyController{
+		Service: apiKeyService,
+	}
+}
+
+// Issues a new API key, the response is the only time the key is returned
+func (apiKeyController *APIKeyController) CreateAPIKeyHandler(writer http.ResponseWriter, request *http.Request) {
+	var input model.CreateAPIKeyInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		http.Error(writer, fmt.Sprintf("Invalid input: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	created, err := apiKeyController.Service.CreateAPIKey(request.Context(), input, requestOperatorID(request))
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error creating API key", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusCreated, created)
+}
+
+// Lists API keys without their secrets
+func (apiKeyController *APIKeyController) ListAPIKeysHandler(writer http.ResponseWriter, request *http.Request) {
+	apiKeys, err := apiKeyController.Service.ListAPIKeys()
+	if err != nil {
+		writeServerError(writer, request, "Error fetching API keys", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, apiKeys)
+}
+
+// Revokes an API key, requests made with it are rejected from then on
+func (apiKeyController *APIKeyController) RevokeAPIKeyHandler(writer http.ResponseWriter, request *http.Request) {
+	keyID, err := strconv.Atoi(mux.Vars(request)["key_id"])
+	if err != nil {
+		http.Error(writer, "Invalid key ID format", http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := apiKeyController.Service.RevokeAPIKey(request.Context(), keyID, requestOperatorID(request))
+	if err != nil {
+		writeServerError(writer, request, "Error revoking API key", err)
+		return
+	}
+	if !revoked {
+		http.Error(writer, fmt.Sprintf("Active API key %d not found", keyID), http.StatusNotFound)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Handles the HTTP requests for approving or rejecting held operations
+type ApprovalController struct {
+	Service *service.ApprovalService
+}
+
+func NewApprovalController(approvalService *service.ApprovalService) *ApprovalController {
+	return &ApprovalController{
+		Service: approvalService,
+	}
+}
+
+// Handles the GET /api/v1/approvals?status= request
+func (approvalController *ApprovalController) ListOperationsHandler(writer http.ResponseWriter, request *http.Request) {
+	operations, err := approvalController.Service.ListOperations(request.URL.Query().Get("status"))
+	if err != nil {
+		writeServerError(writer, request, "Error fetching operations", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, operations)
+}
+
+// Retrieves a held operation by its ID
+func (approvalController *ApprovalController) GetOperationHandler(writer http.ResponseWriter, request *http.Request) {
+	operationID, err := strconv.Atoi(mux.Vars(request)["operation_id"])
+	if err != nil {
+		http.Error(writer, "Invalid operation ID format", http.StatusBadRequest)
+		return
+	}
+
+	operation, err := approvalController.Service.GetOperation(operationID)
+	if err != nil {
+		writeServerError(writer, request, "Error fetching operation", err)
+		return
+	}
+	if operation == nil {
+		http.Error(writer, fmt.Sprintf("Operation %d not found", operationID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, operation)
+}
+
+// Approves a held operation on behalf of the requesting operator and executes it
+func (approvalController *ApprovalController) ApproveOperationHandler(writer http.ResponseWriter, request *http.Request) {
+	operationID, err := strconv.Atoi(mux.Vars(request)["operation_id"])
+	if err != nil {
+		http.Error(writer, "Invalid operation ID format", http.StatusBadRequest)
+		return
+	}
+
+	operation, err := approvalController.Service.Approve(request.Context(), operationID, requestOperatorID(request))
+	if err != nil {
+		writeOperationError(writer, request, err, "Error approving operation")
+		return
+	}
+	if operation == nil {
+		http.Error(writer, fmt.Sprintf("Operation %d not found", operationID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, operation)
+}
+
+// Rejects a held operation on behalf of the requesting operator
+func (approvalController *ApprovalController) RejectOperationHandler(writer http.ResponseWriter, request *http.Request) {
+	operationID, err := strconv.Atoi(mux.Vars(request)["operation_id"])
+	if err != nil {
+		http.Error(writer, "Invalid operation ID format", http.StatusBadRequest)
+		return
+	}
+
+	var input model.RejectOperationInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		http.Error(writer, fmt.Sprintf("Invalid input: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	operation, err := approvalController.Service.Reject(request.Context(), operationID, requestOperatorID(request), input.Reason)
+	if err != nil {
+		writeOperationError(writer, request, err, "Error rejecting operation")
+		return
+	}
+	if operation == nil {
+		http.Error(writer, fmt.Sprintf("Operation %d not found", operationID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, operation)
+}
+
+// Writes a validation failure as 422 Unprocessable Entity and anything else as 500
+func writeOperationError(writer http.ResponseWriter, request *http.Request, err error, context string) {
+	var validationError *common.ValidationError
+	if errors.As(err, &validationError) {
+		http.Error(writer, validationError.Message, http.StatusUnprocessableEntity)
+		return
+	}
+	writeServerError(writer, request, context, err)
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handles the HTTP requests for historical account balances
+type BalanceController struct {
+	Service *service.BalanceService
+}
+
+func NewBalanceController(balanceService *service.BalanceService) *BalanceController {
+	return &BalanceController{
+		Service: balanceService,
+	}
+}
+
+// Handles the GET /api/v1/accounts/{account_id}/balance?as_of=<RFC3339 timestamp> request, as_of defaults to now
+func (balanceController *BalanceController) GetBalanceHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return
+	}
+
+	asOf := time.Now()
+	if value := request.URL.Query().Get("as_of"); value != "" {
+		if asOf, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(writer, "Invalid as_of timestamp, expected RFC 3339 (e.g. 2025-03-12T15:04:05Z)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	balance, err := balanceController.Service.GetBalanceAsOf(accountID, asOf)
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error fetching balance", err)
+		return
+	}
+	if balance == nil {
+		http.Error(writer, fmt.Sprintf("Account with ID %d not found", accountID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, balance)
+}
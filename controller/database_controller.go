@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"database/sql"
+	"internal-transfers/model"
+	"net/http"
+)
+
+// Handles the admin HTTP requests about the database connection pool
+type DatabaseController struct {
+	// Returns the current pool statistics, sqlx.DB.Stats in production
+	Stats func() sql.DBStats
+}
+
+func NewDatabaseController(stats func() sql.DBStats) *DatabaseController {
+	return &DatabaseController{
+		Stats: stats,
+	}
+}
+
+// Reports connection pool usage, for monitoring pool exhaustion and connection churn
+func (databaseController *DatabaseController) GetPoolStatsHandler(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, request, http.StatusOK, model.NewDBPoolStats(databaseController.Stats()))
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Handles the admin HTTP requests for fee schedules
+type FeeController struct {
+	Service *service.FeeService
+}
+
+func NewFeeController(feeService *service.FeeService) *FeeController {
+	return &FeeController{
+		Service: feeService,
+	}
+}
+
+// Lists all fee schedules
+func (feeController *FeeController) ListFeeSchedulesHandler(writer http.ResponseWriter, request *http.Request) {
+	schedules, err := feeController.Service.ListFeeSchedules()
+	if err != nil {
+		writeServerError(writer, request, "Error fetching fee schedules", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, schedules)
+}
+
+// Retrieves a fee schedule by its ID
+func (feeController *FeeController) GetFeeScheduleHandler(writer http.ResponseWriter, request *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(request)["fee_schedule_id"])
+	if err != nil {
+		http.Error(writer, "Invalid fee schedule ID format", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := feeController.Service.GetFeeScheduleByID(id)
+	if err != nil {
+		writeServerError(writer, request, "Error fetching fee schedule", err)
+		return
+	}
+	if schedule == nil {
+		http.Error(writer, fmt.Sprintf("Fee schedule with ID %d not found", id), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, schedule)
+}
+
+// Creates a new fee schedule, effective from the given date or immediately
+func (feeController *FeeController) CreateFeeScheduleHandler(writer http.ResponseWriter, request *http.Request) {
+	var input model.CreateFeeScheduleInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		http.Error(writer, fmt.Sprintf("Invalid input: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := feeController.Service.CreateFeeSchedule(request.Context(), input)
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error creating fee schedule", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusCreated, schedule)
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+)
+
+// Handles the liveness and readiness probes
+type HealthController struct {
+	Service *service.HealthService
+}
+
+func NewHealthController(healthService *service.HealthService) *HealthController {
+	return &HealthController{
+		Service: healthService,
+	}
+}
+
+// Answers 200 while the process is able to serve HTTP at all
+func (healthController *HealthController) LivenessHandler(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, request, http.StatusOK, model.HealthReport{Status: model.HealthStatusOK})
+}
+
+// Answers 200 when every dependency is reachable and the server is not shutting down, 503 otherwise,
+// with the result of each check
+func (healthController *HealthController) ReadinessHandler(writer http.ResponseWriter, request *http.Request) {
+	report := healthController.Service.Readiness(request.Context())
+	status := http.StatusOK
+	if report.Status != model.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(writer, request, status, report)
+}
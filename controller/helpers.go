@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"encoding/json"
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"net/http"
+)
+
+// Returns the operator a request was made by: the authenticated principal, or the X-Operator-ID header
+// when authentication is disabled. Empty when neither identifies one.
+func requestOperatorID(request *http.Request) string {
+	if principal := auth.PrincipalFromContext(request.Context()); principal != nil {
+		return principal.ID
+	}
+	return request.Header.Get(auth.OperatorIDHeader)
+}
+
+// Logs err with the request ID and answers 500 Internal Server Error with the message only, the error may hold
+// database or driver details
+func writeServerError(writer http.ResponseWriter, request *http.Request, message string, err error) {
+	common.LogErrorWithContext(request.Context(), message, "error", err, "method", request.Method, "path", request.URL.Path)
+	http.Error(writer, message, http.StatusInternalServerError)
+}
+
+// Writes body as a JSON response with the given status
+func writeJSON(writer http.ResponseWriter, request *http.Request, status int, body interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(body); err != nil {
+		common.LogErrorWithContext(request.Context(), "Error encoding response", "error", err, "method", request.Method, "path", request.URL.Path)
+	}
+}
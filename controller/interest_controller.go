@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handles the admin HTTP requests for account interest
+type InterestController struct {
+	Service *service.InterestService
+}
+
+func NewInterestController(interestService *service.InterestService) *InterestController {
+	return &InterestController{
+		Service: interestService,
+	}
+}
+
+// Retrieves the interest configuration of an account
+func (interestController *InterestController) GetInterestConfigHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return
+	}
+
+	config, err := interestController.Service.GetInterestConfig(accountID)
+	if err != nil {
+		writeServerError(writer, request, "Error fetching interest config", err)
+		return
+	}
+	if config == nil {
+		http.Error(writer, fmt.Sprintf("No interest configured for account %d", accountID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, config)
+}
+
+// Handles the PUT /api/v1/admin/accounts/{account_id}/interest request
+func (interestController *InterestController) ConfigureInterestHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return
+	}
+
+	var input model.InterestConfigInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		http.Error(writer, fmt.Sprintf("Invalid input: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	config, err := interestController.Service.ConfigureInterest(request.Context(), accountID, input)
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error configuring interest", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, config)
+}
+
+// Lists the daily accruals of an account, defaults to the last 30 days
+func (interestController *InterestController) ListAccrualsHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return
+	}
+
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -30)
+	if value := request.URL.Query().Get("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(writer, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := request.URL.Query().Get("to"); value != "" {
+		if to, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(writer, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	accruals, err := interestController.Service.ListAccruals(accountID, from, to)
+	if err != nil {
+		writeServerError(writer, request, "Error fetching interest accruals", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, accruals)
+}
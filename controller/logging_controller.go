@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"log/slog"
+	"net/http"
+)
+
+// Handles the admin HTTP requests about the application log
+type LoggingController struct {
+	// Level of the application logger, common.LogLevel in production
+	Level       *slog.LevelVar
+	AuditLogger common.Auditor
+}
+
+func NewLoggingController(level *slog.LevelVar, auditLogger common.Auditor) *LoggingController {
+	return &LoggingController{
+		Level:       level,
+		AuditLogger: auditLogger,
+	}
+}
+
+// Returns the current log level
+func (loggingController *LoggingController) GetLogLevelHandler(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, request, http.StatusOK, model.LogLevel{Level: common.FormatLogLevel(loggingController.Level.Level())})
+}
+
+// Changes the log level until the next change or restart
+func (loggingController *LoggingController) SetLogLevelHandler(writer http.ResponseWriter, request *http.Request) {
+	var input model.LogLevel
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		http.Error(writer, fmt.Sprintf("Error decoding request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	level, err := common.ParseLogLevel(input.Level)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	previous := loggingController.Level.Level()
+	loggingController.Level.Set(level)
+	if loggingController.AuditLogger != nil {
+		loggingController.AuditLogger.LogActionWithContext(request.Context(), "Log Level Changed", fmt.Sprintf("From: %s, To: %s, Changed By: %s",
+			common.FormatLogLevel(previous), common.FormatLogLevel(level), requestOperatorID(request)))
+	}
+	writeJSON(writer, request, http.StatusOK, model.LogLevel{Level: common.FormatLogLevel(level)})
+}
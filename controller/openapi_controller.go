@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"net/http"
+)
+
+// Serves the OpenAPI document describing the v1 API
+type OpenAPIController struct {
+	Document []byte
+}
+
+func NewOpenAPIController(document []byte) *OpenAPIController {
+	return &OpenAPIController{
+		Document: document,
+	}
+}
+
+// Returns the OpenAPI document as stored, for API clients and tooling
+func (openAPIController *OpenAPIController) GetDocumentHandler(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(openAPIController.Document)
+}
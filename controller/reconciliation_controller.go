@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handles the admin HTTP requests for balance reconciliation
+type ReconciliationController struct {
+	Service *service.ReconciliationService
+}
+
+func NewReconciliationController(reconciliationService *service.ReconciliationService) *ReconciliationController {
+	return &ReconciliationController{
+		Service: reconciliationService,
+	}
+}
+
+// Runs a reconciliation immediately and returns its report
+func (reconciliationController *ReconciliationController) RunReconciliationHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx, cancel := context.WithTimeout(request.Context(), 5*time.Minute)
+	defer cancel()
+
+	run, err := reconciliationController.Service.Reconcile(ctx)
+	if err != nil {
+		writeServerError(writer, request, "Error running reconciliation", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusCreated, run)
+}
+
+// Lists the most recent reconciliation runs
+func (reconciliationController *ReconciliationController) ListRunsHandler(writer http.ResponseWriter, request *http.Request) {
+	runs, err := reconciliationController.Service.ListRuns()
+	if err != nil {
+		writeServerError(writer, request, "Error fetching reconciliation runs", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, runs)
+}
+
+// Retrieves a reconciliation run with its mismatches
+func (reconciliationController *ReconciliationController) GetRunHandler(writer http.ResponseWriter, request *http.Request) {
+	runID, err := strconv.Atoi(mux.Vars(request)["run_id"])
+	if err != nil {
+		http.Error(writer, "Invalid run ID format", http.StatusBadRequest)
+		return
+	}
+
+	run, err := reconciliationController.Service.GetRun(runID)
+	if err != nil {
+		writeServerError(writer, request, "Error fetching reconciliation run", err)
+		return
+	}
+	if run == nil {
+		http.Error(writer, fmt.Sprintf("Reconciliation run %d not found", runID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, run)
+}
+
+// Approves a mismatch and posts its correcting entry
+func (reconciliationController *ReconciliationController) ApproveCorrectionHandler(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	runID, err := strconv.Atoi(vars["run_id"])
+	if err != nil {
+		http.Error(writer, "Invalid run ID format", http.StatusBadRequest)
+		return
+	}
+	mismatchID, err := strconv.Atoi(vars["mismatch_id"])
+	if err != nil {
+		http.Error(writer, "Invalid mismatch ID format", http.StatusBadRequest)
+		return
+	}
+
+	var input model.ApproveCorrectionInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		http.Error(writer, fmt.Sprintf("Invalid input: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	mismatch, err := reconciliationController.Service.ApproveCorrection(request.Context(), runID, mismatchID, input.ApprovedBy)
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusConflict)
+			return
+		}
+		writeServerError(writer, request, "Error posting correction", err)
+		return
+	}
+	if mismatch == nil {
+		http.Error(writer, fmt.Sprintf("Mismatch %d not found in run %d", mismatchID, runID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, mismatch)
+}
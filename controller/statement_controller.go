@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var statementContentTypes = map[string]string{
+	model.StatementFormatCSV:  "text/csv",
+	model.StatementFormatJSON: "application/json",
+	model.StatementFormatPDF:  "application/pdf",
+}
+
+// Handles the HTTP requests for account statements
+type StatementController struct {
+	Service *service.StatementService
+}
+
+func NewStatementController(statementService *service.StatementService) *StatementController {
+	return &StatementController{
+		Service: statementService,
+	}
+}
+
+// Handles the GET /api/v1/accounts/{account_id}/statements?from=&to=&format= request.
+// from and to accept RFC 3339 timestamps or YYYY-MM-DD dates (a date for to covers the whole day);
+// the period defaults to the current month and the format to json.
+func (statementController *StatementController) GetStatementHandler(writer http.ResponseWriter, request *http.Request) {
+	accountID, err := strconv.Atoi(mux.Vars(request)["account_id"])
+	if err != nil {
+		http.Error(writer, "Invalid account ID format", http.StatusBadRequest)
+		return
+	}
+
+	query := request.URL.Query()
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := now
+
+	if value := query.Get("from"); value != "" {
+		if from, err = parseStatementTime(value, false); err != nil {
+			http.Error(writer, "Invalid from, expected RFC 3339 timestamp or YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if to, err = parseStatementTime(value, true); err != nil {
+			http.Error(writer, "Invalid to, expected RFC 3339 timestamp or YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = model.StatementFormatJSON
+	}
+	if err := service.ValidateStatementFormat(format); err != nil {
+		http.Error(writer, err.(*common.ValidationError).Message, http.StatusBadRequest)
+		return
+	}
+
+	statement, err := statementController.Service.GenerateStatement(accountID, from, to)
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error generating statement", err)
+		return
+	}
+	if statement == nil {
+		http.Error(writer, fmt.Sprintf("Account with ID %d not found", accountID), http.StatusNotFound)
+		return
+	}
+
+	// Render fully before writing headers so a rendering failure can still return a 500
+	var body bytes.Buffer
+	if err := service.RenderStatement(&body, statement, format); err != nil {
+		writeServerError(writer, request, "Error rendering statement", err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", statementContentTypes[format])
+	if format != model.StatementFormatJSON {
+		writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"statement-%d-%s-%s.%s\"",
+			accountID, from.Format("20060102"), to.Format("20060102"), format))
+	}
+	writer.WriteHeader(http.StatusOK)
+	body.WriteTo(writer)
+}
+
+func parseStatementTime(value string, endOfDay bool) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		return parsed.AddDate(0, 0, 1).Add(-time.Microsecond), nil
+	}
+	return parsed, nil
+}
@@ -2,23 +2,60 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"internal-transfers/auth"
+	"internal-transfers/common"
 	"internal-transfers/model"
+	"internal-transfers/ratelimit"
 	"internal-transfers/service"
+	"internal-transfers/tracing"
 	"net/http"
+	"strconv"
 )
 
 type TransactionController struct {
 	Service *service.TransactionService
+	// Holds transfers above the approval threshold, transfers post immediately when nil
+	Approvals *service.ApprovalService
+	// Limits transfers to source accounts the caller is linked to, every account is allowed when nil
+	Authorizer *auth.Authorizer
+	// Limits transfers by source account once the caller's access to it is checked, unlimited when nil
+	AccountLimiter *ratelimit.Limiter
 }
 
-// Handles the creation of a transaction
+// Handles the creation of a transaction. Transfers above the approval threshold are held
+// and 202 Accepted returns the pending operation instead.
 func (transactionController *TransactionController) CreateTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "TransactionController.CreateTransactionHandler")
+	defer span.End()
+
 	var request model.TransactionRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, fmt.Sprintf("Error decoding request body: %v", err), http.StatusBadRequest)
 		return
 	}
+	span.SetAttributes(
+		tracing.AccountID("transaction.source_account_id", request.SourceAccountID),
+		tracing.AccountID("transaction.destination_account_id", request.DestinationAccountID),
+		tracing.Amount("transaction.amount", request.Amount))
+
+	if !transactionController.Authorizer.CheckAccount(w, r, request.SourceAccountID) {
+		return
+	}
+	if transactionController.AccountLimiter != nil && !transactionController.AccountLimiter.Allow(w, r, strconv.Itoa(request.SourceAccountID)) {
+		return
+	}
+
+	if transactionController.Approvals != nil && transactionController.Approvals.RequiresApproval(request.Amount) {
+		operation, err := transactionController.Approvals.SubmitTransfer(ctx, request, requestOperatorID(r))
+		if err != nil {
+			writeOperationError(w, r, err, "Error submitting transaction")
+			return
+		}
+		writeJSON(w, r, http.StatusAccepted, operation)
+		return
+	}
 
 	transaction := model.Transaction{
 		SourceAccountID:      request.SourceAccountID,
@@ -26,11 +63,20 @@ func (transactionController *TransactionController) CreateTransactionHandler(w h
 		Amount:               request.Amount,
 	}
 
-	if err := transactionController.Service.PerformTransaction(transaction); err != nil {
-		http.Error(w, fmt.Sprintf("Error processing transaction: %v", err), http.StatusInternalServerError)
+	posted, err := transactionController.Service.PerformTransactionWithContext(ctx, transaction)
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(w, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(w, r, "Error processing transaction", err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Transaction successful"))
+	if err := json.NewEncoder(w).Encode(posted); err != nil {
+		writeServerError(w, r, "Error encoding transaction data", err)
+	}
 }
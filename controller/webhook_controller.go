@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Handles the admin HTTP requests for webhook endpoints and deliveries
+type WebhookController struct {
+	Service *service.WebhookService
+}
+
+func NewWebhookController(webhookService *service.WebhookService) *WebhookController {
+	return &WebhookController{
+		Service: webhookService,
+	}
+}
+
+// Registers a webhook endpoint, the response is the only time its signing secret is returned
+func (webhookController *WebhookController) CreateEndpointHandler(writer http.ResponseWriter, request *http.Request) {
+	var input model.CreateWebhookEndpointInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		http.Error(writer, fmt.Sprintf("Invalid input: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	created, err := webhookController.Service.CreateEndpoint(request.Context(), input, requestOperatorID(request))
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error creating webhook endpoint", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusCreated, created)
+}
+
+// Lists webhook endpoints without their secrets
+func (webhookController *WebhookController) ListEndpointsHandler(writer http.ResponseWriter, request *http.Request) {
+	endpoints, err := webhookController.Service.ListEndpoints()
+	if err != nil {
+		writeServerError(writer, request, "Error fetching webhook endpoints", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, endpoints)
+}
+
+// Disables a webhook endpoint, no further events are delivered to it
+func (webhookController *WebhookController) DisableEndpointHandler(writer http.ResponseWriter, request *http.Request) {
+	endpointID, err := strconv.Atoi(mux.Vars(request)["endpoint_id"])
+	if err != nil {
+		http.Error(writer, "Invalid endpoint ID format", http.StatusBadRequest)
+		return
+	}
+
+	disabled, err := webhookController.Service.DisableEndpoint(request.Context(), endpointID, requestOperatorID(request))
+	if err != nil {
+		writeServerError(writer, request, "Error disabling webhook endpoint", err)
+		return
+	}
+	if !disabled {
+		http.Error(writer, fmt.Sprintf("Active webhook endpoint %d not found", endpointID), http.StatusNotFound)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// Queues a range of events for delivery to an endpoint again, answering with the number of deliveries queued
+func (webhookController *WebhookController) ReplayEventsHandler(writer http.ResponseWriter, request *http.Request) {
+	endpointID, err := strconv.Atoi(mux.Vars(request)["endpoint_id"])
+	if err != nil {
+		http.Error(writer, "Invalid endpoint ID format", http.StatusBadRequest)
+		return
+	}
+	var input model.ReplayWebhookEventsInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		http.Error(writer, fmt.Sprintf("Invalid input: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	queued, found, err := webhookController.Service.ReplayEvents(request.Context(), endpointID, input, requestOperatorID(request))
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error replaying webhook events", err)
+		return
+	}
+	if !found {
+		http.Error(writer, fmt.Sprintf("Active webhook endpoint %d not found", endpointID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusAccepted, map[string]int{"queued": queued})
+}
+
+// Lists the latest webhook deliveries, optionally only those with the status query parameter (e.g. dead)
+func (webhookController *WebhookController) ListDeliveriesHandler(writer http.ResponseWriter, request *http.Request) {
+	deliveries, err := webhookController.Service.ListDeliveries(request.URL.Query().Get("status"))
+	if err != nil {
+		var validationError *common.ValidationError
+		if errors.As(err, &validationError) {
+			http.Error(writer, validationError.Message, http.StatusBadRequest)
+			return
+		}
+		writeServerError(writer, request, "Error fetching webhook deliveries", err)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusOK, deliveries)
+}
+
+// Queues a delivery again with a fresh set of attempts, typically one from the dead letters
+func (webhookController *WebhookController) ReplayDeliveryHandler(writer http.ResponseWriter, request *http.Request) {
+	deliveryID, err := strconv.ParseInt(mux.Vars(request)["delivery_id"], 10, 64)
+	if err != nil {
+		http.Error(writer, "Invalid delivery ID format", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := webhookController.Service.ReplayDelivery(request.Context(), deliveryID, requestOperatorID(request))
+	if err != nil {
+		writeServerError(writer, request, "Error replaying webhook delivery", err)
+		return
+	}
+	if delivery == nil {
+		http.Error(writer, fmt.Sprintf("Webhook delivery %d not found", deliveryID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(writer, request, http.StatusAccepted, delivery)
+}
@@ -14,9 +14,44 @@ require github.com/shopspring/decimal v1.4.0
 
 require github.com/stretchr/testify v1.10.0
 
+require github.com/golang-jwt/jwt/v5 v5.3.1
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require go.opentelemetry.io/otel v1.35.0
+
+require go.opentelemetry.io/otel/sdk v1.35.0
+
+require go.opentelemetry.io/otel/trace v1.35.0
+
+require go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.35.0
+
+require go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.35.0
+
+require github.com/XSAM/otelsql v0.38.0
+
+require google.golang.org/grpc v1.71.0
+
+require google.golang.org/protobuf v1.36.5
+
+require github.com/gorilla/websocket v1.5.3
+
 require (
+	github.com/cenkalti/backoff/v4 v4.3.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.26.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/objx v0.5.2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.35.0 // indirect
+	go.opentelemetry.io/otel/metric v1.35.0 // indirect
+	go.opentelemetry.io/proto/otlp v1.5.0 // indirect
+	golang.org/x/net v0.35.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20250218202821-56aae31c358a // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
 )
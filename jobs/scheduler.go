@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/common"
+	"sync"
+	"time"
+)
+
+// A background task run on a fixed interval. Run must be safe to repeat, jobs are
+// expected to pick up where they left off rather than rely on exact timing.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Runs the job once when the scheduler starts instead of waiting a full interval
+	RunOnStart bool
+	Run        func(ctx context.Context) error
+}
+
+// Runs registered jobs in their own goroutines until stopped
+type Scheduler struct {
+	jobs   []Job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Adds a job, must be called before Start
+func (scheduler *Scheduler) Register(job Job) {
+	scheduler.jobs = append(scheduler.jobs, job)
+}
+
+// Starts every registered job
+func (scheduler *Scheduler) Start(ctx context.Context) {
+	ctx, scheduler.cancel = context.WithCancel(ctx)
+	for _, job := range scheduler.jobs {
+		scheduler.wg.Add(1)
+		go scheduler.loop(ctx, job)
+	}
+}
+
+// Stops all jobs and waits for running executions to return
+func (scheduler *Scheduler) Stop() {
+	scheduler.Shutdown(context.Background())
+}
+
+// Stops all jobs, cancelling the context of running executions, and waits for them to return until ctx ends
+func (scheduler *Scheduler) Shutdown(ctx context.Context) error {
+	if scheduler.cancel != nil {
+		scheduler.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("jobs still running: %w", ctx.Err())
+	}
+}
+
+func (scheduler *Scheduler) loop(ctx context.Context, job Job) {
+	defer scheduler.wg.Done()
+
+	if job.RunOnStart {
+		runJob(ctx, job)
+	}
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runJob(ctx, job)
+		}
+	}
+}
+
+func runJob(ctx context.Context, job Job) {
+	started := time.Now()
+	if err := job.Run(ctx); err != nil {
+		common.LogErrorWithContext(ctx, "job failed", "job", job.Name, "duration_ms", time.Since(started).Milliseconds(), "error", err)
+		return
+	}
+	common.LogInfoWithContext(ctx, "job completed", "job", job.Name, "duration_ms", time.Since(started).Milliseconds())
+}
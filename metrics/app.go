@@ -0,0 +1,55 @@
+package metrics
+
+import "database/sql"
+
+// Application metrics, registered in Default
+var (
+	HTTPRequests = Default.NewCounter("http_requests_total",
+		"HTTP requests by method, route template and status code.", "method", "route", "status")
+	HTTPRequestDuration = Default.NewHistogram("http_request_duration_seconds",
+		"HTTP request latency by method, route template and status code.",
+		[]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}, "method", "route", "status")
+
+	GRPCRequests = Default.NewCounter("grpc_requests_total",
+		"gRPC calls by full method name and status code.", "method", "code")
+	GRPCRequestDuration = Default.NewHistogram("grpc_request_duration_seconds",
+		"gRPC call latency by full method name and status code, streams are measured until they end.",
+		[]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}, "method", "code")
+
+	TransfersTotal = Default.NewCounter("transfers_total",
+		"Transfers attempted by transaction type and outcome (success or the class of error).", "type", "outcome")
+	TransferAmount = Default.NewHistogram("transfer_amount",
+		"Amounts of completed transfers by transaction type.",
+		[]float64{1, 10, 100, 1000, 10000, 100000, 1000000}, "type")
+
+	WebhookDeliveries = Default.NewCounter("webhook_deliveries_total",
+		"Webhook delivery attempts by event type and outcome (delivered, retry or dead).", "event_type", "outcome")
+
+	ServiceRetries = Default.NewCounter("service_retries_total",
+		"Operations retried after a timeout or deadlock, by operation.", "operation")
+
+	AuditWriteFailures = Default.NewCounter("audit_write_failures_total",
+		"Audit records that could not be written to the audit log.")
+)
+
+// Registers gauges and counters reading the database connection pool statistics on every scrape
+func RegisterDBStats(registry *Registry, stats func() sql.DBStats) {
+	registry.NewGaugeFunc("db_pool_max_open_connections", "Maximum number of open connections to the database.",
+		func() float64 { return float64(stats().MaxOpenConnections) })
+	registry.NewGaugeFunc("db_pool_open_connections", "Established connections, in use and idle.",
+		func() float64 { return float64(stats().OpenConnections) })
+	registry.NewGaugeFunc("db_pool_in_use_connections", "Connections currently in use.",
+		func() float64 { return float64(stats().InUse) })
+	registry.NewGaugeFunc("db_pool_idle_connections", "Idle connections.",
+		func() float64 { return float64(stats().Idle) })
+	registry.NewCounterFunc("db_pool_wait_count_total", "Connections waited for.",
+		func() float64 { return float64(stats().WaitCount) })
+	registry.NewCounterFunc("db_pool_wait_duration_seconds_total", "Time spent waiting for a connection.",
+		func() float64 { return stats().WaitDuration.Seconds() })
+	registry.NewCounterFunc("db_pool_max_idle_closed_total", "Connections closed because of the idle connection limit.",
+		func() float64 { return float64(stats().MaxIdleClosed) })
+	registry.NewCounterFunc("db_pool_max_idle_time_closed_total", "Connections closed because they were idle too long.",
+		func() float64 { return float64(stats().MaxIdleTimeClosed) })
+	registry.NewCounterFunc("db_pool_max_lifetime_closed_total", "Connections closed because they reached their lifetime.",
+		func() float64 { return float64(stats().MaxLifetimeClosed) })
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Route label of requests matching no route, so unknown paths do not create a series each
+const unmatchedRoute = "unmatched"
+
+// Counts and times every request served by the router, labelled with its route template (e.g.
+// /api/v1/accounts/{account_id}) rather than the path. Wraps the router so requests rejected by its
+// middleware (authentication, rate limits) are recorded too.
+func InstrumentRouter(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		route := unmatchedRoute
+		var match mux.RouteMatch
+		if router.Match(request, &match) && match.Route != nil {
+			if template, err := match.Route.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		recorder := &statusRecorder{ResponseWriter: writer, status: http.StatusOK}
+		started := time.Now()
+		router.ServeHTTP(recorder, request)
+
+		status := strconv.Itoa(recorder.status)
+		HTTPRequests.Inc(request.Method, route, status)
+		HTTPRequestDuration.Observe(time.Since(started).Seconds(), request.Method, route, status)
+	})
+}
+
+// Remembers the status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	if !recorder.wroteHeader {
+		recorder.status = status
+		recorder.wroteHeader = true
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(body []byte) (int, error) {
+	recorder.wroteHeader = true
+	return recorder.ResponseWriter.Write(body)
+}
+
+// Lets http.ResponseController reach the underlying writer, e.g. to flush streamed responses
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+func (recorder *statusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Lets WebSocket upgrades take over the connection, recorded as 101 Switching Protocols
+func (recorder *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buffer, err := http.NewResponseController(recorder.ResponseWriter).Hijack()
+	if err == nil && !recorder.wroteHeader {
+		recorder.status = http.StatusSwitchingProtocols
+		recorder.wroteHeader = true
+	}
+	return conn, buffer, err
+}
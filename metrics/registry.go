@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Holds metrics and writes them in the Prometheus text exposition format
+type Registry struct {
+	mutex   sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+type metric interface {
+	name() string
+	write(writer io.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// Registry the application metrics are registered in and /metrics serves
+var Default = NewRegistry()
+
+func (registry *Registry) register(m metric) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if registry.names[m.name()] {
+		panic(fmt.Sprintf("metric %s is already registered", m.name()))
+	}
+	registry.names[m.name()] = true
+	registry.metrics = append(registry.metrics, m)
+}
+
+// Writes every metric, sorted by name
+func (registry *Registry) WriteText(writer io.Writer) {
+	registry.mutex.Lock()
+	metrics := append([]metric(nil), registry.metrics...)
+	registry.mutex.Unlock()
+
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name() < metrics[j].name() })
+	for _, m := range metrics {
+		m.write(writer)
+	}
+}
+
+// Serves the registry for Prometheus scrapes
+func (registry *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		registry.WriteText(writer)
+	})
+}
+
+// Name, help and label names shared by every metric type
+type descriptor struct {
+	metricName string
+	help       string
+	labelNames []string
+}
+
+func (d descriptor) name() string {
+	return d.metricName
+}
+
+func (d descriptor) writeHeader(writer io.Writer, metricType string) {
+	fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, escapeHelp(d.help), d.metricName, metricType)
+}
+
+// Joins label values into a map key; label values are checked against the label names
+func (d descriptor) key(labelValues []string) string {
+	if len(labelValues) != len(d.labelNames) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", d.metricName, len(d.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+// Formats {name="value",...} for a key built by key, with extra trailing labels (e.g. le for buckets)
+func (d descriptor) labels(key string, extra ...string) string {
+	var values []string
+	if len(d.labelNames) > 0 {
+		values = strings.Split(key, "\xff")
+	}
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for i, value := range values {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, d.labelNames[i], escapeLabel(value)))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], escapeLabel(extra[i+1])))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(value)
+}
@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// A monotonically increasing value per label combination
+type Counter struct {
+	descriptor
+	mutex  sync.Mutex
+	values map[string]float64
+}
+
+// Creates and registers a counter with the given label names
+func (registry *Registry) NewCounter(name string, help string, labelNames ...string) *Counter {
+	counter := &Counter{descriptor: descriptor{metricName: name, help: help, labelNames: labelNames}, values: map[string]float64{}}
+	registry.register(counter)
+	return counter
+}
+
+// Adds one for the label values
+func (counter *Counter) Inc(labelValues ...string) {
+	counter.Add(1, labelValues...)
+}
+
+// Adds a non-negative amount for the label values
+func (counter *Counter) Add(amount float64, labelValues ...string) {
+	if amount < 0 {
+		panic(fmt.Sprintf("counter %s cannot decrease", counter.metricName))
+	}
+	key := counter.key(labelValues)
+	counter.mutex.Lock()
+	counter.values[key] += amount
+	counter.mutex.Unlock()
+}
+
+// Returns the current value for the label values
+func (counter *Counter) Value(labelValues ...string) float64 {
+	key := counter.key(labelValues)
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	return counter.values[key]
+}
+
+func (counter *Counter) write(writer io.Writer) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	counter.writeHeader(writer, "counter")
+	if len(counter.labelNames) == 0 && len(counter.values) == 0 {
+		fmt.Fprintf(writer, "%s 0\n", counter.metricName)
+	}
+	for _, key := range sortedKeys(counter.values) {
+		fmt.Fprintf(writer, "%s%s %s\n", counter.metricName, counter.labels(key), formatFloat(counter.values[key]))
+	}
+}
+
+// A value read when scraped, e.g. from sql.DB.Stats
+type FuncMetric struct {
+	descriptor
+	metricType string
+	read       func() float64
+}
+
+// Registers a gauge whose value is read on every scrape
+func (registry *Registry) NewGaugeFunc(name string, help string, read func() float64) *FuncMetric {
+	gauge := &FuncMetric{descriptor: descriptor{metricName: name, help: help}, metricType: "gauge", read: read}
+	registry.register(gauge)
+	return gauge
+}
+
+// Registers a counter whose value is read on every scrape, for totals kept elsewhere
+func (registry *Registry) NewCounterFunc(name string, help string, read func() float64) *FuncMetric {
+	counter := &FuncMetric{descriptor: descriptor{metricName: name, help: help}, metricType: "counter", read: read}
+	registry.register(counter)
+	return counter
+}
+
+func (funcMetric *FuncMetric) write(writer io.Writer) {
+	funcMetric.writeHeader(writer, funcMetric.metricType)
+	fmt.Fprintf(writer, "%s %s\n", funcMetric.metricName, formatFloat(funcMetric.read()))
+}
+
+// Counts observations into cumulative buckets per label combination
+type Histogram struct {
+	descriptor
+	buckets []float64
+	mutex   sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	// Observations per bucket, not cumulative; the last slot counts values above every bound
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Creates and registers a histogram with the given upper bucket bounds
+func (registry *Registry) NewHistogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+	histogram := &Histogram{
+		descriptor: descriptor{metricName: name, help: help, labelNames: labelNames},
+		buckets:    bounds,
+		series:     map[string]*histogramSeries{},
+	}
+	registry.register(histogram)
+	return histogram
+}
+
+// Records a value for the label values
+func (histogram *Histogram) Observe(value float64, labelValues ...string) {
+	key := histogram.key(labelValues)
+	index := sort.SearchFloat64s(histogram.buckets, value)
+
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+	series, found := histogram.series[key]
+	if !found {
+		series = &histogramSeries{counts: make([]uint64, len(histogram.buckets)+1)}
+		histogram.series[key] = series
+	}
+	series.counts[index]++
+	series.sum += value
+	series.count++
+}
+
+// Returns the number of observations for the label values
+func (histogram *Histogram) Count(labelValues ...string) uint64 {
+	key := histogram.key(labelValues)
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+	if series, found := histogram.series[key]; found {
+		return series.count
+	}
+	return 0
+}
+
+func (histogram *Histogram) write(writer io.Writer) {
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+
+	histogram.writeHeader(writer, "histogram")
+	for _, key := range sortedKeys(histogram.series) {
+		series := histogram.series[key]
+		var cumulative uint64
+		for i, bound := range histogram.buckets {
+			cumulative += series.counts[i]
+			fmt.Fprintf(writer, "%s_bucket%s %d\n", histogram.metricName, histogram.labels(key, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(writer, "%s_bucket%s %d\n", histogram.metricName, histogram.labels(key, "le", "+Inf"), series.count)
+		fmt.Fprintf(writer, "%s_sum%s %s\n", histogram.metricName, histogram.labels(key), formatFloat(series.sum))
+		fmt.Fprintf(writer, "%s_count%s %d\n", histogram.metricName, histogram.labels(key), series.count)
+	}
+}
@@ -0,0 +1,102 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Numbered migrations, each a pair of NNNN_name.up.sql and NNNN_name.down.sql
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// A schema version with the SQL moving to it from the previous version and back
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Returns the embedded migrations ordered by version. Versions must start at 1 without gaps and each must have
+// both an up and a down script.
+func Load() ([]Migration, error) {
+	return loadFrom(files, "sql")
+}
+
+func loadFrom(fileSystem fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fileSystem, dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not list migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s does not match NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fileSystem, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, found := byVersion[version]
+		if !found {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be consecutive from 1, found %d after %d", migration.Version, i)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+	}
+	return migrations, nil
+}
+
+// Returns the migrations to run to move from the current version to the target, in order, and whether they run up.
+// Moving down returns the applied migrations above the target, newest first.
+func Plan(migrations []Migration, current int, target int) ([]Migration, bool, error) {
+	if target < 0 || target > len(migrations) {
+		return nil, false, fmt.Errorf("version %d does not exist, expected 0 to %d", target, len(migrations))
+	}
+	if current > len(migrations) {
+		return nil, false, fmt.Errorf("database is at version %d, newer than the %d migrations this binary knows", current, len(migrations))
+	}
+
+	if target >= current {
+		return migrations[current:target], true, nil
+	}
+	steps := make([]Migration, 0, current-target)
+	for version := current; version > target; version-- {
+		steps = append(steps, migrations[version-1])
+	}
+	return steps, false, nil
+}
@@ -0,0 +1,217 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Key of the PostgreSQL advisory lock held while migrating, so instances starting together do not race
+const advisoryLockKey = 7346410001
+
+// Returned by CheckSchema when migrations are pending
+var ErrSchemaOutdated = errors.New("database schema is outdated")
+
+// A migration and when it was applied, nil when it is pending
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Applies and rolls back migrations, recording applied versions in schema_migrations
+type Migrator struct {
+	DB         *sqlx.DB
+	Migrations []Migration
+}
+
+// Creates a migrator for the embedded migrations
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// The version the embedded migrations lead to
+func (migrator *Migrator) LatestVersion() int {
+	return len(migrator.Migrations)
+}
+
+// Returns the highest applied version, 0 for a database that was never migrated
+func (migrator *Migrator) CurrentVersion(ctx context.Context) (int, error) {
+	return currentVersion(ctx, migrator.DB)
+}
+
+// Lists every known migration with the time it was applied
+func (migrator *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied := map[int]time.Time{}
+	exists, err := migrationsTableExists(ctx, migrator.DB)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		rows := []struct {
+			Version   int       `db:"version"`
+			AppliedAt time.Time `db:"applied_at"`
+		}{}
+		if err := migrator.DB.SelectContext(ctx, &rows, `SELECT version, applied_at FROM schema_migrations`); err != nil {
+			return nil, fmt.Errorf("error reading schema_migrations: %w", err)
+		}
+		for _, row := range rows {
+			applied[row.Version] = row.AppliedAt
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrator.Migrations))
+	for _, migration := range migrator.Migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, found := applied[migration.Version]; found {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Fails with ErrSchemaOutdated when the database is behind the embedded migrations
+func (migrator *Migrator) CheckSchema(ctx context.Context) error {
+	current, err := migrator.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current < migrator.LatestVersion() {
+		return fmt.Errorf("%w: at version %d, expected %d", ErrSchemaOutdated, current, migrator.LatestVersion())
+	}
+	if current > migrator.LatestVersion() {
+		slog.Warn("database schema is newer than the migrations of this binary", "version", current, "latest_version", migrator.LatestVersion())
+	}
+	return nil
+}
+
+// Applies all pending migrations, returning the ones applied
+func (migrator *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return migrator.To(ctx, migrator.LatestVersion())
+}
+
+// Rolls back the latest applied migration, returning it
+func (migrator *Migrator) Down(ctx context.Context) ([]Migration, error) {
+	var ran []Migration
+	err := migrator.withLock(ctx, func(conn *sqlx.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil || current == 0 {
+			return err
+		}
+		ran, err = migrator.run(ctx, conn, current, current-1)
+		return err
+	})
+	return ran, err
+}
+
+// Migrates up or down to the target version, returning the migrations run in order
+func (migrator *Migrator) To(ctx context.Context, target int) ([]Migration, error) {
+	var ran []Migration
+	err := migrator.withLock(ctx, func(conn *sqlx.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		ran, err = migrator.run(ctx, conn, current, target)
+		return err
+	})
+	return ran, err
+}
+
+// Runs each step in its own transaction so a failure leaves the schema at the last successful version
+func (migrator *Migrator) run(ctx context.Context, conn *sqlx.Conn, current int, target int) ([]Migration, error) {
+	steps, up, err := Plan(migrator.Migrations, current, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, migration := range steps {
+		tx, err := conn.BeginTxx(ctx, nil)
+		if err != nil {
+			return ran, fmt.Errorf("could not begin transaction: %w", err)
+		}
+
+		script, record := migration.Down, `DELETE FROM schema_migrations WHERE version = $1`
+		if up {
+			script, record = migration.Up, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+		}
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			tx.Rollback()
+			return ran, fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		args := []interface{}{migration.Version}
+		if up {
+			args = append(args, migration.Name)
+		}
+		if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+			tx.Rollback()
+			return ran, fmt.Errorf("could not record migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return ran, fmt.Errorf("could not commit migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		ran = append(ran, migration)
+	}
+	return ran, nil
+}
+
+// Runs fn on a dedicated connection holding the advisory lock, creating schema_migrations when missing.
+// Other instances wait for the lock and then see the migrations already applied.
+func (migrator *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := migrator.DB.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get a connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+		return fmt.Errorf("could not acquire the migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockKey)
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("could not create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+type queryer interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+func migrationsTableExists(ctx context.Context, db queryer) (bool, error) {
+	var exists bool
+	if err := db.GetContext(ctx, &exists, `SELECT to_regclass('schema_migrations') IS NOT NULL`); err != nil {
+		return false, fmt.Errorf("error checking for schema_migrations: %w", err)
+	}
+	return exists, nil
+}
+
+func currentVersion(ctx context.Context, db queryer) (int, error) {
+	exists, err := migrationsTableExists(ctx, db)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	if err := db.GetContext(ctx, &version, `SELECT MAX(version) FROM schema_migrations`); err != nil {
+		return 0, fmt.Errorf("error reading schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
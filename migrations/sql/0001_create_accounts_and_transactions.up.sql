@@ -0,0 +1,15 @@
+-- Original schema. IF NOT EXISTS lets databases created from the README script adopt the migrations.
+CREATE TABLE IF NOT EXISTS accounts (
+    account_id SERIAL PRIMARY KEY,
+    balance DECIMAL(15, 5) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    transaction_id SERIAL PRIMARY KEY,
+    source_account_id INT NOT NULL,
+    destination_account_id INT NOT NULL,
+    amount DECIMAL(15, 5) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (source_account_id) REFERENCES accounts(account_id),
+    FOREIGN KEY (destination_account_id) REFERENCES accounts(account_id)
+);
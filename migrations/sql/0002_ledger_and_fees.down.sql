@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS fee_schedules;
+DROP TABLE IF EXISTS ledger_entries;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee_breakdown;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee_amount;
+ALTER TABLE accounts DROP COLUMN IF EXISTS account_type;
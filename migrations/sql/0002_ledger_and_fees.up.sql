@@ -0,0 +1,39 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS account_type VARCHAR(50) NOT NULL DEFAULT 'standard';
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_amount DECIMAL(15, 5) NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_breakdown JSONB;
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    entry_id BIGSERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(transaction_id),
+    account_id INT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(15, 5) NOT NULL,
+    entry_type VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS ledger_entries_account_idx ON ledger_entries (account_id, created_at);
+
+-- Transfers made before the ledger existed get their two legs, so balances can be rebuilt from entries
+INSERT INTO ledger_entries (transaction_id, account_id, amount, entry_type, created_at)
+SELECT t.transaction_id, leg.account_id, leg.amount, 'transfer', COALESCE(t.created_at, CURRENT_TIMESTAMP)
+FROM transactions t
+CROSS JOIN LATERAL (VALUES (t.source_account_id, -t.amount), (t.destination_account_id, t.amount)) AS leg (account_id, amount)
+WHERE NOT EXISTS (SELECT 1 FROM ledger_entries l WHERE l.transaction_id = t.transaction_id)
+ORDER BY t.transaction_id;
+
+CREATE TABLE IF NOT EXISTS fee_schedules (
+    fee_schedule_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    source_account_type VARCHAR(50) NOT NULL DEFAULT '',
+    destination_account_type VARCHAR(50) NOT NULL DEFAULT '',
+    source_account_id INT REFERENCES accounts(account_id),
+    destination_account_id INT REFERENCES accounts(account_id),
+    fee_type VARCHAR(20) NOT NULL,
+    flat_amount DECIMAL(15, 5) NOT NULL DEFAULT 0,
+    percentage DECIMAL(9, 5) NOT NULL DEFAULT 0,
+    tiers JSONB,
+    min_fee DECIMAL(15, 5),
+    max_fee DECIMAL(15, 5),
+    effective_from TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS interest_accruals;
+DROP TABLE IF EXISTS interest_configs;
+ALTER TABLE transactions DROP COLUMN IF EXISTS transaction_type;
@@ -0,0 +1,23 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS transaction_type VARCHAR(30) NOT NULL DEFAULT 'transfer';
+
+CREATE TABLE IF NOT EXISTS interest_configs (
+    account_id INT PRIMARY KEY REFERENCES accounts(account_id),
+    annual_rate DECIMAL(9, 5) NOT NULL,
+    day_count_convention VARCHAR(10) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    start_date DATE NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    accrual_id BIGSERIAL PRIMARY KEY,
+    account_id INT NOT NULL REFERENCES accounts(account_id),
+    accrual_date DATE NOT NULL,
+    closing_balance DECIMAL(15, 5) NOT NULL,
+    annual_rate DECIMAL(9, 5) NOT NULL,
+    day_count_convention VARCHAR(10) NOT NULL,
+    amount DECIMAL(20, 10) NOT NULL,
+    posted_transaction_id INT REFERENCES transactions(transaction_id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_id, accrual_date)
+);
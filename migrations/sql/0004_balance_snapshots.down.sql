@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS account_balance_snapshots;
+ALTER TABLE accounts DROP COLUMN IF EXISTS created_at;
+ALTER TABLE accounts DROP COLUMN IF EXISTS initial_balance;
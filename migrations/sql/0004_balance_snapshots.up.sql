@@ -0,0 +1,16 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS initial_balance DECIMAL(15, 5) NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Accounts created before initial balances were recorded: the opening balance is what the ledger does not explain,
+-- and the account existed at least since its first entry. Accounts already consistent are left unchanged.
+UPDATE accounts a
+SET initial_balance = a.balance - COALESCE((SELECT SUM(l.amount) FROM ledger_entries l WHERE l.account_id = a.account_id), 0),
+    created_at = LEAST(a.created_at, COALESCE((SELECT MIN(l.created_at) FROM ledger_entries l WHERE l.account_id = a.account_id), a.created_at))
+WHERE a.initial_balance = 0;
+
+CREATE TABLE IF NOT EXISTS account_balance_snapshots (
+    account_id INT NOT NULL REFERENCES accounts(account_id),
+    snapshot_date DATE NOT NULL,
+    balance DECIMAL(15, 5) NOT NULL,
+    PRIMARY KEY (account_id, snapshot_date)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reconciliation_mismatches;
+DROP TABLE IF EXISTS reconciliation_runs;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS reconciliation_runs (
+    run_id SERIAL PRIMARY KEY,
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    accounts_checked INT NOT NULL DEFAULT 0,
+    mismatch_count INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS reconciliation_mismatches (
+    mismatch_id SERIAL PRIMARY KEY,
+    run_id INT NOT NULL REFERENCES reconciliation_runs(run_id),
+    account_id INT NOT NULL REFERENCES accounts(account_id),
+    stored_balance DECIMAL(15, 5) NOT NULL,
+    expected_balance DECIMAL(15, 5) NOT NULL,
+    ledger_balance DECIMAL(15, 5) NOT NULL,
+    difference DECIMAL(15, 5) NOT NULL,
+    details TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    approved_by VARCHAR(100),
+    approved_at TIMESTAMP,
+    correction_transaction_id INT REFERENCES transactions(transaction_id)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+    adjustment_id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(transaction_id),
+    account_id INT NOT NULL REFERENCES accounts(account_id),
+    suspense_account_id INT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(15, 5) NOT NULL,
+    reason_code VARCHAR(30) NOT NULL,
+    reference VARCHAR(100) NOT NULL,
+    operator_id VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS balance_adjustments_account_idx ON balance_adjustments (account_id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS pending_operations (
+    operation_id SERIAL PRIMARY KEY,
+    operation_type VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    amount DECIMAL(15, 5) NOT NULL,
+    payload JSONB NOT NULL,
+    requested_by VARCHAR(100) NOT NULL,
+    requested_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    decided_by VARCHAR(100),
+    decided_at TIMESTAMP,
+    reason TEXT,
+    transaction_id INT REFERENCES transactions(transaction_id)
+);
+CREATE INDEX IF NOT EXISTS pending_operations_status_idx ON pending_operations (status, expires_at);
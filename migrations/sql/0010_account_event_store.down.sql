@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS account_aggregate_snapshots;
+DROP TABLE IF EXISTS account_event_store;
+DROP FUNCTION IF EXISTS reject_account_event_change();
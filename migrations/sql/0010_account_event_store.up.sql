@@ -0,0 +1,54 @@
+-- Every change to an account as an immutable event, versioned per account. The accounts table is a projection of it.
+CREATE TABLE IF NOT EXISTS account_event_store (
+    event_id BIGSERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    version BIGINT NOT NULL,
+    event_type VARCHAR(30) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_id, version)
+);
+CREATE INDEX IF NOT EXISTS account_event_store_created_idx ON account_event_store (account_id, created_at);
+
+CREATE OR REPLACE FUNCTION reject_account_event_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'account events are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS account_event_store_immutable ON account_event_store;
+CREATE TRIGGER account_event_store_immutable BEFORE UPDATE OR DELETE ON account_event_store
+FOR EACH ROW EXECUTE FUNCTION reject_account_event_change();
+DROP TRIGGER IF EXISTS account_event_store_no_truncate ON account_event_store;
+CREATE TRIGGER account_event_store_no_truncate BEFORE TRUNCATE ON account_event_store
+FOR EACH STATEMENT EXECUTE FUNCTION reject_account_event_change();
+
+-- State of an account at a version, so replays start from the latest snapshot instead of the first event
+CREATE TABLE IF NOT EXISTS account_aggregate_snapshots (
+    account_id INT NOT NULL,
+    version BIGINT NOT NULL,
+    account_type VARCHAR(50) NOT NULL,
+    balance DECIMAL(15, 5) NOT NULL,
+    initial_balance DECIMAL(15, 5) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    taken_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, version)
+);
+
+-- Existing accounts get their history from the ledger: opened with the initial balance, then one event per entry
+INSERT INTO account_event_store (account_id, version, event_type, data, created_at)
+SELECT a.account_id, 1, 'AccountOpened',
+    jsonb_build_object('account_type', a.account_type, 'initial_balance', a.initial_balance::text), a.created_at
+FROM accounts a
+WHERE NOT EXISTS (SELECT 1 FROM account_event_store e WHERE e.account_id = a.account_id)
+ORDER BY a.account_id;
+
+INSERT INTO account_event_store (account_id, version, event_type, data, created_at)
+SELECT l.account_id, 1 + ROW_NUMBER() OVER (PARTITION BY l.account_id ORDER BY l.entry_id),
+    CASE WHEN l.amount < 0 THEN 'AccountDebited' ELSE 'AccountCredited' END,
+    jsonb_build_object('transaction_id', l.transaction_id, 'entry_id', l.entry_id, 'entry_type', l.entry_type,
+        'amount', ABS(l.amount)::text),
+    l.created_at
+FROM ledger_entries l
+WHERE NOT EXISTS (SELECT 1 FROM account_event_store e WHERE e.account_id = l.account_id AND e.version > 1)
+ORDER BY l.account_id, l.entry_id;
@@ -1,25 +1,49 @@
 package model
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Account type assigned when none is provided on creation
+const DefaultAccountType = "standard"
 
 type Account struct {
-	AccountID int             `json:"account_id" db:"account_id"`
-	Balance   decimal.Decimal `json:"balance" db:"balance"`
+	AccountID      int             `json:"account_id" db:"account_id"`
+	AccountType    string          `json:"account_type" db:"account_type"`
+	Balance        decimal.Decimal `json:"balance" db:"balance"`
+	InitialBalance decimal.Decimal `json:"initial_balance" db:"initial_balance"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
 }
 
 func NewAccount(accountID int, initialBalance decimal.Decimal) *Account {
 	return &Account{
-		AccountID: accountID,
-		Balance:   initialBalance,
+		AccountID:      accountID,
+		AccountType:    DefaultAccountType,
+		Balance:        initialBalance,
+		InitialBalance: initialBalance,
 	}
 }
 
 type CreateAccountInput struct {
 	AccountID      int             `json:"account_id"`
+	AccountType    string          `json:"account_type,omitempty"`
 	InitialBalance decimal.Decimal `json:"initial_balance"`
 }
 
-type UpdateAccountInput struct {
+// Closing balance of an account at the end of a day
+type BalanceSnapshot struct {
+	AccountID    int             `json:"account_id" db:"account_id"`
+	SnapshotDate time.Time       `json:"snapshot_date" db:"snapshot_date"`
+	Balance      decimal.Decimal `json:"balance" db:"balance"`
+}
+
+// Balance of an account reconstructed at a point in time
+type HistoricalBalance struct {
 	AccountID int             `json:"account_id"`
+	AsOf      time.Time       `json:"as_of"`
 	Balance   decimal.Decimal `json:"balance"`
+	// Snapshot the reconstruction started from, nil when replayed from the initial balance
+	SnapshotDate *time.Time `json:"snapshot_date,omitempty"`
 }
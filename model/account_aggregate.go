@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Types of the events in the account event store
+const (
+	AccountOpenedEvent   = "AccountOpened"
+	AccountCreditedEvent = "AccountCredited"
+	AccountDebitedEvent  = "AccountDebited"
+)
+
+// An immutable change to an account, numbered by version from 1 per account. The data is AccountOpened for the
+// first event and AccountBalanceChange for the others.
+type StoredAccountEvent struct {
+	EventID   int64           `json:"event_id" db:"event_id"`
+	AccountID int             `json:"account_id" db:"account_id"`
+	Version   int64           `json:"version" db:"version"`
+	EventType string          `json:"event_type" db:"event_type"`
+	Data      json.RawMessage `json:"data" db:"data"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// Data of an AccountOpened event
+type AccountOpened struct {
+	AccountType    string          `json:"account_type"`
+	InitialBalance decimal.Decimal `json:"initial_balance"`
+}
+
+// Data of an AccountCredited or AccountDebited event, one per ledger entry on the account
+type AccountBalanceChange struct {
+	TransactionID int    `json:"transaction_id"`
+	EntryID       int    `json:"entry_id"`
+	EntryType     string `json:"entry_type"`
+	// Always positive, the event type gives the direction
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// An account rebuilt by replaying its events, as of the version of the last one applied
+type AccountAggregate struct {
+	Account
+	Version int64 `json:"version"`
+	// Version of the snapshot the replay started from, zero when replayed from the first event
+	SnapshotVersion int64 `json:"snapshot_version"`
+	ReplayedEvents  int   `json:"replayed_events"`
+}
+
+// Applies the next event of the account, which must follow the aggregate's version without a gap
+func (aggregate *AccountAggregate) Apply(event StoredAccountEvent) error {
+	if event.Version != aggregate.Version+1 {
+		return fmt.Errorf("event %d of account %d has version %d, expected %d", event.EventID, event.AccountID, event.Version, aggregate.Version+1)
+	}
+
+	switch event.EventType {
+	case AccountOpenedEvent:
+		var opened AccountOpened
+		if err := json.Unmarshal(event.Data, &opened); err != nil {
+			return fmt.Errorf("invalid data in event %d: %w", event.EventID, err)
+		}
+		aggregate.Account = Account{
+			AccountID:      event.AccountID,
+			AccountType:    opened.AccountType,
+			Balance:        opened.InitialBalance,
+			InitialBalance: opened.InitialBalance,
+			CreatedAt:      event.CreatedAt,
+		}
+	case AccountCreditedEvent, AccountDebitedEvent:
+		if aggregate.Version == 0 {
+			return fmt.Errorf("event %d changes account %d before it was opened", event.EventID, event.AccountID)
+		}
+		var change AccountBalanceChange
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return fmt.Errorf("invalid data in event %d: %w", event.EventID, err)
+		}
+		if event.EventType == AccountDebitedEvent {
+			aggregate.Balance = aggregate.Balance.Sub(change.Amount)
+		} else {
+			aggregate.Balance = aggregate.Balance.Add(change.Amount)
+		}
+	default:
+		return fmt.Errorf("event %d has unknown type %q", event.EventID, event.EventType)
+	}
+
+	aggregate.Version = event.Version
+	aggregate.ReplayedEvents++
+	return nil
+}
+
+// State of an account at a version, saved periodically so replays start from it
+type AccountAggregateSnapshot struct {
+	Account
+	Version int64     `json:"version" db:"version"`
+	TakenAt time.Time `json:"taken_at" db:"taken_at"`
+}
+
+// An account whose row in the accounts table differs from the replay of its events
+type ProjectionDifference struct {
+	AccountID int `json:"account_id"`
+	// Nil when the account has no row
+	Stored   *Account `json:"stored"`
+	Replayed Account  `json:"replayed"`
+}
+
+// Outcome of regenerating the accounts table from the event store
+type ProjectionRebuild struct {
+	DryRun           bool `json:"dry_run"`
+	AccountsReplayed int  `json:"accounts_replayed"`
+	// Rows written, none on a dry run
+	AccountsUpdated int                    `json:"accounts_updated"`
+	Differences     []ProjectionDifference `json:"differences"`
+	// Accounts with a row but no events, left as they are
+	AccountsWithoutEvents []int `json:"accounts_without_events"`
+}
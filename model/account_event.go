@@ -0,0 +1,24 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+// Types of the events pushed to account event streams
+const (
+	AccountEventTransaction = "transaction"
+	AccountEventBalance     = "balance"
+)
+
+// An event pushed to the event streams of an account. A transaction event carries the transaction ID as its
+// event ID, resuming after it replays the later transactions. A balance event carries the stored balance and
+// follows the replay and every new transaction, it has no event ID.
+type AccountEvent struct {
+	Type      string `json:"type"`
+	EventID   int    `json:"event_id,omitempty"`
+	AccountID int    `json:"account_id"`
+	// Transaction events only
+	Transaction *Transaction `json:"transaction,omitempty"`
+	// Transaction events only: the signed change of the account's balance, fees included
+	BalanceChange *decimal.Decimal `json:"balance_change,omitempty"`
+	// Balance events only
+	Balance *decimal.Decimal `json:"balance,omitempty"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Reasons an operator may give for adjusting a balance
+var AdjustmentReasonCodes = []string{
+	"correction",
+	"goodwill",
+	"chargeback",
+	"write_off",
+	"migration",
+}
+
+// An operator initiated balance change, posted as a transaction against the suspense account
+type Adjustment struct {
+	AdjustmentID      int `json:"adjustment_id" db:"adjustment_id"`
+	TransactionID     int `json:"transaction_id" db:"transaction_id"`
+	AccountID         int `json:"account_id" db:"account_id"`
+	SuspenseAccountID int `json:"suspense_account_id" db:"suspense_account_id"`
+	// Signed change to the account's balance, positive credits the account
+	Amount     decimal.Decimal `json:"amount" db:"amount"`
+	ReasonCode string          `json:"reason_code" db:"reason_code"`
+	Reference  string          `json:"reference" db:"reference"`
+	OperatorID string          `json:"operator_id" db:"operator_id"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+type CreateAdjustmentInput struct {
+	Amount     decimal.Decimal `json:"amount"`
+	ReasonCode string          `json:"reason_code"`
+	Reference  string          `json:"reference"`
+	// Used when the request carries no X-Operator-ID header
+	OperatorID string `json:"operator_id,omitempty"`
+}
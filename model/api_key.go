@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// A credential for machine clients. Only a hash of the key is stored, the key itself is shown once on creation.
+type APIKey struct {
+	KeyID     int            `json:"key_id" db:"key_id"`
+	Name      string         `json:"name" db:"name"`
+	KeyPrefix string         `json:"key_prefix" db:"key_prefix"`
+	KeyHash   string         `json:"-" db:"key_hash"`
+	Roles     pq.StringArray `json:"roles" db:"roles"`
+	// Accounts the key may read and debit when its roles are limited to linked accounts
+	AccountIDs pq.Int64Array `json:"account_ids" db:"account_ids"`
+	CreatedBy  string        `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+	RevokedAt  *time.Time    `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+type CreateAPIKeyInput struct {
+	Name       string   `json:"name"`
+	Roles      []string `json:"roles"`
+	AccountIDs []int    `json:"account_ids,omitempty"`
+}
+
+// Returned once when a key is created, carrying the key in clear
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
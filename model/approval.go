@@ -0,0 +1,69 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Operations that can be held for approval
+const (
+	OperationTypeTransfer   = "transfer"
+	OperationTypeAdjustment = "adjustment"
+)
+
+// Pending operation states. An approved operation is executed straight away and ends as executed or failed.
+const (
+	OperationStatusPending  = "pending"
+	OperationStatusApproved = "approved"
+	OperationStatusRejected = "rejected"
+	OperationStatusExpired  = "expired"
+	OperationStatusExecuted = "executed"
+	OperationStatusFailed   = "failed"
+)
+
+// A transfer or adjustment held until a second operator approves it
+type PendingOperation struct {
+	OperationID   int               `json:"operation_id" db:"operation_id"`
+	OperationType string            `json:"operation_type" db:"operation_type"`
+	Status        string            `json:"status" db:"status"`
+	Amount        decimal.Decimal   `json:"amount" db:"amount"`
+	Payload       *OperationPayload `json:"payload" db:"payload"`
+	RequestedBy   string            `json:"requested_by" db:"requested_by"`
+	RequestedAt   time.Time         `json:"requested_at" db:"requested_at"`
+	ExpiresAt     time.Time         `json:"expires_at" db:"expires_at"`
+	DecidedBy     *string           `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedAt     *time.Time        `json:"decided_at,omitempty" db:"decided_at"`
+	// Reason given on rejection, or the error when execution failed
+	Reason        *string `json:"reason,omitempty" db:"reason"`
+	TransactionID *int    `json:"transaction_id,omitempty" db:"transaction_id"`
+}
+
+// The request to execute once approved, exactly one field is set
+type OperationPayload struct {
+	Transfer   *TransactionRequest `json:"transfer,omitempty"`
+	Adjustment *AdjustmentRequest  `json:"adjustment,omitempty"`
+}
+
+// An adjustment request together with the account it applies to
+type AdjustmentRequest struct {
+	AccountID int `json:"account_id"`
+	CreateAdjustmentInput
+}
+
+func (payload *OperationPayload) Value() (driver.Value, error) {
+	if payload == nil {
+		return nil, nil
+	}
+	return json.Marshal(payload)
+}
+
+func (payload *OperationPayload) Scan(src interface{}) error {
+	return scanJSON(src, payload)
+}
+
+type RejectOperationInput struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type FeeType string
+
+const (
+	FeeTypeFlat       FeeType = "flat"
+	FeeTypePercentage FeeType = "percentage"
+	FeeTypeTiered     FeeType = "tiered"
+)
+
+// A band of a tiered fee schedule. Applies to amounts up to and including UpTo (no bound when null)
+type FeeTier struct {
+	UpTo       decimal.NullDecimal `json:"up_to"`
+	FlatAmount decimal.Decimal     `json:"flat_amount"`
+	Percentage decimal.Decimal     `json:"percentage"`
+}
+
+type FeeTiers []FeeTier
+
+// Fee charged on transfers matching the schedule's account types or account pair.
+// Empty types and null account IDs act as wildcards.
+type FeeSchedule struct {
+	FeeScheduleID          int                 `json:"fee_schedule_id" db:"fee_schedule_id"`
+	Name                   string              `json:"name" db:"name"`
+	SourceAccountType      string              `json:"source_account_type" db:"source_account_type"`
+	DestinationAccountType string              `json:"destination_account_type" db:"destination_account_type"`
+	SourceAccountID        *int                `json:"source_account_id,omitempty" db:"source_account_id"`
+	DestinationAccountID   *int                `json:"destination_account_id,omitempty" db:"destination_account_id"`
+	FeeType                FeeType             `json:"fee_type" db:"fee_type"`
+	FlatAmount             decimal.Decimal     `json:"flat_amount" db:"flat_amount"`
+	Percentage             decimal.Decimal     `json:"percentage" db:"percentage"`
+	Tiers                  FeeTiers            `json:"tiers,omitempty" db:"tiers"`
+	MinFee                 decimal.NullDecimal `json:"min_fee" db:"min_fee"`
+	MaxFee                 decimal.NullDecimal `json:"max_fee" db:"max_fee"`
+	EffectiveFrom          time.Time           `json:"effective_from" db:"effective_from"`
+	CreatedAt              time.Time           `json:"created_at" db:"created_at"`
+}
+
+type CreateFeeScheduleInput struct {
+	Name                   string              `json:"name"`
+	SourceAccountType      string              `json:"source_account_type"`
+	DestinationAccountType string              `json:"destination_account_type"`
+	SourceAccountID        *int                `json:"source_account_id"`
+	DestinationAccountID   *int                `json:"destination_account_id"`
+	FeeType                FeeType             `json:"fee_type"`
+	FlatAmount             decimal.Decimal     `json:"flat_amount"`
+	Percentage             decimal.Decimal     `json:"percentage"`
+	Tiers                  FeeTiers            `json:"tiers"`
+	MinFee                 decimal.NullDecimal `json:"min_fee"`
+	MaxFee                 decimal.NullDecimal `json:"max_fee"`
+	EffectiveFrom          *time.Time          `json:"effective_from"`
+}
+
+// How the fee on a transaction was computed, stored alongside the transaction record
+type FeeBreakdown struct {
+	FeeScheduleID  int             `json:"fee_schedule_id"`
+	ScheduleName   string          `json:"schedule_name"`
+	FeeType        FeeType         `json:"fee_type"`
+	FeeAccountID   int             `json:"fee_account_id"`
+	CalculatedFee  decimal.Decimal `json:"calculated_fee"`
+	CapApplied     string          `json:"cap_applied,omitempty"`
+	ChargedFee     decimal.Decimal `json:"charged_fee"`
+	TransferAmount decimal.Decimal `json:"transfer_amount"`
+}
+
+func (tiers FeeTiers) Value() (driver.Value, error) {
+	if tiers == nil {
+		return nil, nil
+	}
+	return json.Marshal(tiers)
+}
+
+func (tiers *FeeTiers) Scan(src interface{}) error {
+	return scanJSON(src, tiers)
+}
+
+func (breakdown *FeeBreakdown) Value() (driver.Value, error) {
+	if breakdown == nil {
+		return nil, nil
+	}
+	return json.Marshal(breakdown)
+}
+
+func (breakdown *FeeBreakdown) Scan(src interface{}) error {
+	return scanJSON(src, breakdown)
+}
+
+// Decodes a JSON/JSONB column into target, leaving it untouched for NULL
+func scanJSON(src interface{}, target interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(value, target)
+	case string:
+		return json.Unmarshal([]byte(value), target)
+	default:
+		return fmt.Errorf("unsupported type %T for JSON column", src)
+	}
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Day-count conventions used to turn an annual rate into a daily accrual
+const (
+	DayCountActual365 = "ACT/365"
+	DayCount30360     = "30/360"
+)
+
+// Per-account interest settings. A positive rate credits the account from the interest
+// expense account, a negative rate debits it.
+type InterestConfig struct {
+	AccountID          int             `json:"account_id" db:"account_id"`
+	AnnualRate         decimal.Decimal `json:"annual_rate" db:"annual_rate"`
+	DayCountConvention string          `json:"day_count_convention" db:"day_count_convention"`
+	Enabled            bool            `json:"enabled" db:"enabled"`
+	// First day interest accrues for
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type InterestConfigInput struct {
+	AnnualRate         decimal.Decimal `json:"annual_rate"`
+	DayCountConvention string          `json:"day_count_convention"`
+	Enabled            *bool           `json:"enabled"`
+	StartDate          *time.Time      `json:"start_date"`
+}
+
+// Interest accrued on one account for one day, based on that day's closing balance
+type InterestAccrual struct {
+	AccrualID           int             `json:"accrual_id" db:"accrual_id"`
+	AccountID           int             `json:"account_id" db:"account_id"`
+	AccrualDate         time.Time       `json:"accrual_date" db:"accrual_date"`
+	ClosingBalance      decimal.Decimal `json:"closing_balance" db:"closing_balance"`
+	AnnualRate          decimal.Decimal `json:"annual_rate" db:"annual_rate"`
+	DayCountConvention  string          `json:"day_count_convention" db:"day_count_convention"`
+	Amount              decimal.Decimal `json:"amount" db:"amount"`
+	PostedTransactionID *int            `json:"posted_transaction_id,omitempty" db:"posted_transaction_id"`
+	CreatedAt           time.Time       `json:"created_at" db:"created_at"`
+}
+
+// Accrued interest awaiting posting for one account
+type UnpostedInterest struct {
+	AccountID     int             `db:"account_id"`
+	Total         decimal.Decimal `db:"total"`
+	LastAccrualID int             `db:"last_accrual_id"`
+}
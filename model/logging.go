@@ -0,0 +1,6 @@
+package model
+
+// Level of the application log: debug, info, warn or error
+type LogLevel struct {
+	Level string `json:"level"`
+}
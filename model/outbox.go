@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Types of the domain events written to the outbox and delivered to webhooks
+const (
+	EventTransferCompleted = "TransferCompleted"
+	EventTransferFailed    = "TransferFailed"
+	EventAccountCreated    = "AccountCreated"
+	EventBalanceAdjusted   = "BalanceAdjusted"
+)
+
+var EventTypes = []string{
+	EventTransferCompleted,
+	EventTransferFailed,
+	EventAccountCreated,
+	EventBalanceAdjusted,
+}
+
+// A domain event stored in the outbox table, in the same database transaction as the change it describes.
+// The payload is the transaction (TransferCompleted), TransferFailure, Account or Adjustment.
+type OutboxEvent struct {
+	EventID   int64           `json:"event_id" db:"event_id"`
+	EventType string          `json:"event_type" db:"event_type"`
+	Payload   json.RawMessage `json:"data" db:"payload"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// Payload of a TransferFailed event
+type TransferFailure struct {
+	SourceAccountID      int             `json:"source_account_id"`
+	DestinationAccountID int             `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	// Error class, as in the transfers_total metric: invalid_amount, account_not_found, insufficient_funds, fee_error,
+	// timeout, posting_failed or error
+	Reason string `json:"reason"`
+	// Fixed description of the reason, never the underlying error
+	Error string `json:"error"`
+}
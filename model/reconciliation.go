@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Mismatch states, corrections are only posted for approved mismatches
+const (
+	MismatchStatusOpen      = "open"
+	MismatchStatusCorrected = "corrected"
+)
+
+// Stored balance of an account next to the balances implied by its history
+type AccountReconciliation struct {
+	AccountID        int             `db:"account_id"`
+	StoredBalance    decimal.Decimal `db:"stored_balance"`
+	InitialBalance   decimal.Decimal `db:"initial_balance"`
+	TransactionTotal decimal.Decimal `db:"transaction_total"`
+	LedgerTotal      decimal.Decimal `db:"ledger_total"`
+}
+
+// One execution of the reconciliation job
+type ReconciliationRun struct {
+	RunID           int                      `json:"run_id" db:"run_id"`
+	StartedAt       time.Time                `json:"started_at" db:"started_at"`
+	CompletedAt     *time.Time               `json:"completed_at,omitempty" db:"completed_at"`
+	AccountsChecked int                      `json:"accounts_checked" db:"accounts_checked"`
+	MismatchCount   int                      `json:"mismatch_count" db:"mismatch_count"`
+	Mismatches      []ReconciliationMismatch `json:"mismatches,omitempty" db:"-"`
+}
+
+// An account whose stored balance disagrees with its initial balance plus its transactions,
+// or whose ledger entries disagree with its transactions
+type ReconciliationMismatch struct {
+	MismatchID      int             `json:"mismatch_id" db:"mismatch_id"`
+	RunID           int             `json:"run_id" db:"run_id"`
+	AccountID       int             `json:"account_id" db:"account_id"`
+	StoredBalance   decimal.Decimal `json:"stored_balance" db:"stored_balance"`
+	ExpectedBalance decimal.Decimal `json:"expected_balance" db:"expected_balance"`
+	LedgerBalance   decimal.Decimal `json:"ledger_balance" db:"ledger_balance"`
+	// Stored balance minus expected balance
+	Difference              decimal.Decimal `json:"difference" db:"difference"`
+	Details                 string          `json:"details" db:"details"`
+	Status                  string          `json:"status" db:"status"`
+	ApprovedBy              *string         `json:"approved_by,omitempty" db:"approved_by"`
+	ApprovedAt              *time.Time      `json:"approved_at,omitempty" db:"approved_at"`
+	CorrectionTransactionID *int            `json:"correction_transaction_id,omitempty" db:"correction_transaction_id"`
+}
+
+type ApproveCorrectionInput struct {
+	ApprovedBy string `json:"approved_by"`
+}
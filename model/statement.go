@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Supported statement output formats
+const (
+	StatementFormatCSV  = "csv"
+	StatementFormatJSON = "json"
+	StatementFormatPDF  = "pdf"
+)
+
+// One ledger movement on a statement, with the account balance right after it
+type StatementLine struct {
+	EntryID               int             `json:"entry_id" db:"entry_id"`
+	TransactionID         int             `json:"transaction_id" db:"transaction_id"`
+	PostedAt              time.Time       `json:"posted_at" db:"posted_at"`
+	TransactionType       string          `json:"transaction_type" db:"transaction_type"`
+	EntryType             string          `json:"entry_type" db:"entry_type"`
+	CounterpartyAccountID int             `json:"counterparty_account_id" db:"counterparty_account_id"`
+	Amount                decimal.Decimal `json:"amount" db:"amount"`
+	RunningBalance        decimal.Decimal `json:"running_balance" db:"-"`
+}
+
+// Account activity over a period
+type Statement struct {
+	AccountID      int             `json:"account_id"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+	TotalCredits   decimal.Decimal `json:"total_credits"`
+	TotalDebits    decimal.Decimal `json:"total_debits"`
+	ClosingBalance decimal.Decimal `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+}
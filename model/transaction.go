@@ -1,11 +1,30 @@
 package model
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Transaction types, fees only apply to customer initiated transfers
+const (
+	TransactionTypeTransfer = "transfer"
+	TransactionTypeInterest = "interest"
+	// Books an unexplained balance difference found by reconciliation against the suspense account
+	TransactionTypeReconciliation = "reconciliation"
+	// Operator initiated balance change booked against the suspense account
+	TransactionTypeAdjustment = "adjustment"
+)
 
 type Transaction struct {
+	TransactionID        int             `json:"transaction_id" db:"transaction_id"`
+	TransactionType      string          `json:"transaction_type" db:"transaction_type"`
 	SourceAccountID      int             `json:"source_account_id" db:"source_account_id"`
 	DestinationAccountID int             `json:"destination_account_id" db:"destination_account_id"`
 	Amount               decimal.Decimal `json:"amount" db:"amount"`
+	FeeAmount            decimal.Decimal `json:"fee_amount" db:"fee_amount"`
+	FeeBreakdown         *FeeBreakdown   `json:"fee_breakdown,omitempty" db:"fee_breakdown"`
+	CreatedAt            time.Time       `json:"created_at" db:"created_at"`
 }
 
 type TransactionRequest struct {
@@ -21,3 +40,20 @@ func NewTransaction(sourceAccountID, destinationAccountID int, amount decimal.De
 		Amount:               amount,
 	}
 }
+
+// Ledger entry type for fee legs, the principal legs carry the transaction type
+const EntryTypeFee = "fee"
+
+// A single signed balance movement on one account, posted as part of a transaction
+type LedgerEntry struct {
+	EntryID       int             `json:"entry_id" db:"entry_id"`
+	TransactionID int             `json:"transaction_id" db:"transaction_id"`
+	AccountID     int             `json:"account_id" db:"account_id"`
+	Amount        decimal.Decimal `json:"amount" db:"amount"`
+	EntryType     string          `json:"entry_type" db:"entry_type"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	// Lets the leg take the account below zero, used for system accounts (e.g. fee revenue)
+	AllowNegative bool `json:"-" db:"-"`
+	// Records the leg without changing the stored balance, which already reflects it
+	RecordOnly bool `json:"-" db:"-"`
+}
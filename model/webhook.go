@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Statuses of a webhook delivery
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	// Given up after the maximum number of attempts, until replayed
+	WebhookDeliveryDead = "dead"
+)
+
+// A URL outbox events are delivered to, signed with the endpoint's secret
+type WebhookEndpoint struct {
+	EndpointID int    `json:"endpoint_id" db:"endpoint_id"`
+	URL        string `json:"url" db:"url"`
+	Secret     string `json:"-" db:"secret"`
+	// Event types delivered to the endpoint, every type when empty
+	EventTypes pq.StringArray `json:"event_types" db:"event_types"`
+	CreatedBy  string         `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+	DisabledAt *time.Time     `json:"disabled_at,omitempty" db:"disabled_at"`
+}
+
+type CreateWebhookEndpointInput struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types,omitempty"`
+}
+
+// Returned once when an endpoint is registered, carrying the signing secret
+type CreatedWebhookEndpoint struct {
+	WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// The delivery of an outbox event to an endpoint
+type WebhookDelivery struct {
+	DeliveryID    int64      `json:"delivery_id" db:"delivery_id"`
+	EventID       int64      `json:"event_id" db:"event_id"`
+	EventType     string     `json:"event_type" db:"event_type"`
+	EndpointID    int        `json:"endpoint_id" db:"endpoint_id"`
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatus    *int       `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// A delivery claimed by the dispatcher, with the event and where to send it. Attempts includes the claimed one.
+type DueWebhookDelivery struct {
+	OutboxEvent
+	DeliveryID int64  `db:"delivery_id"`
+	EndpointID int    `db:"endpoint_id"`
+	Attempts   int    `db:"attempts"`
+	URL        string `db:"url"`
+	Secret     string `db:"secret"`
+}
+
+// Queues the events in [from_event_id, to_event_id] again for an endpoint, including those already delivered
+type ReplayWebhookEventsInput struct {
+	FromEventID int64 `json:"from_event_id"`
+	// Up to the latest event when zero
+	ToEventID int64 `json:"to_event_id,omitempty"`
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The JSON Schema keywords the request schemas use. Anything else in the document is ignored.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// One type name or a list of them, e.g. ["string", "null"]
+type SchemaType []string
+
+func (schemaType *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*schemaType = SchemaType{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	*schemaType = list
+	return nil
+}
+
+// Checks a value decoded with json.Decoder.UseNumber against the schema, returning a problem per mismatch
+// with the JSON path it was found at
+func (document *Document) Validate(schema *Schema, value any) []string {
+	var problems []string
+	document.validate(schema, value, "body", &problems)
+	return problems
+}
+
+func (document *Document) validate(schema *Schema, value any, path string, problems *[]string) {
+	if schema == nil {
+		return
+	}
+	if schema.Ref != "" {
+		resolved, err := document.resolve(schema.Ref)
+		if err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+			return
+		}
+		schema = resolved
+	}
+	report := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(name string) bool { return hasType(value, name) }) {
+		report("expected %s", joinTypes(schema.Type))
+		return
+	}
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(allowed any) bool { return sameValue(allowed, value) }) {
+		report("must be one of %v", schema.Enum)
+	}
+
+	switch typed := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(typed)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			report("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			report("must be at most %d characters", *schema.MaxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(typed) {
+			report("must match %s", schema.Pattern)
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, typed); err != nil {
+				report("expected an RFC 3339 timestamp")
+			}
+		}
+	case json.Number:
+		number, err := typed.Float64()
+		if err != nil {
+			report("invalid number")
+			return
+		}
+		if schema.Minimum != nil && number < *schema.Minimum {
+			report("must be at least %v", *schema.Minimum)
+		}
+		if schema.ExclusiveMinimum != nil && number <= *schema.ExclusiveMinimum {
+			report("must be greater than %v", *schema.ExclusiveMinimum)
+		}
+	case []any:
+		if schema.MinItems != nil && len(typed) < *schema.MinItems {
+			report("must have at least %d items", *schema.MinItems)
+		}
+		for i, item := range typed {
+			document.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, found := typed[name]; !found {
+				report("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(typed))
+		for name := range typed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, known := schema.Properties[name]
+			if !known {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					report("unknown property %q", name)
+				}
+				continue
+			}
+			document.validate(property, typed[name], path+"."+name, problems)
+		}
+	}
+}
+
+func hasType(value any, name string) bool {
+	switch name {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := number.Int64()
+		return err == nil
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	}
+	return false
+}
+
+// Compares an enum entry from the document, decoded without UseNumber, with a request value
+func sameValue(allowed any, value any) bool {
+	if number, ok := value.(json.Number); ok {
+		float, err := number.Float64()
+		return err == nil && reflect.DeepEqual(allowed, float)
+	}
+	return reflect.DeepEqual(allowed, value)
+}
+
+func joinTypes(types SchemaType) string {
+	return strings.Join(types, " or ")
+}
@@ -0,0 +1,143 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The parts of an OpenAPI 3.1 document the server uses: the operations per path and the schemas they reference
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Operations of a path by lower case HTTP method
+type PathItem map[string]*Operation
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Keeps the operations of a path item, skipping shared keys such as parameters and summary
+func (item *PathItem) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*item = PathItem{}
+	for _, method := range httpMethods {
+		raw, found := fields[method]
+		if !found {
+			continue
+		}
+		var operation Operation
+		if err := json.Unmarshal(raw, &operation); err != nil {
+			return fmt.Errorf("%s: %w", method, err)
+		}
+		(*item)[method] = &operation
+	}
+	return nil
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	RequestBody *RequestBody `json:"requestBody,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Parses an OpenAPI document and checks that every schema reference resolves and every pattern compiles
+func Load(data []byte) (*Document, error) {
+	var document Document
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", document.OpenAPI)
+	}
+
+	var problems []string
+	for name, schema := range document.Components.Schemas {
+		problems = append(problems, document.prepare(schema, "#/components/schemas/"+name)...)
+	}
+	for path, item := range document.Paths {
+		for method, operation := range item {
+			if operation == nil || operation.RequestBody == nil {
+				continue
+			}
+			for contentType, mediaType := range operation.RequestBody.Content {
+				location := fmt.Sprintf("%s %s %s", strings.ToUpper(method), path, contentType)
+				problems = append(problems, document.prepare(mediaType.Schema, location)...)
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid OpenAPI document: %s", strings.Join(problems, "; "))
+	}
+	return &document, nil
+}
+
+// Compiles patterns and checks references below schema, reporting problems with their location
+func (document *Document) prepare(schema *Schema, location string) []string {
+	if schema == nil {
+		return nil
+	}
+	var problems []string
+	if schema.Ref != "" {
+		if _, err := document.resolve(schema.Ref); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", location, err))
+		}
+	}
+	if schema.Pattern != "" && schema.pattern == nil {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: invalid pattern: %v", location, err))
+		}
+		schema.pattern = pattern
+	}
+	for name, property := range schema.Properties {
+		problems = append(problems, document.prepare(property, location+"."+name)...)
+	}
+	problems = append(problems, document.prepare(schema.Items, location+"[]")...)
+	return problems
+}
+
+// Returns the component schema a local reference points to
+func (document *Document) resolve(ref string) (*Schema, error) {
+	name, found := strings.CutPrefix(ref, "#/components/schemas/")
+	if !found {
+		return nil, fmt.Errorf("unsupported reference %q", ref)
+	}
+	schema, found := document.Components.Schemas[name]
+	if !found {
+		return nil, fmt.Errorf("unknown schema %q", name)
+	}
+	return schema, nil
+}
+
+// Returns the operation for a method and a path as written in the document, nil when it is not described
+func (document *Document) Operation(method string, path string) *Operation {
+	return document.Paths[path][strings.ToLower(method)]
+}
+
+var routeVariable = regexp.MustCompile(`\{([^{}:]+):[^{}]*(\{[^{}]*\}[^{}]*)*\}`)
+
+// Turns a gorilla/mux path template into an OpenAPI path by dropping the variable patterns,
+// e.g. /accounts/{account_id:[0-9]+} becomes /accounts/{account_id}
+func PathTemplate(muxTemplate string) string {
+	return routeVariable.ReplaceAllString(muxTemplate, "{$1}")
+}
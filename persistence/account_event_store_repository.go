@@ -0,0 +1,154 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"internal-transfers/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Defines methods to read the account event store and its snapshots, and to project accounts from it
+type AccountEventStoreRepository struct {
+	DB *sqlx.DB
+}
+
+// NewAccountEventStoreRepository creates a new AccountEventStoreRepository
+func NewAccountEventStoreRepository(db *sqlx.DB) *AccountEventStoreRepository {
+	return &AccountEventStoreRepository{DB: db}
+}
+
+// Appends the next event of an account within tx, with the data as JSON. The caller holds the lock on the account's
+// row, so the events of an account are appended one database transaction at a time and their versions have no gaps.
+func appendAccountEvent(ctx context.Context, tx *sqlx.Tx, accountID int, eventType string, data any, createdAt time.Time) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	query := `INSERT INTO account_event_store (account_id, version, event_type, data, created_at)
+	SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4 FROM account_event_store WHERE account_id = $1`
+	if _, err := tx.ExecContext(ctx, query, accountID, eventType, string(encoded), createdAt); err != nil {
+		return fmt.Errorf("failed to save %s event for account %d: %w", eventType, accountID, err)
+	}
+	return nil
+}
+
+// Retrieves up to limit events of an account with a version above afterVersion, up to and including toVersion unless
+// it is zero, in version order
+func (repo *AccountEventStoreRepository) ListEventsWithContext(ctx context.Context, accountID int, afterVersion int64, toVersion int64, limit int) ([]model.StoredAccountEvent, error) {
+	events := []model.StoredAccountEvent{}
+	query := `SELECT event_id, account_id, version, event_type, data, created_at FROM account_event_store
+	WHERE account_id = $1 AND version > $2 AND ($3 = 0 OR version <= $3)
+	ORDER BY version LIMIT $4`
+	if err := repo.DB.SelectContext(ctx, &events, query, accountID, afterVersion, toVersion, limit); err != nil {
+		return nil, fmt.Errorf("error listing account events: %w", err)
+	}
+	return events, nil
+}
+
+// Retrieves the version of an account after its last event recorded at or before asOf, zero when there is none
+func (repo *AccountEventStoreRepository) GetVersionAtWithContext(ctx context.Context, accountID int, asOf time.Time) (int64, error) {
+	var version int64
+	query := `SELECT COALESCE(MAX(version), 0) FROM account_event_store WHERE account_id = $1 AND created_at <= $2`
+	if err := repo.DB.GetContext(ctx, &version, query, accountID, asOf); err != nil {
+		return 0, fmt.Errorf("error getting account version: %w", err)
+	}
+	return version, nil
+}
+
+// Retrieves the IDs of the accounts that have events, in ascending order
+func (repo *AccountEventStoreRepository) ListAccountIDsWithContext(ctx context.Context) ([]int, error) {
+	accountIDs := []int{}
+	query := `SELECT DISTINCT account_id FROM account_event_store ORDER BY account_id`
+	if err := repo.DB.SelectContext(ctx, &accountIDs, query); err != nil {
+		return nil, fmt.Errorf("error listing accounts in the event store: %w", err)
+	}
+	return accountIDs, nil
+}
+
+// Retrieves the latest snapshot of an account at or below maxVersion (any version when zero), nil when there is none
+func (repo *AccountEventStoreRepository) GetLatestSnapshotWithContext(ctx context.Context, accountID int, maxVersion int64) (*model.AccountAggregateSnapshot, error) {
+	var snapshot model.AccountAggregateSnapshot
+	query := `SELECT account_id, version, account_type, balance, initial_balance, created_at, taken_at
+	FROM account_aggregate_snapshots
+	WHERE account_id = $1 AND ($2 = 0 OR version <= $2)
+	ORDER BY version DESC LIMIT 1`
+	err := repo.DB.GetContext(ctx, &snapshot, query, accountID, maxVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting account snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Stores a snapshot, keeping the existing one when the account already has a snapshot at that version
+func (repo *AccountEventStoreRepository) SaveSnapshotWithContext(ctx context.Context, snapshot model.AccountAggregateSnapshot) error {
+	query := `INSERT INTO account_aggregate_snapshots (account_id, version, account_type, balance, initial_balance, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (account_id, version) DO NOTHING`
+	_, err := repo.DB.ExecContext(ctx, query, snapshot.AccountID, snapshot.Version, snapshot.AccountType,
+		snapshot.Balance.String(), snapshot.InitialBalance.String(), snapshot.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving account snapshot: %w", err)
+	}
+	return nil
+}
+
+// Retrieves the IDs of the accounts with at least every events recorded since their latest snapshot
+func (repo *AccountEventStoreRepository) ListAccountsDueForSnapshotWithContext(ctx context.Context, every int) ([]int, error) {
+	accountIDs := []int{}
+	query := `SELECT e.account_id FROM (
+		SELECT account_id, MAX(version) AS version FROM account_event_store GROUP BY account_id
+	) e
+	LEFT JOIN (
+		SELECT account_id, MAX(version) AS version FROM account_aggregate_snapshots GROUP BY account_id
+	) s ON s.account_id = e.account_id
+	WHERE e.version - COALESCE(s.version, 0) >= $1
+	ORDER BY e.account_id`
+	if err := repo.DB.SelectContext(ctx, &accountIDs, query, every); err != nil {
+		return nil, fmt.Errorf("error listing accounts due for a snapshot: %w", err)
+	}
+	return accountIDs, nil
+}
+
+// Writes an account replayed up to version to the accounts table, creating its row if missing. The row is locked
+// first and nothing is written when events were appended since the replay; returns false in that case, so the
+// caller replays again.
+func (repo *AccountEventStoreRepository) ProjectAccountWithContext(ctx context.Context, account model.Account, version int64) (bool, error) {
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT account_id FROM accounts WHERE account_id = $1 FOR UPDATE`, account.AccountID); err != nil {
+		return false, fmt.Errorf("error locking account %d: %w", account.AccountID, err)
+	}
+	var current int64
+	query := `SELECT COALESCE(MAX(version), 0) FROM account_event_store WHERE account_id = $1`
+	if err := tx.GetContext(ctx, &current, query, account.AccountID); err != nil {
+		return false, fmt.Errorf("error getting account version: %w", err)
+	}
+	if current != version {
+		return false, nil
+	}
+
+	query = `INSERT INTO accounts (account_id, account_type, balance, initial_balance, created_at) VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (account_id) DO UPDATE SET account_type = EXCLUDED.account_type, balance = EXCLUDED.balance,
+		initial_balance = EXCLUDED.initial_balance, created_at = EXCLUDED.created_at`
+	_, err = tx.ExecContext(ctx, query, account.AccountID, account.AccountType, account.Balance.String(),
+		account.InitialBalance.String(), account.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("error projecting account %d: %w", account.AccountID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return true, nil
+}
@@ -5,9 +5,9 @@ import (
 	"database/sql"
 	"fmt"
 	"internal-transfers/model"
+	"internal-transfers/tracing"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/shopspring/decimal"
 )
 
 // Defines methods to interact with the accounts table in the database
@@ -21,35 +21,62 @@ func NewAccountRepository(db *sqlx.DB) *AccountRepository {
 }
 
 // Retrieves an account by its ID using context with timeout
-func (repo *AccountRepository) GetAccountByIDWithContext(ctx context.Context, accountID int) (*model.Account, error) {
+func (repo *AccountRepository) GetAccountByIDWithContext(ctx context.Context, accountID int) (_ *model.Account, err error) {
+	ctx, span := tracing.Start(ctx, "AccountRepository.GetAccountByID", tracing.AccountID("account.id", accountID))
+	defer tracing.End(span, &err)
+
 	var account model.Account
-	query := `SELECT account_id, balance FROM accounts WHERE account_id = $1`
-	err := repo.DB.GetContext(ctx, &account, query, accountID)
+	query := `SELECT account_id, account_type, balance, initial_balance, created_at FROM accounts WHERE account_id = $1`
+	err = repo.DB.GetContext(ctx, &account, query, accountID)
 	if err != nil {
-		if err == sql.ErrNoRows { 
-			return nil, nil 
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
-		return nil, fmt.Errorf("error getting account by ID: %v", err)
+		return nil, fmt.Errorf("error getting account by ID: %w", err)
 	}
 	return &account, nil
 }
 
-// Creates a new account using context with timeout
-func (repo *AccountRepository) CreateAccountWithContext(ctx context.Context, account model.Account) error {
-	query := `INSERT INTO accounts (account_id, balance) VALUES ($1, $2)`
-	_, err := repo.DB.ExecContext(ctx, query, account.AccountID, account.Balance.String())
+// Creates a new account with its AccountOpened event and AccountCreated outbox event using context with timeout
+func (repo *AccountRepository) CreateAccountWithContext(ctx context.Context, account model.Account) (err error) {
+	ctx, span := tracing.Start(ctx, "AccountRepository.CreateAccount", tracing.AccountID("account.id", account.AccountID))
+	defer tracing.End(span, &err)
+
+	tx, err := repo.DB.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error creating account: %v", err)
+		return fmt.Errorf("could not begin transaction: %w", err)
 	}
-	return nil
-}
+	defer tx.Rollback()
 
-// Updates the balance of an existing account using context with timeout
-func (repo *AccountRepository) UpdateAccountBalanceWithContext(ctx context.Context, accountID int, newBalance decimal.Decimal) error {
-	query := `UPDATE accounts SET balance = $1 WHERE account_id = $2`
-	_, err := repo.DB.ExecContext(ctx, query, newBalance.String(), accountID)
+	query := `INSERT INTO accounts (account_id, account_type, balance, initial_balance) VALUES ($1, $2, $3, $3) RETURNING created_at`
+	err = tx.QueryRowxContext(ctx, query, account.AccountID, account.AccountType, account.Balance.String()).Scan(&account.CreatedAt)
 	if err != nil {
-		return fmt.Errorf("error updating account balance: %v", err)
+		return fmt.Errorf("error creating account: %w", err)
+	}
+	account.InitialBalance = account.Balance
+	opened := model.AccountOpened{AccountType: account.AccountType, InitialBalance: account.InitialBalance}
+	if err = appendAccountEvent(ctx, tx, account.AccountID, model.AccountOpenedEvent, opened, account.CreatedAt); err != nil {
+		return err
+	}
+	if err = addOutboxEvent(ctx, tx, model.EventAccountCreated, account); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
 	}
 	return nil
 }
+
+// Retrieves all accounts ordered by ID
+func (repo *AccountRepository) ListAccountsWithContext(ctx context.Context) (_ []model.Account, err error) {
+	ctx, span := tracing.Start(ctx, "AccountRepository.ListAccounts")
+	defer tracing.End(span, &err)
+
+	accounts := []model.Account{}
+	query := `SELECT account_id, account_type, balance, initial_balance, created_at FROM accounts ORDER BY account_id`
+	if err := repo.DB.SelectContext(ctx, &accounts, query); err != nil {
+		return nil, fmt.Errorf("error listing accounts: %w", err)
+	}
+	return accounts, nil
+}
@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Defines methods to post and retrieve balance adjustments
+type AdjustmentRepository struct {
+	DB *sqlx.DB
+}
+
+// NewAdjustmentRepository creates a new AdjustmentRepository
+func NewAdjustmentRepository(db *sqlx.DB) *AdjustmentRepository {
+	return &AdjustmentRepository{DB: db}
+}
+
+// Posts the adjustment's transaction and stores the adjustment record and its BalanceAdjusted outbox event in a
+// single database transaction, setting the generated IDs and timestamp on adjustment
+func (repo *AdjustmentRepository) CreateAdjustmentWithContext(ctx context.Context, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) error {
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := createAdjustmentTx(ctx, tx, adjustment, transaction, entries); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Posts the adjustment's transaction and stores the adjustment record and its outbox event within tx
+func createAdjustmentTx(ctx context.Context, tx *sqlx.Tx, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) error {
+	if err := postTransactionTx(ctx, tx, transaction, entries); err != nil {
+		return err
+	}
+
+	adjustment.TransactionID = transaction.TransactionID
+	adjustment.CreatedAt = transaction.CreatedAt
+	query := `INSERT INTO balance_adjustments (transaction_id, account_id, suspense_account_id, amount, reason_code, reference, operator_id, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING adjustment_id`
+	err := tx.QueryRowxContext(ctx, query, adjustment.TransactionID, adjustment.AccountID, adjustment.SuspenseAccountID,
+		adjustment.Amount.String(), adjustment.ReasonCode, adjustment.Reference, adjustment.OperatorID, adjustment.CreatedAt).
+		Scan(&adjustment.AdjustmentID)
+	if err != nil {
+		return fmt.Errorf("error saving balance adjustment: %w", err)
+	}
+	return addOutboxEvent(ctx, tx, model.EventBalanceAdjusted, adjustment)
+}
+
+// Retrieves the adjustments of an account, newest first
+func (repo *AdjustmentRepository) ListAdjustmentsWithContext(ctx context.Context, accountID int) ([]model.Adjustment, error) {
+	adjustments := []model.Adjustment{}
+	query := `SELECT adjustment_id, transaction_id, account_id, suspense_account_id, amount, reason_code, reference, operator_id, created_at
+	FROM balance_adjustments WHERE account_id = $1 ORDER BY adjustment_id DESC`
+	if err := repo.DB.SelectContext(ctx, &adjustments, query, accountID); err != nil {
+		return nil, fmt.Errorf("error listing balance adjustments: %w", err)
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"internal-transfers/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Defines methods to store and look up API keys
+type APIKeyRepository struct {
+	DB *sqlx.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{DB: db}
+}
+
+// Stores a new API key, setting the generated ID and creation time
+func (repo *APIKeyRepository) CreateAPIKeyWithContext(ctx context.Context, apiKey *model.APIKey) error {
+	query := `INSERT INTO api_keys (name, key_prefix, key_hash, roles, account_ids, created_by)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING key_id, created_at`
+	err := repo.DB.QueryRowxContext(ctx, query, apiKey.Name, apiKey.KeyPrefix, apiKey.KeyHash, apiKey.Roles, apiKey.AccountIDs, apiKey.CreatedBy).
+		Scan(&apiKey.KeyID, &apiKey.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating API key: %w", err)
+	}
+	return nil
+}
+
+// Retrieves the API key with the given hash, nil when there is none
+func (repo *APIKeyRepository) GetAPIKeyByHashWithContext(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	var apiKey model.APIKey
+	query := `SELECT key_id, name, key_prefix, key_hash, roles, account_ids, created_by, created_at, revoked_at FROM api_keys WHERE key_hash = $1`
+	err := repo.DB.GetContext(ctx, &apiKey, query, keyHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting API key: %w", err)
+	}
+	return &apiKey, nil
+}
+
+// Retrieves all API keys, including revoked ones
+func (repo *APIKeyRepository) ListAPIKeysWithContext(ctx context.Context) ([]model.APIKey, error) {
+	apiKeys := []model.APIKey{}
+	query := `SELECT key_id, name, key_prefix, key_hash, roles, account_ids, created_by, created_at, revoked_at FROM api_keys ORDER BY key_id`
+	if err := repo.DB.SelectContext(ctx, &apiKeys, query); err != nil {
+		return nil, fmt.Errorf("error listing API keys: %w", err)
+	}
+	return apiKeys, nil
+}
+
+// Revokes an API key, returning false when no active key has the given ID
+func (repo *APIKeyRepository) RevokeAPIKeyWithContext(ctx context.Context, keyID int) (bool, error) {
+	query := `UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE key_id = $1 AND revoked_at IS NULL`
+	result, err := repo.DB.ExecContext(ctx, query, keyID)
+	if err != nil {
+		return false, fmt.Errorf("error revoking API key: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error revoking API key: %w", err)
+	}
+	return rows == 1, nil
+}
@@ -0,0 +1,172 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"internal-transfers/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Defines methods to store operations awaiting approval and move them through their states
+type ApprovalRepository struct {
+	DB *sqlx.DB
+}
+
+// NewApprovalRepository creates a new ApprovalRepository
+func NewApprovalRepository(db *sqlx.DB) *ApprovalRepository {
+	return &ApprovalRepository{DB: db}
+}
+
+const pendingOperationColumns = `operation_id, operation_type, status, amount, payload, requested_by, requested_at, expires_at,
+	decided_by, decided_at, reason, transaction_id`
+
+// Stores a new pending operation, setting the generated ID
+func (repo *ApprovalRepository) CreateOperationWithContext(ctx context.Context, operation *model.PendingOperation) error {
+	query := `INSERT INTO pending_operations (operation_type, status, amount, payload, requested_by, requested_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING operation_id`
+	err := repo.DB.QueryRowxContext(ctx, query, operation.OperationType, operation.Status, operation.Amount.String(), operation.Payload,
+		operation.RequestedBy, operation.RequestedAt, operation.ExpiresAt).Scan(&operation.OperationID)
+	if err != nil {
+		return fmt.Errorf("error saving pending operation: %w", err)
+	}
+	return nil
+}
+
+// Retrieves an operation by its ID, nil when it does not exist
+func (repo *ApprovalRepository) GetOperationWithContext(ctx context.Context, operationID int) (*model.PendingOperation, error) {
+	var operation model.PendingOperation
+	query := `SELECT ` + pendingOperationColumns + ` FROM pending_operations WHERE operation_id = $1`
+	err := repo.DB.GetContext(ctx, &operation, query, operationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting pending operation: %w", err)
+	}
+	return &operation, nil
+}
+
+// Retrieves the most recent operations, optionally only those in the given status
+func (repo *ApprovalRepository) ListOperationsWithContext(ctx context.Context, status string, limit int) ([]model.PendingOperation, error) {
+	operations := []model.PendingOperation{}
+	query := `SELECT ` + pendingOperationColumns + ` FROM pending_operations
+	WHERE ($1 = '' OR status = $1) ORDER BY operation_id DESC LIMIT $2`
+	if err := repo.DB.SelectContext(ctx, &operations, query, status, limit); err != nil {
+		return nil, fmt.Errorf("error listing pending operations: %w", err)
+	}
+	return operations, nil
+}
+
+// Moves a pending, unexpired operation to approved or rejected. Returns false when the operation
+// was not pending any more, so concurrent decisions cannot both succeed.
+func (repo *ApprovalRepository) DecideOperationWithContext(ctx context.Context, operationID int, status string, decidedBy string,
+	reason *string, decidedAt time.Time) (bool, error) {
+	query := `UPDATE pending_operations SET status = $1, decided_by = $2, decided_at = $3, reason = $4
+	WHERE operation_id = $5 AND status = $6 AND expires_at > $3`
+	result, err := repo.DB.ExecContext(ctx, query, status, decidedBy, decidedAt, reason, operationID, model.OperationStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("error updating pending operation: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error updating pending operation: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// Records the outcome of executing an approved operation
+func (repo *ApprovalRepository) CompleteOperationWithContext(ctx context.Context, operationID int, status string, transactionID *int, reason *string) error {
+	query := `UPDATE pending_operations SET status = $1, transaction_id = $2, reason = COALESCE($3, reason)
+	WHERE operation_id = $4 AND status = $5`
+	_, err := repo.DB.ExecContext(ctx, query, status, transactionID, reason, operationID, model.OperationStatusApproved)
+	if err != nil {
+		return fmt.Errorf("error updating pending operation: %w", err)
+	}
+	return nil
+}
+
+// Posts an approved transfer and marks its operation executed in a single database transaction. Returns false, posting
+// nothing, when the operation is no longer approved.
+func (repo *ApprovalRepository) ExecuteTransferWithContext(ctx context.Context, operationID int, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+	return repo.executeOperation(ctx, operationID, func(tx *sqlx.Tx) (int, error) {
+		if err := recordTransactionTx(ctx, tx, transaction, entries); err != nil {
+			return 0, err
+		}
+		return transaction.TransactionID, nil
+	})
+}
+
+// Posts an approved adjustment and marks its operation executed in a single database transaction. Returns false, posting
+// nothing, when the operation is no longer approved.
+func (repo *ApprovalRepository) ExecuteAdjustmentWithContext(ctx context.Context, operationID int, adjustment *model.Adjustment,
+	transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+	return repo.executeOperation(ctx, operationID, func(tx *sqlx.Tx) (int, error) {
+		if err := createAdjustmentTx(ctx, tx, adjustment, transaction, entries); err != nil {
+			return 0, err
+		}
+		return adjustment.TransactionID, nil
+	})
+}
+
+// Locks the approved operation, runs post and records the transaction it posted as the operation's outcome
+func (repo *ApprovalRepository) executeOperation(ctx context.Context, operationID int, post func(tx *sqlx.Tx) (int, error)) (bool, error) {
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	query := `SELECT status FROM pending_operations WHERE operation_id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &status, query, operationID); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("error locking pending operation: %w", err)
+	}
+	if status != model.OperationStatusApproved {
+		return false, nil
+	}
+
+	transactionID, err := post(tx)
+	if err != nil {
+		return false, err
+	}
+	query = `UPDATE pending_operations SET status = $1, transaction_id = $2 WHERE operation_id = $3`
+	if _, err := tx.ExecContext(ctx, query, model.OperationStatusExecuted, transactionID, operationID); err != nil {
+		return false, fmt.Errorf("error updating pending operation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// Marks every operation approved at or before approvedBefore that was never executed as failed and returns them.
+// Executions lock their operation, so one still running holds this update back until it has committed.
+func (repo *ApprovalRepository) FailStaleOperationsWithContext(ctx context.Context, approvedBefore time.Time, reason string) ([]model.PendingOperation, error) {
+	operations := []model.PendingOperation{}
+	query := `UPDATE pending_operations SET status = $1, reason = $2
+	WHERE status = $3 AND decided_at <= $4
+	RETURNING ` + pendingOperationColumns
+	if err := repo.DB.SelectContext(ctx, &operations, query, model.OperationStatusFailed, reason, model.OperationStatusApproved, approvedBefore); err != nil {
+		return nil, fmt.Errorf("error failing stale pending operations: %w", err)
+	}
+	return operations, nil
+}
+
+// Marks every pending operation whose deadline has passed as expired and returns them
+func (repo *ApprovalRepository) ExpireOperationsWithContext(ctx context.Context, now time.Time) ([]model.PendingOperation, error) {
+	operations := []model.PendingOperation{}
+	query := `UPDATE pending_operations SET status = $1
+	WHERE status = $2 AND expires_at <= $3
+	RETURNING ` + pendingOperationColumns
+	if err := repo.DB.SelectContext(ctx, &operations, query, model.OperationStatusExpired, model.OperationStatusPending, now); err != nil {
+		return nil, fmt.Errorf("error expiring pending operations: %w", err)
+	}
+	return operations, nil
+}
@@ -0,0 +1,63 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"internal-transfers/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Defines methods to interact with the account_balance_snapshots table in the database
+type BalanceSnapshotRepository struct {
+	DB *sqlx.DB
+}
+
+// NewBalanceSnapshotRepository creates a new BalanceSnapshotRepository
+func NewBalanceSnapshotRepository(db *sqlx.DB) *BalanceSnapshotRepository {
+	return &BalanceSnapshotRepository{DB: db}
+}
+
+// Retrieves the latest snapshot of an account taken for a day before the given date, nil when there is none
+func (repo *BalanceSnapshotRepository) GetLatestSnapshotBeforeWithContext(ctx context.Context, accountID int, before time.Time) (*model.BalanceSnapshot, error) {
+	var snapshot model.BalanceSnapshot
+	query := `SELECT account_id, snapshot_date, balance FROM account_balance_snapshots
+	WHERE account_id = $1 AND snapshot_date < $2
+	ORDER BY snapshot_date DESC LIMIT 1`
+	err := repo.DB.GetContext(ctx, &snapshot, query, accountID, before)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting balance snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Stores snapshots in a single transaction, days already snapshotted are left unchanged
+func (repo *BalanceSnapshotRepository) SaveSnapshotsWithContext(ctx context.Context, snapshots []model.BalanceSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO account_balance_snapshots (account_id, snapshot_date, balance) VALUES ($1, $2, $3)
+	ON CONFLICT (account_id, snapshot_date) DO NOTHING`
+	for _, snapshot := range snapshots {
+		if _, err := tx.ExecContext(ctx, query, snapshot.AccountID, snapshot.SnapshotDate, snapshot.Balance.String()); err != nil {
+			return fmt.Errorf("error saving balance snapshot: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return nil
+}
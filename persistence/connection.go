@@ -1,45 +1,89 @@
 package persistence
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
+	"internal-transfers/config"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-// Establishes DB connection
-func ConnectToDB() (*sqlx.DB, error) {
-	err := godotenv.Load("../../.env")
+// Longest wait for a single ping while the database comes up
+const connectPingTimeout = 5 * time.Second
+
+// Establishes DB connection, retrying with exponential backoff while the database is unreachable, and applies
+// the pool settings. Every statement run with a context is traced as a child of the span in that context.
+func ConnectToDB(dbConfig config.DBConfig) (*sqlx.DB, error) {
+	sqlDB, err := otelsql.Open("postgres", ConnectionString(dbConfig),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{DisableErrSkip: true, OmitConnResetSession: true, OmitRows: true}))
 	if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
+		return nil, fmt.Errorf("failed to connect to the database: %w", err)
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
+
+	db.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
 
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
+	backoff := dbConfig.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), connectPingTimeout)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			break
+		}
+		if attempt >= dbConfig.ConnectAttempts {
+			db.Close()
+			return nil, fmt.Errorf("failed to ping the database after %d attempts: %w", attempt, err)
+		}
 
-	if dbUser == "" || dbPassword == "" || dbName == "" || dbHost == "" || dbPort == "" {
-		log.Fatalf("One or more environment variables are missing (DB_USER, DB_PASSWORD, DB_NAME, DB_HOST, DB_PORT)")
+		slog.Warn("database not reachable, retrying", "attempt", attempt, "max_attempts", dbConfig.ConnectAttempts,
+			"backoff_ms", backoff.Milliseconds(), "error", err)
+		time.Sleep(backoff)
+		backoff = min(2*backoff, dbConfig.ConnectMaxBackoff)
 	}
 
-	connStr := fmt.Sprintf("user=%s password=%s dbname=%s host=%s port=%s sslmode=disable", dbUser, dbPassword, dbName, dbHost, dbPort)
+	slog.Info("connected to the database")
 
-	db, err := sqlx.Open("postgres", connStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to the database: %v", err)
-	}
+	return db, nil
+}
 
-	err = db.Ping()
-	if err != nil {
-		return nil, fmt.Errorf("failed to ping the database: %v", err)
+// Returns the configured DSN, or builds a key=value connection string from the individual settings
+func ConnectionString(dbConfig config.DBConfig) string {
+	if dbConfig.DSN != "" {
+		return dbConfig.DSN
 	}
 
-	log.Println("Successfully connected to the database!")
+	parameters := []string{
+		"host=" + quoteParameter(dbConfig.Host),
+		"port=" + strconv.Itoa(dbConfig.Port),
+		"user=" + quoteParameter(dbConfig.User),
+		"dbname=" + quoteParameter(dbConfig.Name),
+		"sslmode=" + quoteParameter(dbConfig.SSLMode),
+	}
+	if dbConfig.Password != "" {
+		parameters = append(parameters, "password="+quoteParameter(dbConfig.Password))
+	}
+	if dbConfig.SSLRootCert != "" {
+		parameters = append(parameters, "sslrootcert="+quoteParameter(dbConfig.SSLRootCert))
+	}
+	if dbConfig.SSLCert != "" {
+		parameters = append(parameters, "sslcert="+quoteParameter(dbConfig.SSLCert), "sslkey="+quoteParameter(dbConfig.SSLKey))
+	}
+	return strings.Join(parameters, " ")
+}
 
-	return db, nil
+// Quotes a connection string value so spaces, quotes and backslashes (e.g. in passwords) survive parsing
+func quoteParameter(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
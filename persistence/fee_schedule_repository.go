@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"internal-transfers/model"
+	"internal-transfers/tracing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Defines methods to interact with the fee_schedules table in the database
+type FeeScheduleRepository struct {
+	DB *sqlx.DB
+}
+
+// NewFeeScheduleRepository creates a new FeeScheduleRepository
+func NewFeeScheduleRepository(db *sqlx.DB) *FeeScheduleRepository {
+	return &FeeScheduleRepository{DB: db}
+}
+
+const feeScheduleColumns = `fee_schedule_id, name, source_account_type, destination_account_type, source_account_id,
+	destination_account_id, fee_type, flat_amount, percentage, tiers, min_fee, max_fee, effective_from, created_at`
+
+// Retrieves all fee schedules, most recently effective first
+func (repo *FeeScheduleRepository) ListFeeSchedulesWithContext(ctx context.Context) ([]model.FeeSchedule, error) {
+	schedules := []model.FeeSchedule{}
+	query := `SELECT ` + feeScheduleColumns + ` FROM fee_schedules ORDER BY effective_from DESC, fee_schedule_id DESC`
+	if err := repo.DB.SelectContext(ctx, &schedules, query); err != nil {
+		return nil, fmt.Errorf("error listing fee schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// Retrieves the fee schedules already in effect at the given time, most recently effective first
+func (repo *FeeScheduleRepository) ListEffectiveFeeSchedulesWithContext(ctx context.Context, asOf time.Time) (_ []model.FeeSchedule, err error) {
+	ctx, span := tracing.Start(ctx, "FeeScheduleRepository.ListEffectiveFeeSchedules")
+	defer tracing.End(span, &err)
+
+	schedules := []model.FeeSchedule{}
+	query := `SELECT ` + feeScheduleColumns + ` FROM fee_schedules WHERE effective_from <= $1
+	ORDER BY effective_from DESC, fee_schedule_id DESC`
+	if err := repo.DB.SelectContext(ctx, &schedules, query, asOf); err != nil {
+		return nil, fmt.Errorf("error listing effective fee schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// Retrieves a fee schedule by its ID, returns nil when it does not exist
+func (repo *FeeScheduleRepository) GetFeeScheduleByIDWithContext(ctx context.Context, feeScheduleID int) (*model.FeeSchedule, error) {
+	var schedule model.FeeSchedule
+	query := `SELECT ` + feeScheduleColumns + ` FROM fee_schedules WHERE fee_schedule_id = $1`
+	err := repo.DB.GetContext(ctx, &schedule, query, feeScheduleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting fee schedule by ID: %w", err)
+	}
+	return &schedule, nil
+}
+
+// Creates a new fee schedule and sets its generated ID and creation time
+func (repo *FeeScheduleRepository) CreateFeeScheduleWithContext(ctx context.Context, schedule *model.FeeSchedule) error {
+	query := `INSERT INTO fee_schedules (name, source_account_type, destination_account_type, source_account_id,
+	destination_account_id, fee_type, flat_amount, percentage, tiers, min_fee, max_fee, effective_from)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	RETURNING fee_schedule_id, created_at`
+
+	err := repo.DB.QueryRowxContext(ctx, query, schedule.Name, schedule.SourceAccountType, schedule.DestinationAccountType,
+		schedule.SourceAccountID, schedule.DestinationAccountID, schedule.FeeType, schedule.FlatAmount.String(),
+		schedule.Percentage.String(), schedule.Tiers, schedule.MinFee, schedule.MaxFee, schedule.EffectiveFrom).
+		Scan(&schedule.FeeScheduleID, &schedule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating fee schedule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"internal-transfers/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+// Defines methods to interact with the interest configuration and accrual tables
+type InterestRepository struct {
+	DB *sqlx.DB
+}
+
+// NewInterestRepository creates a new InterestRepository
+func NewInterestRepository(db *sqlx.DB) *InterestRepository {
+	return &InterestRepository{DB: db}
+}
+
+// Retrieves the interest configuration of an account, returns nil when none is set
+func (repo *InterestRepository) GetInterestConfigWithContext(ctx context.Context, accountID int) (*model.InterestConfig, error) {
+	var config model.InterestConfig
+	query := `SELECT account_id, annual_rate, day_count_convention, enabled, start_date, updated_at
+	FROM interest_configs WHERE account_id = $1`
+	err := repo.DB.GetContext(ctx, &config, query, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting interest config: %w", err)
+	}
+	return &config, nil
+}
+
+// Retrieves the configurations of all accounts currently earning or paying interest
+func (repo *InterestRepository) ListEnabledInterestConfigsWithContext(ctx context.Context) ([]model.InterestConfig, error) {
+	configs := []model.InterestConfig{}
+	query := `SELECT account_id, annual_rate, day_count_convention, enabled, start_date, updated_at
+	FROM interest_configs WHERE enabled ORDER BY account_id`
+	if err := repo.DB.SelectContext(ctx, &configs, query); err != nil {
+		return nil, fmt.Errorf("error listing interest configs: %w", err)
+	}
+	return configs, nil
+}
+
+// Creates or replaces the interest configuration of an account
+func (repo *InterestRepository) SaveInterestConfigWithContext(ctx context.Context, config *model.InterestConfig) error {
+	query := `INSERT INTO interest_configs (account_id, annual_rate, day_count_convention, enabled, start_date, updated_at)
+	VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+	ON CONFLICT (account_id) DO UPDATE SET annual_rate = EXCLUDED.annual_rate,
+		day_count_convention = EXCLUDED.day_count_convention, enabled = EXCLUDED.enabled,
+		start_date = EXCLUDED.start_date, updated_at = EXCLUDED.updated_at
+	RETURNING updated_at`
+	err := repo.DB.QueryRowxContext(ctx, query, config.AccountID, config.AnnualRate.String(), config.DayCountConvention,
+		config.Enabled, config.StartDate).Scan(&config.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving interest config: %w", err)
+	}
+	return nil
+}
+
+// Returns the most recent day interest was accrued for, nil when the account has no accruals
+func (repo *InterestRepository) GetLastAccrualDateWithContext(ctx context.Context, accountID int) (*time.Time, error) {
+	var last sql.NullTime
+	query := `SELECT MAX(accrual_date) FROM interest_accruals WHERE account_id = $1`
+	if err := repo.DB.GetContext(ctx, &last, query, accountID); err != nil {
+		return nil, fmt.Errorf("error getting last accrual date: %w", err)
+	}
+	if !last.Valid {
+		return nil, nil
+	}
+	return &last.Time, nil
+}
+
+// Stores a daily accrual. Accruing the same account and day twice is a no-op
+func (repo *InterestRepository) CreateAccrualWithContext(ctx context.Context, accrual model.InterestAccrual) error {
+	query := `INSERT INTO interest_accruals (account_id, accrual_date, closing_balance, annual_rate, day_count_convention, amount)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (account_id, accrual_date) DO NOTHING`
+	_, err := repo.DB.ExecContext(ctx, query, accrual.AccountID, accrual.AccrualDate, accrual.ClosingBalance.String(),
+		accrual.AnnualRate.String(), accrual.DayCountConvention, accrual.Amount.String())
+	if err != nil {
+		return fmt.Errorf("error creating interest accrual: %w", err)
+	}
+	return nil
+}
+
+// Retrieves the accruals of an account within a date range, oldest first
+func (repo *InterestRepository) ListAccrualsWithContext(ctx context.Context, accountID int, from, to time.Time) ([]model.InterestAccrual, error) {
+	accruals := []model.InterestAccrual{}
+	query := `SELECT accrual_id, account_id, accrual_date, closing_balance, annual_rate, day_count_convention, amount,
+		posted_transaction_id, created_at
+	FROM interest_accruals WHERE account_id = $1 AND accrual_date BETWEEN $2 AND $3 ORDER BY accrual_date`
+	if err := repo.DB.SelectContext(ctx, &accruals, query, accountID, from, to); err != nil {
+		return nil, fmt.Errorf("error listing interest accruals: %w", err)
+	}
+	return accruals, nil
+}
+
+// Sums the accruals not yet posted, per account
+func (repo *InterestRepository) ListUnpostedInterestWithContext(ctx context.Context) ([]model.UnpostedInterest, error) {
+	unposted := []model.UnpostedInterest{}
+	query := `SELECT account_id, SUM(amount) AS total, MAX(accrual_id) AS last_accrual_id
+	FROM interest_accruals WHERE posted_transaction_id IS NULL
+	GROUP BY account_id ORDER BY account_id`
+	if err := repo.DB.SelectContext(ctx, &unposted, query); err != nil {
+		return nil, fmt.Errorf("error listing unposted interest: %w", err)
+	}
+	return unposted, nil
+}
+
+// Posts the interest of an account and links the accruals it pays to the transaction in a single database
+// transaction. The unposted accruals up to pending.LastAccrualID are claimed with FOR UPDATE SKIP LOCKED first; when
+// the claimed ones no longer add up to pending.Total, because another run posted or is posting some of them, nothing
+// is written and false is returned.
+func (repo *InterestRepository) PostInterestWithContext(ctx context.Context, pending model.UnpostedInterest, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	claimed := []model.InterestAccrual{}
+	query := `SELECT accrual_id, amount FROM interest_accruals
+	WHERE account_id = $1 AND accrual_id <= $2 AND posted_transaction_id IS NULL
+	ORDER BY accrual_id FOR UPDATE SKIP LOCKED`
+	if err := tx.SelectContext(ctx, &claimed, query, pending.AccountID, pending.LastAccrualID); err != nil {
+		return false, fmt.Errorf("error claiming interest accruals: %w", err)
+	}
+	total := decimal.Zero
+	accrualIDs := make([]int64, len(claimed))
+	for i, accrual := range claimed {
+		total = total.Add(accrual.Amount)
+		accrualIDs[i] = int64(accrual.AccrualID)
+	}
+	if len(claimed) == 0 || !total.Equal(pending.Total) {
+		return false, nil
+	}
+
+	if err := recordTransactionTx(ctx, tx, transaction, entries); err != nil {
+		return false, err
+	}
+	query = `UPDATE interest_accruals SET posted_transaction_id = $1 WHERE accrual_id = ANY($2)`
+	if _, err := tx.ExecContext(ctx, query, transaction.TransactionID, pq.Array(accrualIDs)); err != nil {
+		return false, fmt.Errorf("error marking interest accruals posted: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return true, nil
+}
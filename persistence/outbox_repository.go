@@ -0,0 +1,37 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Defines methods to write domain events to the outbox table
+type OutboxRepository struct {
+	DB *sqlx.DB
+}
+
+// NewOutboxRepository creates a new OutboxRepository
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{DB: db}
+}
+
+// Stores an event that has no database change of its own, e.g. a failed transfer
+func (repo *OutboxRepository) AddEventWithContext(ctx context.Context, eventType string, payload any) error {
+	return addOutboxEvent(ctx, repo.DB, eventType, payload)
+}
+
+// Stores an event with the payload as JSON, through the database transaction of the change it describes if any
+func addOutboxEvent(ctx context.Context, execer sqlx.ExecerContext, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	query := `INSERT INTO outbox_events (event_type, payload) VALUES ($1, $2)`
+	if _, err := execer.ExecContext(ctx, query, eventType, string(data)); err != nil {
+		return fmt.Errorf("failed to save %s event: %w", eventType, err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"internal-transfers/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Defines methods to compute account history totals and store reconciliation results
+type ReconciliationRepository struct {
+	DB *sqlx.DB
+}
+
+// NewReconciliationRepository creates a new ReconciliationRepository
+func NewReconciliationRepository(db *sqlx.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{DB: db}
+}
+
+// Net movement per account implied by the transactions table: destinations receive the amount,
+// sources pay the amount plus fee, and the fee account recorded in the breakdown receives the fee
+const accountReconciliationQuery = `WITH movements AS (
+	SELECT destination_account_id AS account_id, amount FROM transactions
+	UNION ALL
+	SELECT source_account_id, -(amount + fee_amount) FROM transactions
+	UNION ALL
+	SELECT (fee_breakdown->>'fee_account_id')::int, fee_amount FROM transactions
+	WHERE fee_amount > 0 AND fee_breakdown IS NOT NULL
+)
+SELECT a.account_id, a.balance AS stored_balance, a.initial_balance,
+	COALESCE(m.total, 0) AS transaction_total, COALESCE(l.total, 0) AS ledger_total
+FROM accounts a
+LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM movements GROUP BY account_id) m ON m.account_id = a.account_id
+LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM ledger_entries GROUP BY account_id) l ON l.account_id = a.account_id`
+
+// Computes the stored balance and history totals of every account
+func (repo *ReconciliationRepository) ListAccountReconciliationsWithContext(ctx context.Context) ([]model.AccountReconciliation, error) {
+	results := []model.AccountReconciliation{}
+	query := accountReconciliationQuery + ` ORDER BY a.account_id`
+	if err := repo.DB.SelectContext(ctx, &results, query); err != nil {
+		return nil, fmt.Errorf("error computing account reconciliation: %w", err)
+	}
+	return results, nil
+}
+
+// Computes the stored balance and history totals of a single account, nil when it does not exist
+func (repo *ReconciliationRepository) GetAccountReconciliationWithContext(ctx context.Context, accountID int) (*model.AccountReconciliation, error) {
+	var result model.AccountReconciliation
+	query := accountReconciliationQuery + ` WHERE a.account_id = $1`
+	err := repo.DB.GetContext(ctx, &result, query, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error computing account reconciliation: %w", err)
+	}
+	return &result, nil
+}
+
+// Stores a completed run and its mismatches, setting the generated IDs
+func (repo *ReconciliationRepository) SaveRunWithContext(ctx context.Context, run *model.ReconciliationRun) error {
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO reconciliation_runs (started_at, completed_at, accounts_checked, mismatch_count)
+	VALUES ($1, $2, $3, $4) RETURNING run_id`
+	err = tx.QueryRowxContext(ctx, query, run.StartedAt, run.CompletedAt, run.AccountsChecked, run.MismatchCount).Scan(&run.RunID)
+	if err != nil {
+		return fmt.Errorf("error saving reconciliation run: %w", err)
+	}
+
+	query = `INSERT INTO reconciliation_mismatches (run_id, account_id, stored_balance, expected_balance, ledger_balance,
+		difference, details, status)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING mismatch_id`
+	for i := range run.Mismatches {
+		mismatch := &run.Mismatches[i]
+		mismatch.RunID = run.RunID
+		err = tx.QueryRowxContext(ctx, query, mismatch.RunID, mismatch.AccountID, mismatch.StoredBalance.String(),
+			mismatch.ExpectedBalance.String(), mismatch.LedgerBalance.String(), mismatch.Difference.String(),
+			mismatch.Details, mismatch.Status).Scan(&mismatch.MismatchID)
+		if err != nil {
+			return fmt.Errorf("error saving reconciliation mismatch: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Retrieves the most recent runs without their mismatches
+func (repo *ReconciliationRepository) ListRunsWithContext(ctx context.Context, limit int) ([]model.ReconciliationRun, error) {
+	runs := []model.ReconciliationRun{}
+	query := `SELECT run_id, started_at, completed_at, accounts_checked, mismatch_count
+	FROM reconciliation_runs ORDER BY run_id DESC LIMIT $1`
+	if err := repo.DB.SelectContext(ctx, &runs, query, limit); err != nil {
+		return nil, fmt.Errorf("error listing reconciliation runs: %w", err)
+	}
+	return runs, nil
+}
+
+// Retrieves a run with its mismatches, nil when it does not exist
+func (repo *ReconciliationRepository) GetRunWithContext(ctx context.Context, runID int) (*model.ReconciliationRun, error) {
+	var run model.ReconciliationRun
+	query := `SELECT run_id, started_at, completed_at, accounts_checked, mismatch_count
+	FROM reconciliation_runs WHERE run_id = $1`
+	err := repo.DB.GetContext(ctx, &run, query, runID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting reconciliation run: %w", err)
+	}
+
+	run.Mismatches = []model.ReconciliationMismatch{}
+	query = `SELECT mismatch_id, run_id, account_id, stored_balance, expected_balance, ledger_balance, difference, details,
+		status, approved_by, approved_at, correction_transaction_id
+	FROM reconciliation_mismatches WHERE run_id = $1 ORDER BY account_id`
+	if err := repo.DB.SelectContext(ctx, &run.Mismatches, query, runID); err != nil {
+		return nil, fmt.Errorf("error listing reconciliation mismatches: %w", err)
+	}
+	return &run, nil
+}
+
+// Retrieves a mismatch of a run, nil when it does not exist
+func (repo *ReconciliationRepository) GetMismatchWithContext(ctx context.Context, runID int, mismatchID int) (*model.ReconciliationMismatch, error) {
+	var mismatch model.ReconciliationMismatch
+	query := `SELECT mismatch_id, run_id, account_id, stored_balance, expected_balance, ledger_balance, difference, details,
+		status, approved_by, approved_at, correction_transaction_id
+	FROM reconciliation_mismatches WHERE run_id = $1 AND mismatch_id = $2`
+	err := repo.DB.GetContext(ctx, &mismatch, query, runID, mismatchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting reconciliation mismatch: %w", err)
+	}
+	return &mismatch, nil
+}
+
+// Books the correction of an open mismatch and records who approved it in a single database transaction. The
+// mismatch is claimed first by moving it out of the open status; returns false, with nothing written, when it is no
+// longer open because another approval got there first.
+func (repo *ReconciliationRepository) CorrectMismatchWithContext(ctx context.Context, mismatchID int, approvedBy string, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE reconciliation_mismatches SET status = $1, approved_by = $2, approved_at = CURRENT_TIMESTAMP
+	WHERE mismatch_id = $3 AND status = $4`
+	result, err := tx.ExecContext(ctx, query, model.MismatchStatusCorrected, approvedBy, mismatchID, model.MismatchStatusOpen)
+	if err != nil {
+		return false, fmt.Errorf("error updating reconciliation mismatch: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error updating reconciliation mismatch: %w", err)
+	}
+	if rows != 1 {
+		return false, nil
+	}
+
+	if err := postTransactionTx(ctx, tx, transaction, entries); err != nil {
+		return false, err
+	}
+	query = `UPDATE reconciliation_mismatches SET correction_transaction_id = $1 WHERE mismatch_id = $2`
+	if _, err := tx.ExecContext(ctx, query, transaction.TransactionID, mismatchID); err != nil {
+		return false, fmt.Errorf("error updating reconciliation mismatch: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return true, nil
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"internal-transfers/common"
 	"internal-transfers/model"
+	"internal-transfers/tracing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Responsible for interacting with the database for transaction related operations
@@ -23,7 +28,7 @@ func NewTransactionRepository(db *sqlx.DB) *TransactionRepository {
 
 // Retrieves a transaction by its ID
 func (transactionRepository *TransactionRepository) GetTransactionByID(transactionID string) (*model.Transaction, error) {
-	query := `SELECT transaction_id, source_account_id, destination_account_id, amount 
+	query := `SELECT transaction_id, transaction_type, source_account_id, destination_account_id, amount, fee_amount, fee_breakdown, created_at
 			  FROM transactions 
 			  WHERE transaction_id = $1`
 
@@ -34,7 +39,7 @@ func (transactionRepository *TransactionRepository) GetTransactionByID(transacti
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("transaction with ID %s not found", transactionID)
 		}
-		return nil, fmt.Errorf("failed to fetch transaction: %v", err)
+		return nil, fmt.Errorf("failed to fetch transaction: %w", err)
 	}
 
 	return &transaction, nil
@@ -47,7 +52,7 @@ func (transactionRepository *TransactionRepository) SaveTransaction(transaction
 
 	_, err := transactionRepository.DB.Exec(query, transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount.String())
 	if err != nil {
-		return fmt.Errorf("failed to save transaction: %v", err)
+		return fmt.Errorf("failed to save transaction: %w", err)
 	}
 
 	return nil
@@ -57,7 +62,7 @@ func (transactionRepository *TransactionRepository) SaveTransaction(transaction
 func (transactionRepository *TransactionRepository) SaveTransactionWithContext(ctx context.Context, transaction model.Transaction) error {
 	tx, err := transactionRepository.DB.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("could not begin transaction: %v", err)
+		return fmt.Errorf("could not begin transaction: %w", err)
 	}
 
 	defer func() {
@@ -71,12 +76,229 @@ func (transactionRepository *TransactionRepository) SaveTransactionWithContext(c
 
 	_, err = tx.ExecContext(ctx, query, transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount.String())
 	if err != nil {
-		return fmt.Errorf("failed to save transaction: %v", err)
+		return fmt.Errorf("failed to save transaction: %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %v", err)
+		return fmt.Errorf("could not commit transaction: %w", err)
 	}
 
 	return nil
 }
+
+// Records a transaction and applies all of its ledger legs in a single database transaction, together with their
+// account events and a TransferCompleted outbox event for transfers. Debit legs are guarded in SQL so concurrent transfers cannot
+// overdraw an account. On success the generated transaction ID and timestamps are set on transaction and entries.
+func (transactionRepository *TransactionRepository) PostTransactionWithContext(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) (err error) {
+	ctx, span := tracing.Start(ctx, "TransactionRepository.PostTransaction",
+		attribute.String("transaction.type", transaction.TransactionType),
+		attribute.Int("ledger.entries", len(entries)))
+	defer tracing.End(span, &err)
+
+	tx, err := transactionRepository.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := recordTransactionTx(ctx, tx, transaction, entries); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("transaction.id", transaction.TransactionID))
+	return nil
+}
+
+// Posts the transaction within tx like PostTransactionWithContext, including the TransferCompleted outbox event of transfers
+func recordTransactionTx(ctx context.Context, tx *sqlx.Tx, transaction *model.Transaction, entries []model.LedgerEntry) error {
+	if err := postTransactionTx(ctx, tx, transaction, entries); err != nil {
+		return err
+	}
+	if transaction.TransactionType == model.TransactionTypeTransfer {
+		return addOutboxEvent(ctx, tx, model.EventTransferCompleted, transaction)
+	}
+	return nil
+}
+
+// Applies the ledger legs and records the transaction within tx, so callers can store related rows atomically.
+// Each leg is appended to the event store of its account, record-only legs included: they account for a change the
+// stored balance already has.
+func postTransactionTx(ctx context.Context, tx *sqlx.Tx, transaction *model.Transaction, entries []model.LedgerEntry) error {
+	for _, entry := range entries {
+		if entry.RecordOnly {
+			// Lock the row like a balance update would, for the account event appended below
+			if _, err := tx.ExecContext(ctx, `SELECT account_id FROM accounts WHERE account_id = $1 FOR UPDATE`, entry.AccountID); err != nil {
+				return fmt.Errorf("failed to lock account %d: %w", entry.AccountID, err)
+			}
+			continue
+		}
+
+		query := `UPDATE accounts SET balance = balance + $1 WHERE account_id = $2`
+		if !entry.AllowNegative && entry.Amount.IsNegative() {
+			query += ` AND balance + $1 >= 0`
+		}
+
+		result, err := tx.ExecContext(ctx, query, entry.Amount.String(), entry.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to update balance for account %d: %w", entry.AccountID, err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to update balance for account %d: %w", entry.AccountID, err)
+		}
+		if rows == 0 {
+			if entry.Amount.IsNegative() {
+				return common.ErrInsufficientBalance
+			}
+			return fmt.Errorf("account %d not found", entry.AccountID)
+		}
+	}
+
+	query := `INSERT INTO transactions (transaction_type, source_account_id, destination_account_id, amount, fee_amount, fee_breakdown)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING transaction_id, created_at`
+
+	err := tx.QueryRowxContext(ctx, query, transaction.TransactionType, transaction.SourceAccountID, transaction.DestinationAccountID,
+		transaction.Amount.String(), transaction.FeeAmount.String(), transaction.FeeBreakdown).
+		Scan(&transaction.TransactionID, &transaction.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save transaction: %w", err)
+	}
+
+	for i := range entries {
+		entries[i].TransactionID = transaction.TransactionID
+		query := `INSERT INTO ledger_entries (transaction_id, account_id, amount, entry_type, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING entry_id`
+
+		err = tx.QueryRowxContext(ctx, query, entries[i].TransactionID, entries[i].AccountID, entries[i].Amount.String(),
+			entries[i].EntryType, transaction.CreatedAt).Scan(&entries[i].EntryID)
+		if err != nil {
+			return fmt.Errorf("failed to save ledger entry for account %d: %w", entries[i].AccountID, err)
+		}
+		entries[i].CreatedAt = transaction.CreatedAt
+
+		eventType := model.AccountCreditedEvent
+		if entries[i].Amount.IsNegative() {
+			eventType = model.AccountDebitedEvent
+		}
+		change := model.AccountBalanceChange{
+			TransactionID: entries[i].TransactionID,
+			EntryID:       entries[i].EntryID,
+			EntryType:     entries[i].EntryType,
+			Amount:        entries[i].Amount.Abs(),
+		}
+		if err := appendAccountEvent(ctx, tx, entries[i].AccountID, eventType, change, entries[i].CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Retrieves up to limit transactions with an ID above afterID in ascending ID order, only those
+// touching accountID unless it is zero
+func (transactionRepository *TransactionRepository) ListTransactionsWithContext(ctx context.Context, accountID int, afterID int, limit int) ([]model.Transaction, error) {
+	transactions := []model.Transaction{}
+	query := `SELECT transaction_id, transaction_type, source_account_id, destination_account_id, amount, fee_amount, fee_breakdown, created_at
+	FROM transactions
+	WHERE transaction_id > $1 AND ($2 = 0 OR source_account_id = $2 OR destination_account_id = $2)
+	ORDER BY transaction_id
+	LIMIT $3`
+	if err := transactionRepository.DB.SelectContext(ctx, &transactions, query, afterID, accountID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// Retrieves the transactions with the given IDs, skipping those that do not exist (yet)
+func (transactionRepository *TransactionRepository) ListTransactionsByIDWithContext(ctx context.Context, transactionIDs []int) ([]model.Transaction, error) {
+	transactions := []model.Transaction{}
+	ids := make([]int64, len(transactionIDs))
+	for i, id := range transactionIDs {
+		ids[i] = int64(id)
+	}
+	query := `SELECT transaction_id, transaction_type, source_account_id, destination_account_id, amount, fee_amount, fee_breakdown, created_at
+	FROM transactions
+	WHERE transaction_id = ANY($1)
+	ORDER BY transaction_id`
+	if err := transactionRepository.DB.SelectContext(ctx, &transactions, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// Returns the highest transaction ID, zero when there are no transactions
+func (transactionRepository *TransactionRepository) GetLastTransactionIDWithContext(ctx context.Context) (int, error) {
+	var transactionID int
+	query := `SELECT COALESCE(MAX(transaction_id), 0) FROM transactions`
+	if err := transactionRepository.DB.GetContext(ctx, &transactionID, query); err != nil {
+		return 0, fmt.Errorf("failed to get last transaction id: %w", err)
+	}
+	return transactionID, nil
+}
+
+// Sums the ledger movements on an account posted at or after the given time
+func (transactionRepository *TransactionRepository) SumLedgerEntriesSinceWithContext(ctx context.Context, accountID int, since time.Time) (decimal.Decimal, error) {
+	var total decimal.Decimal
+	query := `SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE account_id = $1 AND created_at >= $2`
+	if err := transactionRepository.DB.GetContext(ctx, &total, query, accountID, since); err != nil {
+		return decimal.Zero, fmt.Errorf("failed to sum ledger entries: %w", err)
+	}
+	return total, nil
+}
+
+// Sums the ledger movements on an account posted within [from, to]
+func (transactionRepository *TransactionRepository) SumLedgerEntriesBetweenWithContext(ctx context.Context, accountID int, from, to time.Time) (decimal.Decimal, error) {
+	var total decimal.Decimal
+	query := `SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE account_id = $1 AND created_at >= $2 AND created_at <= $3`
+	if err := transactionRepository.DB.GetContext(ctx, &total, query, accountID, from, to); err != nil {
+		return decimal.Zero, fmt.Errorf("failed to sum ledger entries: %w", err)
+	}
+	return total, nil
+}
+
+// Sums the ledger movements on an account per calendar day within [from, to), days without movements are omitted
+func (transactionRepository *TransactionRepository) SumDailyLedgerEntriesWithContext(ctx context.Context, accountID int, from, to time.Time) (map[time.Time]decimal.Decimal, error) {
+	var rows []struct {
+		Day   time.Time       `db:"day"`
+		Total decimal.Decimal `db:"total"`
+	}
+	query := `SELECT created_at::date AS day, SUM(amount) AS total FROM ledger_entries
+	WHERE account_id = $1 AND created_at >= $2 AND created_at < $3
+	GROUP BY created_at::date`
+	if err := transactionRepository.DB.SelectContext(ctx, &rows, query, accountID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to sum daily ledger entries: %w", err)
+	}
+
+	totals := make(map[time.Time]decimal.Decimal, len(rows))
+	for _, row := range rows {
+		day := row.Day.UTC()
+		totals[time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)] = row.Total
+	}
+	return totals, nil
+}
+
+// Retrieves the ledger movements on an account within [from, to] in posting order, with the other party of each leg
+func (transactionRepository *TransactionRepository) ListStatementLinesWithContext(ctx context.Context, accountID int, from, to time.Time) ([]model.StatementLine, error) {
+	lines := []model.StatementLine{}
+	query := `SELECT le.entry_id, le.transaction_id, le.created_at AS posted_at, t.transaction_type, le.entry_type, le.amount,
+		CASE
+			WHEN le.entry_type = 'fee' AND le.account_id = t.source_account_id THEN COALESCE((t.fee_breakdown->>'fee_account_id')::int, 0)
+			WHEN le.account_id = t.source_account_id THEN t.destination_account_id
+			ELSE t.source_account_id
+		END AS counterparty_account_id
+	FROM ledger_entries le
+	JOIN transactions t ON t.transaction_id = le.transaction_id
+	WHERE le.account_id = $1 AND le.created_at >= $2 AND le.created_at <= $3
+	ORDER BY le.created_at, le.entry_id`
+	if err := transactionRepository.DB.SelectContext(ctx, &lines, query, accountID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to list statement lines: %w", err)
+	}
+	return lines, nil
+}
@@ -0,0 +1,187 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"internal-transfers/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Defines methods to manage webhook endpoints and the deliveries of outbox events to them
+type WebhookRepository struct {
+	DB *sqlx.DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{DB: db}
+}
+
+const webhookDeliveryColumns = `d.delivery_id, d.event_id, e.event_type, d.endpoint_id, d.status, d.attempts, d.next_attempt_at,
+	d.last_status_code, d.last_error, d.delivered_at, d.created_at`
+
+// Stores a new endpoint, setting the generated ID and creation time
+func (repo *WebhookRepository) CreateEndpointWithContext(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	query := `INSERT INTO webhook_endpoints (url, secret, event_types, created_by) VALUES ($1, $2, $3, $4)
+	RETURNING endpoint_id, created_at`
+	err := repo.DB.QueryRowxContext(ctx, query, endpoint.URL, endpoint.Secret, endpoint.EventTypes, endpoint.CreatedBy).
+		Scan(&endpoint.EndpointID, &endpoint.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// Retrieves all endpoints, including disabled ones
+func (repo *WebhookRepository) ListEndpointsWithContext(ctx context.Context) ([]model.WebhookEndpoint, error) {
+	endpoints := []model.WebhookEndpoint{}
+	query := `SELECT endpoint_id, url, secret, event_types, created_by, created_at, disabled_at FROM webhook_endpoints ORDER BY endpoint_id`
+	if err := repo.DB.SelectContext(ctx, &endpoints, query); err != nil {
+		return nil, fmt.Errorf("error listing webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+// Stops deliveries to an endpoint, returning false when no active endpoint has the given ID
+func (repo *WebhookRepository) DisableEndpointWithContext(ctx context.Context, endpointID int) (bool, error) {
+	query := `UPDATE webhook_endpoints SET disabled_at = CURRENT_TIMESTAMP WHERE endpoint_id = $1 AND disabled_at IS NULL`
+	result, err := repo.DB.ExecContext(ctx, query, endpointID)
+	if err != nil {
+		return false, fmt.Errorf("error disabling webhook endpoint: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error disabling webhook endpoint: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// Creates a pending delivery of up to limit undispatched events, oldest first, for every active endpoint subscribed
+// to their type and marks the events dispatched. Returns the number of events dispatched. Concurrent dispatchers
+// skip each other's events.
+func (repo *WebhookRepository) FanOutEventsWithContext(ctx context.Context, limit int) (int, error) {
+	query := `WITH events AS (
+		SELECT event_id, event_type FROM outbox_events WHERE dispatched_at IS NULL
+		ORDER BY event_id LIMIT $1 FOR UPDATE SKIP LOCKED
+	), deliveries AS (
+		INSERT INTO webhook_deliveries (event_id, endpoint_id)
+		SELECT e.event_id, w.endpoint_id FROM events e
+		JOIN webhook_endpoints w ON w.disabled_at IS NULL AND (cardinality(w.event_types) = 0 OR e.event_type = ANY(w.event_types))
+		ON CONFLICT (event_id, endpoint_id) DO NOTHING
+	)
+	UPDATE outbox_events SET dispatched_at = CURRENT_TIMESTAMP WHERE event_id IN (SELECT event_id FROM events)`
+	result, err := repo.DB.ExecContext(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("error dispatching outbox events: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error dispatching outbox events: %w", err)
+	}
+	return int(rows), nil
+}
+
+// Claims up to limit pending deliveries that are due, to active endpoints, counting an attempt and holding them for
+// lease so a dispatcher that dies mid-attempt has them retried once the lease runs out
+func (repo *WebhookRepository) ClaimDueDeliveriesWithContext(ctx context.Context, limit int, lease time.Duration) ([]model.DueWebhookDelivery, error) {
+	deliveries := []model.DueWebhookDelivery{}
+	query := `WITH due AS (
+		SELECT d.delivery_id FROM webhook_deliveries d
+		JOIN webhook_endpoints w ON w.endpoint_id = d.endpoint_id AND w.disabled_at IS NULL
+		WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP
+		ORDER BY d.next_attempt_at, d.delivery_id LIMIT $1 FOR UPDATE OF d SKIP LOCKED
+	), claimed AS (
+		UPDATE webhook_deliveries d SET attempts = d.attempts + 1, next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM due WHERE d.delivery_id = due.delivery_id
+		RETURNING d.delivery_id, d.event_id, d.endpoint_id, d.attempts
+	)
+	SELECT c.delivery_id, c.endpoint_id, c.attempts, e.event_id, e.event_type, e.payload, e.created_at, w.url, w.secret
+	FROM claimed c
+	JOIN outbox_events e ON e.event_id = c.event_id
+	JOIN webhook_endpoints w ON w.endpoint_id = c.endpoint_id
+	ORDER BY c.delivery_id`
+	if err := repo.DB.SelectContext(ctx, &deliveries, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Records a successful attempt
+func (repo *WebhookRepository) MarkDeliveredWithContext(ctx context.Context, deliveryID int64, statusCode int) error {
+	query := `UPDATE webhook_deliveries SET status = 'delivered', last_status_code = $2, last_error = NULL, delivered_at = CURRENT_TIMESTAMP
+	WHERE delivery_id = $1`
+	if _, err := repo.DB.ExecContext(ctx, query, deliveryID, statusCode); err != nil {
+		return fmt.Errorf("error updating webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// Records a failed attempt, retrying after retryAfter or moving the delivery to the dead letters when dead is set.
+// statusCode is nil when no response was received.
+func (repo *WebhookRepository) MarkFailedWithContext(ctx context.Context, deliveryID int64, statusCode *int, message string, retryAfter time.Duration, dead bool) error {
+	status := model.WebhookDeliveryPending
+	if dead {
+		status = model.WebhookDeliveryDead
+	}
+	query := `UPDATE webhook_deliveries SET status = $2, last_status_code = $3, last_error = $4,
+	next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $5)
+	WHERE delivery_id = $1`
+	if _, err := repo.DB.ExecContext(ctx, query, deliveryID, status, statusCode, message, retryAfter.Seconds()); err != nil {
+		return fmt.Errorf("error updating webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// Retrieves up to limit deliveries with the given status (every status when empty), newest first
+func (repo *WebhookRepository) ListDeliveriesWithContext(ctx context.Context, status string, limit int) ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+	query := `SELECT ` + webhookDeliveryColumns + `
+	FROM webhook_deliveries d JOIN outbox_events e ON e.event_id = d.event_id
+	WHERE $1 = '' OR d.status = $1
+	ORDER BY d.delivery_id DESC LIMIT $2`
+	if err := repo.DB.SelectContext(ctx, &deliveries, query, status, limit); err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Makes a delivery pending and due now with its attempts reset, whatever its status. Returns nil when it does not exist.
+func (repo *WebhookRepository) ReplayDeliveryWithContext(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	query := `WITH replayed AS (
+		UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, delivered_at = NULL
+		WHERE delivery_id = $1 RETURNING *
+	)
+	SELECT ` + webhookDeliveryColumns + ` FROM replayed d JOIN outbox_events e ON e.event_id = d.event_id`
+	err := repo.DB.GetContext(ctx, &delivery, query, deliveryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error replaying webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// Queues the events with an ID in [fromEventID, toEventID] (no upper bound when toEventID is zero) of the types an
+// endpoint subscribes to, creating the missing deliveries and making the existing ones pending again. Only events already dispatched are
+// queued, the dispatcher fans out the others. Returns the number of deliveries queued.
+func (repo *WebhookRepository) ReplayEventsWithContext(ctx context.Context, endpointID int, fromEventID int64, toEventID int64) (int, error) {
+	query := `INSERT INTO webhook_deliveries (event_id, endpoint_id)
+	SELECT e.event_id, w.endpoint_id FROM outbox_events e
+	JOIN webhook_endpoints w ON w.endpoint_id = $1 AND (cardinality(w.event_types) = 0 OR e.event_type = ANY(w.event_types))
+	WHERE e.event_id >= $2 AND ($3 = 0 OR e.event_id <= $3) AND e.dispatched_at IS NOT NULL
+	ON CONFLICT (event_id, endpoint_id) DO UPDATE SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, delivered_at = NULL`
+	result, err := repo.DB.ExecContext(ctx, query, endpointID, fromEventID, toEventID)
+	if err != nil {
+		return 0, fmt.Errorf("error replaying webhook events: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error replaying webhook events: %w", err)
+	}
+	return int(rows), nil
+}
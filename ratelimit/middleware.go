@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Response headers describing the limit applied to a request
+const (
+	LimitHeader      = "RateLimit-Limit"
+	RemainingHeader  = "RateLimit-Remaining"
+	ResetHeader      = "RateLimit-Reset"
+	RetryAfterHeader = "Retry-After"
+)
+
+// Largest transfer body read to find the source account
+const maxKeyBodyBytes = 1 << 20
+
+// Rejects requests over the limit of their key with 429 Too Many Requests
+type Limiter struct {
+	Store Store
+	Limit Limit
+	// Prefix separating this limiter's buckets from others in a shared store
+	Name string
+	// Returns the bucket key of a request, requests without a key are not limited
+	Key func(request *http.Request) (string, bool)
+}
+
+// Limits each authenticated principal, falling back to the remote IP for anonymous requests
+func NewClientLimiter(store Store, limit Limit) *Limiter {
+	return &Limiter{Store: store, Limit: limit, Name: "client", Key: clientKey}
+}
+
+// Limits requests by remote IP before they are authenticated, so failed authentication attempts are limited too
+func NewIPLimiter(store Store, limit Limit) *Limiter {
+	return &Limiter{Store: store, Limit: limit, Name: "ip", Key: ipKey}
+}
+
+// Limits transfers by the source account in the request body, regardless of which client sends them
+func NewSourceAccountLimiter(store Store, limit Limit) *Limiter {
+	return &Limiter{Store: store, Limit: limit, Name: "account", Key: sourceAccountKey}
+}
+
+func (limiter *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		key, found := limiter.Key(request)
+		if found && !limiter.Allow(writer, request, key) {
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// Takes a token for the key and sets the rate limit headers. When there is none it writes 429 Too Many Requests
+// and returns false. For handlers that can only tell the key once they have checked the request.
+func (limiter *Limiter) Allow(writer http.ResponseWriter, request *http.Request, key string) bool {
+	decision, err := limiter.Take(request.Context(), key)
+	if err != nil {
+		// An unavailable store should not take the API down with it
+		common.LogErrorWithContext(request.Context(), "rate limit store failed, allowing request", "error", err)
+		return true
+	}
+
+	writer.Header().Set(LimitHeader, strconv.Itoa(decision.Limit))
+	writer.Header().Set(RemainingHeader, strconv.Itoa(decision.Remaining))
+	writer.Header().Set(ResetHeader, strconv.Itoa(ceilSeconds(decision.Reset)))
+	if !decision.Allowed {
+		writer.Header().Set(RetryAfterHeader, strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+		http.Error(writer, fmt.Sprintf("Too many requests for this %s, retry later", limiter.Name), http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+// Takes a token from the bucket of a key, for transports that do not go through Middleware
+func (limiter *Limiter) Take(ctx context.Context, key string) (Decision, error) {
+	return limiter.Store.Take(ctx, limiter.Name+":"+key, limiter.Limit)
+}
+
+func clientKey(request *http.Request) (string, bool) {
+	return ClientKey(request.Context(), request.RemoteAddr), true
+}
+
+func ipKey(request *http.Request) (string, bool) {
+	return IPKey(request.RemoteAddr), true
+}
+
+// Returns the IP limiter's key of a call, the host of its remote address
+func IPKey(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return host
+}
+
+// Returns the client limiter's key of a call: the ID of the principal in ctx, or the host of remoteAddr when anonymous
+func ClientKey(ctx context.Context, remoteAddr string) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil && principal.ID != "" {
+		return principal.ID
+	}
+	return "ip:" + IPKey(remoteAddr)
+}
+
+// Reads the source account from the transfer body and replaces the body so the handler can still decode it.
+// Malformed bodies are not limited here, the handler rejects them.
+func sourceAccountKey(request *http.Request) (string, bool) {
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxKeyBodyBytes))
+	request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), request.Body), request.Body}
+	if err != nil {
+		return "", false
+	}
+
+	var transfer struct {
+		SourceAccountID int `json:"source_account_id"`
+	}
+	if err := json.Unmarshal(body, &transfer); err != nil || transfer.SourceAccountID == 0 {
+		return "", false
+	}
+	return strconv.Itoa(transfer.SourceAccountID), true
+}
+
+// Rounds up to whole seconds as the headers require, so clients never retry too early
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Token bucket settings: buckets hold up to Burst tokens and refill at Rate tokens per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Builds a limit allowing requestsPerMinute on average with bursts of up to burst requests
+func PerMinute(requestsPerMinute int, burst int) Limit {
+	if burst <= 0 {
+		burst = requestsPerMinute
+	}
+	return Limit{Rate: float64(requestsPerMinute) / 60, Burst: burst}
+}
+
+// Reports whether the limit is set, a zero limit disables limiting
+func (limit Limit) Enabled() bool {
+	return limit.Rate > 0 && limit.Burst > 0
+}
+
+// Outcome of taking a token from a bucket
+type Decision struct {
+	Allowed bool
+	// Bucket size, reported as RateLimit-Limit
+	Limit int
+	// Whole tokens left after this request
+	Remaining int
+	// Time until the bucket is full again
+	Reset time.Duration
+	// Time until the next token is available, zero when the request was allowed
+	RetryAfter time.Duration
+}
+
+// Holds token buckets by key. MemoryStore keeps them in process; a shared store (e.g. Redis) can implement
+// the same interface so limits hold across instances.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     Limit
+}
+
+// Keeps token buckets in memory, limits are per process
+type MemoryStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// Refills the key's bucket for the time elapsed since it was last used and takes a token when one is available
+func (store *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Decision, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := store.now()
+	store.prune(now)
+
+	current, found := store.buckets[key]
+	if !found {
+		current = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		store.buckets[key] = current
+	}
+	current.limit = limit
+	current.tokens = math.Min(float64(limit.Burst), current.tokens+now.Sub(current.updatedAt).Seconds()*limit.Rate)
+	current.updatedAt = now
+
+	decision := Decision{Limit: limit.Burst}
+	if current.tokens >= 1 {
+		current.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = secondsToDuration((1 - current.tokens) / limit.Rate)
+	}
+	decision.Remaining = int(current.tokens)
+	decision.Reset = secondsToDuration((float64(limit.Burst) - current.tokens) / limit.Rate)
+	return decision, nil
+}
+
+// Drops buckets that have refilled completely, they behave the same as new ones
+func (store *MemoryStore) prune(now time.Time) {
+	if now.Sub(store.lastPrune) < time.Minute {
+		return
+	}
+	for key, idle := range store.buckets {
+		if idle.tokens+now.Sub(idle.updatedAt).Seconds()*idle.limit.Rate >= float64(idle.limit.Burst) {
+			delete(store.buckets, key)
+		}
+	}
+	store.lastPrune = now
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
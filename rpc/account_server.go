@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	transfersv1 "internal-transfers/proto/transfers/v1"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Account operations used by the server, implemented by service.AccountService
+type AccountOperations interface {
+	GetAccountByID(accountID int) (*model.Account, error)
+	CreateAccount(account model.Account) error
+}
+
+// Serves transfers.v1.AccountService, the gRPC counterpart of controller.AccountController
+type AccountServer struct {
+	transfersv1.UnimplementedAccountServiceServer
+	Service     AccountOperations
+	AuditLogger common.Auditor
+	// Limits reads to accounts the caller is linked to, every account is allowed when nil
+	Authorizer *auth.Authorizer
+}
+
+func NewAccountServer(accountService AccountOperations, auditLogger common.Auditor, authorizer *auth.Authorizer) *AccountServer {
+	return &AccountServer{
+		Service:     accountService,
+		AuditLogger: auditLogger,
+		Authorizer:  authorizer,
+	}
+}
+
+func (accountServer *AccountServer) CreateAccount(ctx context.Context, request *transfersv1.CreateAccountRequest) (*transfersv1.Account, error) {
+	initialBalance, err := decimal.NewFromString(request.InitialBalance)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid initial_balance: %v", err)
+	}
+
+	account := model.NewAccount(int(request.AccountId), initialBalance)
+	if request.AccountType != "" {
+		account.AccountType = request.AccountType
+	}
+	if err := accountServer.Service.CreateAccount(*account); err != nil {
+		return nil, statusFromError(ctx, "Error creating account", err)
+	}
+	if accountServer.AuditLogger != nil {
+		accountServer.AuditLogger.LogActionWithContext(ctx, "CreateAccount", fmt.Sprintf("Account created with ID: %d", account.AccountID))
+	}
+
+	created, err := accountServer.Service.GetAccountByID(account.AccountID)
+	if err != nil {
+		return nil, statusFromError(ctx, "Error fetching account", err)
+	}
+	if created == nil {
+		return nil, status.Errorf(codes.Internal, "account %d was not found after creating it", account.AccountID)
+	}
+	return accountMessage(created), nil
+}
+
+func (accountServer *AccountServer) GetAccount(ctx context.Context, request *transfersv1.GetAccountRequest) (*transfersv1.Account, error) {
+	if err := accountServer.Authorizer.CheckAccountContext(ctx, transport, transfersv1.AccountService_GetAccount_FullMethodName, int(request.AccountId)); err != nil {
+		return nil, statusFromError(ctx, "Error authorizing call", err)
+	}
+
+	account, err := accountServer.Service.GetAccountByID(int(request.AccountId))
+	if err != nil {
+		return nil, statusFromError(ctx, "Error fetching account", err)
+	}
+	if account == nil {
+		return nil, status.Errorf(codes.NotFound, "account with ID %d not found", request.AccountId)
+	}
+	return accountMessage(account), nil
+}
+
+func accountMessage(account *model.Account) *transfersv1.Account {
+	return &transfersv1.Account{
+		AccountId:      int64(account.AccountID),
+		AccountType:    account.AccountType,
+		Balance:        account.Balance.String(),
+		InitialBalance: account.InitialBalance.String(),
+		CreatedAt:      timestamppb.New(account.CreatedAt),
+	}
+}
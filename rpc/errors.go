@@ -0,0 +1,58 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"internal-transfers/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Status codes of the transfer error classes, see service.TransferErrorClass. Posting failures and
+// unclassified errors are Internal.
+var transferErrorCodes = map[string]codes.Code{
+	"invalid_amount":     codes.InvalidArgument,
+	"account_not_found":  codes.NotFound,
+	"insufficient_funds": codes.FailedPrecondition,
+	"timeout":            codes.DeadlineExceeded,
+}
+
+// Converts an error returned by the service package to a gRPC status. Errors without a more specific code are
+// logged and returned as Internal, like writeServerError does for HTTP.
+func statusFromError(ctx context.Context, message string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, isStatus := status.FromError(err); isStatus {
+		return err
+	}
+
+	var validationError *common.ValidationError
+	switch {
+	case errors.As(err, &validationError):
+		return status.Error(codes.InvalidArgument, validationError.Message)
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	case errors.Is(err, auth.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case errors.Is(err, service.ErrFeedLagged):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrTransfersAborted), errors.Is(err, service.ErrFeedClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request cancelled")
+	case errors.Is(err, service.ErrAccountExists):
+		return status.Error(codes.AlreadyExists, "account already exists")
+	}
+
+	if code, found := transferErrorCodes[service.TransferErrorClass(err)]; found {
+		return status.Error(code, err.Error())
+	}
+	method, _ := grpc.Method(ctx)
+	common.LogErrorWithContext(ctx, message, "error", err, "method", method)
+	return status.Error(codes.Internal, message)
+}
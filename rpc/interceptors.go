@@ -0,0 +1,366 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"internal-transfers/metrics"
+	transfersv1 "internal-transfers/proto/transfers/v1"
+	"internal-transfers/ratelimit"
+	"internal-transfers/tracing"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Transport name recorded in authorization audit records
+const transport = "gRPC"
+
+// Metadata keys, the lower case forms of the HTTP headers with the same meaning
+var (
+	requestIDKey     = strings.ToLower(common.RequestIDHeader)
+	apiKeyKey        = strings.ToLower(auth.APIKeyHeader)
+	operatorIDKey    = strings.ToLower(auth.OperatorIDHeader)
+	authorizationKey = "authorization"
+
+	signatureClientKey    = strings.ToLower(auth.SignatureClientHeader)
+	signatureTimestampKey = strings.ToLower(auth.SignatureTimestampHeader)
+	signatureNonceKey     = strings.ToLower(auth.SignatureNonceHeader)
+	signatureKey          = strings.ToLower(auth.SignatureHeader)
+)
+
+// HTTP method signed for gRPC calls, which are HTTP/2 POST requests
+const signedMethod = "POST"
+
+// Permission required by each method, methods not listed (reflection) only require authentication
+var methodPermissions = map[string]string{
+	"/transfers.v1.AccountService/CreateAccount":       auth.PermissionAccountsCreate,
+	"/transfers.v1.AccountService/GetAccount":          auth.PermissionAccountsRead,
+	"/transfers.v1.TransferService/PerformTransfer":    auth.PermissionTransfersCreate,
+	"/transfers.v1.TransferService/ListTransactions":   auth.PermissionAccountsRead,
+	"/transfers.v1.TransferService/StreamTransactions": auth.PermissionAccountsRead,
+}
+
+// Methods checked for an HMAC signature, the counterparts of the signed REST routes, with the body each one signs.
+// The body is a canonical list of the request's fields rather than its protobuf encoding, which differs between
+// protobuf implementations, so clients in any language can compute it from the values they send.
+var signedMethods = map[string]func(request any) ([]byte, bool){
+	"/transfers.v1.TransferService/PerformTransfer": signedTransferBody,
+}
+
+// Signed body of a transfer: source_account_id, destination_account_id and amount, as sent, joined by newlines
+func signedTransferBody(request any) ([]byte, bool) {
+	transfer, ok := request.(*transfersv1.PerformTransferRequest)
+	if !ok {
+		return nil, false
+	}
+	return []byte(fmt.Sprintf("%d\n%d\n%s", transfer.SourceAccountId, transfer.DestinationAccountId, transfer.Amount)), true
+}
+
+// Gives every call what the HTTP middleware chain gives a request: a request ID, a server span, authentication,
+// the method's permission check, rate limits, request signatures, metrics and an access log line
+type Interceptor struct {
+	// Authenticates the credentials in the metadata. When nil, authentication is disabled and every call runs
+	// as an admin named by the x-operator-id metadata, like auth.AnonymousMiddleware.
+	Authenticator *auth.Authenticator
+	Authorizer    *auth.Authorizer
+	AuditLogger   common.Auditor
+	// Checks the signature of transfer calls when set. The signature covers POST, the full method name and the
+	// canonical field list of the request (see signedMethods), sent as the x-signature-* metadata.
+	SignatureVerifier *auth.SignatureVerifier
+	// Limits every call by remote IP before authentication when set
+	IPLimiter *ratelimit.Limiter
+	// Limits every call by principal when set, sharing buckets with the REST limiter of the same store
+	ClientLimiter *ratelimit.Limiter
+	// Limits transfers by source account when set, once the caller's access to the account is checked
+	AccountLimiter *ratelimit.Limiter
+}
+
+func NewInterceptor(authenticator *auth.Authenticator, authorizer *auth.Authorizer, auditLogger common.Auditor) *Interceptor {
+	return &Interceptor{
+		Authenticator: authenticator,
+		Authorizer:    authorizer,
+		AuditLogger:   auditLogger,
+	}
+}
+
+// Intercepts unary calls, registered with grpc.UnaryInterceptor
+func (interceptor *Interceptor) Unary(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, finish := interceptor.begin(ctx, info.FullMethod)
+	err := interceptor.limitIP(ctx)
+	if err == nil {
+		ctx, err = interceptor.authorize(ctx, info.FullMethod)
+	}
+	if err == nil {
+		err = interceptor.limitClient(ctx)
+	}
+	if err == nil {
+		err = interceptor.verifySignature(ctx, info.FullMethod, request)
+	}
+	if err == nil {
+		err = interceptor.limitAccount(ctx, info.FullMethod, request)
+	}
+	var response any
+	if err == nil {
+		response, err = handler(ctx, request)
+	}
+	finish(err)
+	return response, err
+}
+
+// Intercepts streaming calls, registered with grpc.StreamInterceptor
+func (interceptor *Interceptor) Stream(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, finish := interceptor.begin(stream.Context(), info.FullMethod)
+	err := interceptor.limitIP(ctx)
+	if err == nil {
+		ctx, err = interceptor.authorize(ctx, info.FullMethod)
+	}
+	if err == nil {
+		err = interceptor.limitClient(ctx)
+	}
+	if err == nil {
+		err = handler(server, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+	finish(err)
+	return err
+}
+
+// Sets up the request ID and the server span of a call, returning the context for the handler and a function
+// recording the outcome once the call ends
+func (interceptor *Interceptor) begin(ctx context.Context, method string) (context.Context, func(error)) {
+	started := time.Now()
+	incoming, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := common.NormalizeRequestID(firstValue(incoming, requestIDKey))
+	ctx = common.WithRequestID(ctx, requestID)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(incoming))
+	ctx, span := tracing.StartServer(ctx, strings.TrimPrefix(method, "/"),
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.method", method))
+
+	return ctx, func(err error) {
+		code := status.Code(err)
+		span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+		level := slog.LevelInfo
+		if isServerError(code) {
+			span.SetStatus(otelcodes.Error, code.String())
+			level = slog.LevelError
+		}
+		span.End()
+
+		duration := time.Since(started)
+		metrics.GRPCRequests.Inc(method, code.String())
+		metrics.GRPCRequestDuration.Observe(duration.Seconds(), method, code.String())
+
+		slog.Log(ctx, level, "rpc",
+			"method", method,
+			"code", code.String(),
+			"duration_ms", float64(duration.Microseconds())/1000,
+			"remote_addr", remoteAddress(ctx),
+			"user_agent", firstValue(incoming, "user-agent"))
+	}
+}
+
+// Codes reported as failures of the server rather than of the call
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+// Authenticates the call and checks the method's permission, returning the context carrying the principal
+func (interceptor *Interceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	principal, err := interceptor.authenticate(ctx, method)
+	if err != nil {
+		return ctx, err
+	}
+	ctx = auth.WithPrincipal(ctx, principal)
+	if permission, found := methodPermissions[method]; found {
+		if err := interceptor.Authorizer.CheckPermissionContext(ctx, transport, method, permission); err != nil {
+			return ctx, statusFromError(ctx, "Error authorizing call", err)
+		}
+	}
+	return ctx, nil
+}
+
+// Returns the principal for the credentials in the metadata or the verified client certificate of the connection
+func (interceptor *Interceptor) authenticate(ctx context.Context, method string) (*auth.Principal, error) {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	if interceptor.Authenticator == nil {
+		return &auth.Principal{ID: firstValue(incoming, operatorIDKey), Name: "anonymous", Method: auth.MethodNone, Roles: []string{auth.RoleAdmin}}, nil
+	}
+
+	var connection *tls.ConnectionState
+	client, _ := peer.FromContext(ctx)
+	if client != nil {
+		if tlsInfo, ok := client.AuthInfo.(credentials.TLSInfo); ok {
+			connection = &tlsInfo.State
+		}
+	}
+
+	principal, err := interceptor.Authenticator.AuthenticateCredentials(ctx, firstValue(incoming, apiKeyKey), firstValue(incoming, authorizationKey), connection)
+	if errors.Is(err, auth.ErrCredentialLookup) {
+		common.LogErrorWithContext(ctx, err.Error())
+		return nil, status.Error(codes.Unavailable, "authentication is temporarily unavailable")
+	}
+	if err != nil {
+		if interceptor.AuditLogger != nil {
+			remoteAddr := ""
+			if client != nil {
+				remoteAddr = client.Addr.String()
+			}
+			interceptor.AuditLogger.LogActionWithContext(ctx, "Authentication Failed", fmt.Sprintf("Method: %s, Path: %s, Remote Address: %s, Reason: %v",
+				transport, method, remoteAddr, err))
+		}
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	return principal, nil
+}
+
+// Rejects the call when its remote IP is over the IP limit
+func (interceptor *Interceptor) limitIP(ctx context.Context) error {
+	if interceptor.IPLimiter == nil {
+		return nil
+	}
+	return take(ctx, interceptor.IPLimiter, ratelimit.IPKey(remoteAddress(ctx)))
+}
+
+// Rejects the call when its principal is over the client limit
+func (interceptor *Interceptor) limitClient(ctx context.Context) error {
+	if interceptor.ClientLimiter == nil {
+		return nil
+	}
+	return take(ctx, interceptor.ClientLimiter, ratelimit.ClientKey(ctx, remoteAddress(ctx)))
+}
+
+// Rejects a transfer when its source account is over the account limit. Access to the account is checked first, so
+// callers cannot spend the tokens of accounts they are not linked to.
+func (interceptor *Interceptor) limitAccount(ctx context.Context, method string, request any) error {
+	transfer, ok := request.(*transfersv1.PerformTransferRequest)
+	if interceptor.AccountLimiter == nil || !ok || transfer.SourceAccountId == 0 {
+		return nil
+	}
+	if err := interceptor.Authorizer.CheckAccountContext(ctx, transport, method, int(transfer.SourceAccountId)); err != nil {
+		return statusFromError(ctx, "Error authorizing call", err)
+	}
+	return take(ctx, interceptor.AccountLimiter, strconv.FormatInt(transfer.SourceAccountId, 10))
+}
+
+// Takes a token for the key and returns RESOURCE_EXHAUSTED when there is none, with the rate limit headers as
+// metadata. An unavailable store allows the call, like ratelimit.Limiter.Middleware.
+func take(ctx context.Context, limiter *ratelimit.Limiter, key string) error {
+	decision, err := limiter.Take(ctx, key)
+	if err != nil {
+		common.LogErrorWithContext(ctx, "rate limit store failed, allowing call", "error", err)
+		return nil
+	}
+
+	header := metadata.Pairs(
+		strings.ToLower(ratelimit.LimitHeader), strconv.Itoa(decision.Limit),
+		strings.ToLower(ratelimit.RemainingHeader), strconv.Itoa(decision.Remaining),
+		strings.ToLower(ratelimit.ResetHeader), strconv.Itoa(ceilSeconds(decision.Reset)))
+	if !decision.Allowed {
+		header.Set(strings.ToLower(ratelimit.RetryAfterHeader), strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+	}
+	grpc.SetHeader(ctx, header)
+	if !decision.Allowed {
+		return status.Errorf(codes.ResourceExhausted, "too many requests for this %s, retry later", limiter.Name)
+	}
+	return nil
+}
+
+// Rounds up to whole seconds, so clients never retry too early
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
+
+// Checks the signature of calls to signed methods, skipping unsigned calls unless signatures are required
+func (interceptor *Interceptor) verifySignature(ctx context.Context, method string, request any) error {
+	signedBody, found := signedMethods[method]
+	if interceptor.SignatureVerifier == nil || !found {
+		return nil
+	}
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	if firstValue(incoming, signatureKey) == "" && !interceptor.SignatureVerifier.Required {
+		return nil
+	}
+
+	body, ok := signedBody(request)
+	if !ok {
+		return status.Error(codes.Internal, "request cannot be verified")
+	}
+	err := interceptor.SignatureVerifier.VerifySignature(firstValue(incoming, signatureClientKey), firstValue(incoming, signatureTimestampKey),
+		firstValue(incoming, signatureNonceKey), firstValue(incoming, signatureKey), signedMethod, method, body)
+	if err != nil {
+		if interceptor.AuditLogger != nil {
+			interceptor.AuditLogger.LogActionWithContext(ctx, "Request Signature Rejected", fmt.Sprintf("Client: %s, Method: %s, Path: %s, Remote Address: %s, Reason: %v",
+				firstValue(incoming, signatureClientKey), transport, method, remoteAddress(ctx), err))
+		}
+		return status.Errorf(codes.Unauthenticated, "invalid request signature: %v", err)
+	}
+	return nil
+}
+
+// Returns the address of the calling peer, empty when unknown
+func remoteAddress(ctx context.Context) string {
+	if client, found := peer.FromContext(ctx); found {
+		return client.Addr.String()
+	}
+	return ""
+}
+
+// Returns the first value of a metadata key, empty when it is missing
+func firstValue(incoming metadata.MD, key string) string {
+	if values := incoming.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Reads W3C trace context from incoming metadata
+type metadataCarrier metadata.MD
+
+func (carrier metadataCarrier) Get(key string) string {
+	return firstValue(metadata.MD(carrier), key)
+}
+
+func (carrier metadataCarrier) Set(key string, value string) {
+	metadata.MD(carrier).Set(key, value)
+}
+
+func (carrier metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(carrier))
+	for key := range carrier {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Hands the handler of a streaming call the context set up by the interceptor
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *contextStream) Context() context.Context {
+	return stream.ctx
+}
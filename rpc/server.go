@@ -0,0 +1,24 @@
+// Package rpc serves the gRPC API defined in proto/transfers/v1, backed by the same services as the REST API.
+package rpc
+
+import (
+	transfersv1 "internal-transfers/proto/transfers/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Creates a gRPC server with AccountService and TransferService registered behind the interceptor, and the
+// reflection service when reflect is set. options carry the transport credentials.
+func NewServer(interceptor *Interceptor, accounts *AccountServer, transfers *TransferServer, reflect bool, options ...grpc.ServerOption) *grpc.Server {
+	options = append(options,
+		grpc.ChainUnaryInterceptor(interceptor.Unary),
+		grpc.ChainStreamInterceptor(interceptor.Stream))
+	server := grpc.NewServer(options...)
+	transfersv1.RegisterAccountServiceServer(server, accounts)
+	transfersv1.RegisterTransferServiceServer(server, transfers)
+	if reflect {
+		reflection.Register(server)
+	}
+	return server
+}
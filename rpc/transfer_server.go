@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"context"
+	"internal-transfers/auth"
+	"internal-transfers/model"
+	transfersv1 "internal-transfers/proto/transfers/v1"
+	"internal-transfers/service"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Serves transfers.v1.TransferService, the gRPC counterpart of controller.TransactionController
+type TransferServer struct {
+	transfersv1.UnimplementedTransferServiceServer
+	Service *service.TransactionService
+	// Holds transfers above the approval threshold, transfers post immediately when nil
+	Approvals *service.ApprovalService
+	// Limits transfers and reads to accounts the caller is linked to, every account is allowed when nil
+	Authorizer *auth.Authorizer
+}
+
+func NewTransferServer(transactionService *service.TransactionService, approvalService *service.ApprovalService, authorizer *auth.Authorizer) *TransferServer {
+	return &TransferServer{
+		Service:    transactionService,
+		Approvals:  approvalService,
+		Authorizer: authorizer,
+	}
+}
+
+func (transferServer *TransferServer) PerformTransfer(ctx context.Context, request *transfersv1.PerformTransferRequest) (*transfersv1.PerformTransferResponse, error) {
+	amount, err := decimal.NewFromString(request.Amount)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid amount: %v", err)
+	}
+	if err := transferServer.Authorizer.CheckAccountContext(ctx, transport, transfersv1.TransferService_PerformTransfer_FullMethodName, int(request.SourceAccountId)); err != nil {
+		return nil, statusFromError(ctx, "Error authorizing call", err)
+	}
+
+	if transferServer.Approvals != nil && transferServer.Approvals.RequiresApproval(amount) {
+		operation, err := transferServer.Approvals.SubmitTransfer(ctx, model.TransactionRequest{
+			SourceAccountID:      int(request.SourceAccountId),
+			DestinationAccountID: int(request.DestinationAccountId),
+			Amount:               amount,
+		}, operatorID(ctx))
+		if err != nil {
+			return nil, statusFromError(ctx, "Error submitting transaction", err)
+		}
+		return &transfersv1.PerformTransferResponse{
+			Result: &transfersv1.PerformTransferResponse_PendingOperationId{PendingOperationId: int64(operation.OperationID)},
+		}, nil
+	}
+
+	posted, err := transferServer.Service.PerformTransactionWithContext(ctx, model.Transaction{
+		SourceAccountID:      int(request.SourceAccountId),
+		DestinationAccountID: int(request.DestinationAccountId),
+		Amount:               amount,
+	})
+	if err != nil {
+		return nil, statusFromError(ctx, "Error processing transaction", err)
+	}
+	return &transfersv1.PerformTransferResponse{
+		Result: &transfersv1.PerformTransferResponse_Transaction{Transaction: transactionMessage(*posted)},
+	}, nil
+}
+
+func (transferServer *TransferServer) ListTransactions(ctx context.Context, request *transfersv1.ListTransactionsRequest) (*transfersv1.ListTransactionsResponse, error) {
+	if err := transferServer.Authorizer.CheckAccountContext(ctx, transport, transfersv1.TransferService_ListTransactions_FullMethodName, int(request.AccountId)); err != nil {
+		return nil, statusFromError(ctx, "Error authorizing call", err)
+	}
+	if request.PageSize < 0 || request.AfterTransactionId < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size and after_transaction_id must not be negative")
+	}
+
+	pageSize := int(request.PageSize)
+	if pageSize == 0 {
+		pageSize = service.DefaultTransactionPageSize
+	}
+	pageSize = min(pageSize, service.MaxTransactionPageSize)
+	transactions, err := transferServer.Service.ListTransactions(ctx, int(request.AccountId), int(request.AfterTransactionId), pageSize)
+	if err != nil {
+		return nil, statusFromError(ctx, "Error listing transactions", err)
+	}
+
+	response := &transfersv1.ListTransactionsResponse{Transactions: make([]*transfersv1.Transaction, 0, len(transactions))}
+	for _, transaction := range transactions {
+		response.Transactions = append(response.Transactions, transactionMessage(transaction))
+	}
+	if len(transactions) == pageSize {
+		response.NextAfterTransactionId = int64(transactions[len(transactions)-1].TransactionID)
+	}
+	return response, nil
+}
+
+func (transferServer *TransferServer) StreamTransactions(request *transfersv1.StreamTransactionsRequest, stream grpc.ServerStreamingServer[transfersv1.Transaction]) error {
+	ctx := stream.Context()
+	if err := transferServer.Authorizer.CheckAccountContext(ctx, transport, transfersv1.TransferService_StreamTransactions_FullMethodName, int(request.AccountId)); err != nil {
+		return statusFromError(ctx, "Error authorizing call", err)
+	}
+	if request.AfterTransactionId < 0 {
+		return status.Error(codes.InvalidArgument, "after_transaction_id must not be negative")
+	}
+
+	err := transferServer.Service.StreamTransactions(ctx, int(request.AccountId), int(request.AfterTransactionId), func(transaction model.Transaction) error {
+		return stream.Send(transactionMessage(transaction))
+	}, nil)
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return statusFromError(ctx, "Error streaming transactions", err)
+}
+
+// The caller recorded on operations held for approval, the principal's ID or the x-operator-id metadata
+func operatorID(ctx context.Context) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		return principal.ID
+	}
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	return firstValue(incoming, operatorIDKey)
+}
+
+func transactionMessage(transaction model.Transaction) *transfersv1.Transaction {
+	return &transfersv1.Transaction{
+		TransactionId:        int64(transaction.TransactionID),
+		TransactionType:      transaction.TransactionType,
+		SourceAccountId:      int64(transaction.SourceAccountID),
+		DestinationAccountId: int64(transaction.DestinationAccountID),
+		Amount:               transaction.Amount.String(),
+		FeeAmount:            transaction.FeeAmount.String(),
+		CreatedAt:            timestamppb.New(transaction.CreatedAt),
+	}
+}
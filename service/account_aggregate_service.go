@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"time"
+)
+
+// Page sizes of ListAccountEvents
+const (
+	DefaultAccountEventPageSize = 100
+	MaxAccountEventPageSize     = 1000
+)
+
+// Events read per query while replaying an account
+const replayBatchSize = 1000
+
+// Times an account is replayed again when transfers keep appending to it while its row is rebuilt
+const projectionAttempts = 5
+
+// Responsible for accounts as event-sourced aggregates: replaying them from the event store, snapshotting them and
+// regenerating the accounts table, which is a projection of the store
+type AccountAggregateService struct {
+	Repo        AccountEventStoreRepository
+	AccountRepo AccountRepository
+	AuditLogger *common.AuditLogger
+	// Events recorded since an account's latest snapshot before a new one is taken
+	SnapshotEvery int
+}
+
+func NewAccountAggregateService(eventStoreRepo AccountEventStoreRepository, accountRepo AccountRepository, auditLogger *common.AuditLogger,
+	snapshotEvery int) *AccountAggregateService {
+	return &AccountAggregateService{
+		Repo:          eventStoreRepo,
+		AccountRepo:   accountRepo,
+		AuditLogger:   auditLogger,
+		SnapshotEvery: snapshotEvery,
+	}
+}
+
+// Rebuilds an account as it was at a version, or after the last event recorded at or before asOf, or currently when
+// neither is given. The replay starts from the latest snapshot at or below that version. Returns nil when the
+// account has no events.
+func (aggregateService *AccountAggregateService) LoadAccount(accountID int, version int64, asOf *time.Time) (*model.AccountAggregate, error) {
+	if version < 0 {
+		return nil, &common.ValidationError{Message: "version must be a positive number"}
+	}
+	if version != 0 && asOf != nil {
+		return nil, &common.ValidationError{Message: "give either version or as_of, not both"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	if asOf != nil {
+		var err error
+		if version, err = aggregateService.Repo.GetVersionAtWithContext(ctx, accountID, asOf.UTC()); err != nil {
+			return nil, err
+		}
+		if version == 0 {
+			first, err := aggregateService.Repo.ListEventsWithContext(ctx, accountID, 0, 0, 1)
+			if err != nil || len(first) == 0 {
+				return nil, err
+			}
+			return nil, &common.ValidationError{Message: fmt.Sprintf("account %d did not exist at %s", accountID, asOf.Format(time.RFC3339))}
+		}
+	}
+
+	aggregate, err := aggregateService.replay(ctx, accountID, version, true)
+	if err != nil || aggregate == nil {
+		return nil, err
+	}
+	if version != 0 && aggregate.Version < version {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("account %d is at version %d, it has no version %d", accountID, aggregate.Version, version)}
+	}
+	return aggregate, nil
+}
+
+// Retrieves a page of an account's events with a version above afterVersion. limit defaults to
+// DefaultAccountEventPageSize and is capped at MaxAccountEventPageSize.
+func (aggregateService *AccountAggregateService) ListAccountEvents(accountID int, afterVersion int64, limit int) ([]model.StoredAccountEvent, error) {
+	if afterVersion < 0 {
+		return nil, &common.ValidationError{Message: "after_version must not be negative"}
+	}
+	if limit <= 0 {
+		limit = DefaultAccountEventPageSize
+	}
+	limit = min(limit, MaxAccountEventPageSize)
+
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return aggregateService.Repo.ListEventsWithContext(ctx, accountID, afterVersion, 0, limit)
+}
+
+// Snapshots every account with at least SnapshotEvery events recorded since its latest snapshot
+func (aggregateService *AccountAggregateService) SnapshotAccounts(ctx context.Context) error {
+	accountIDs, err := aggregateService.Repo.ListAccountsDueForSnapshotWithContext(ctx, aggregateService.SnapshotEvery)
+	if err != nil {
+		return err
+	}
+
+	for _, accountID := range accountIDs {
+		aggregate, err := aggregateService.replay(ctx, accountID, 0, true)
+		if err != nil {
+			return fmt.Errorf("replaying account %d: %w", accountID, err)
+		}
+		if aggregate == nil {
+			continue
+		}
+		snapshot := model.AccountAggregateSnapshot{Account: aggregate.Account, Version: aggregate.Version}
+		if err := aggregateService.Repo.SaveSnapshotWithContext(ctx, snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Regenerates the accounts table from the event store: every account is replayed from its first event, snapshots
+// are not used, and its row is rewritten when it differs. A dry run only reports the differences. Accounts with a
+// row but no events are reported and left as they are. Safe to run while transfers are posted; a dry run may then
+// report a transfer that committed between the replay and the read of the row.
+func (aggregateService *AccountAggregateService) RebuildProjection(ctx context.Context, dryRun bool) (*model.ProjectionRebuild, error) {
+	rebuild := &model.ProjectionRebuild{DryRun: dryRun, Differences: []model.ProjectionDifference{}, AccountsWithoutEvents: []int{}}
+
+	accountIDs, err := aggregateService.Repo.ListAccountIDsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replayed := make(map[int]bool, len(accountIDs))
+	for _, accountID := range accountIDs {
+		difference, updated, err := aggregateService.rebuildAccount(ctx, accountID, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("rebuilding account %d: %w", accountID, err)
+		}
+		replayed[accountID] = true
+		rebuild.AccountsReplayed++
+		if difference != nil {
+			rebuild.Differences = append(rebuild.Differences, *difference)
+		}
+		if updated {
+			rebuild.AccountsUpdated++
+		}
+	}
+
+	accounts, err := aggregateService.AccountRepo.ListAccountsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		if !replayed[account.AccountID] {
+			rebuild.AccountsWithoutEvents = append(rebuild.AccountsWithoutEvents, account.AccountID)
+		}
+	}
+
+	if !dryRun && aggregateService.AuditLogger != nil {
+		aggregateService.AuditLogger.LogActionWithContext(ctx, "Account Projection Rebuilt", fmt.Sprintf("Accounts Replayed: %d, Accounts Updated: %d, Accounts Without Events: %d",
+			rebuild.AccountsReplayed, rebuild.AccountsUpdated, len(rebuild.AccountsWithoutEvents)))
+	}
+	return rebuild, nil
+}
+
+// Replays an account and compares it with its row, rewriting the row unless dryRun. Replays again when events were
+// appended before the row could be written.
+func (aggregateService *AccountAggregateService) rebuildAccount(ctx context.Context, accountID int, dryRun bool) (*model.ProjectionDifference, bool, error) {
+	for attempt := 1; ; attempt++ {
+		aggregate, err := aggregateService.replay(ctx, accountID, 0, false)
+		if err != nil {
+			return nil, false, err
+		}
+		stored, err := aggregateService.AccountRepo.GetAccountByIDWithContext(ctx, accountID)
+		if err != nil {
+			return nil, false, err
+		}
+		if stored != nil && sameAccount(*stored, aggregate.Account) {
+			return nil, false, nil
+		}
+
+		difference := &model.ProjectionDifference{AccountID: accountID, Stored: stored, Replayed: aggregate.Account}
+		if dryRun {
+			return difference, false, nil
+		}
+		projected, err := aggregateService.Repo.ProjectAccountWithContext(ctx, aggregate.Account, aggregate.Version)
+		if err != nil {
+			return nil, false, err
+		}
+		if projected {
+			return difference, true, nil
+		}
+		if attempt == projectionAttempts {
+			return nil, false, fmt.Errorf("events kept being appended during %d attempts", projectionAttempts)
+		}
+	}
+}
+
+// Replays an account's events up to toVersion (every event when zero), from its latest snapshot at or below it when
+// fromSnapshot is set. Returns nil when the account has no events.
+func (aggregateService *AccountAggregateService) replay(ctx context.Context, accountID int, toVersion int64, fromSnapshot bool) (*model.AccountAggregate, error) {
+	aggregate := &model.AccountAggregate{}
+	if fromSnapshot {
+		snapshot, err := aggregateService.Repo.GetLatestSnapshotWithContext(ctx, accountID, toVersion)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != nil {
+			aggregate.Account = snapshot.Account
+			aggregate.Version = snapshot.Version
+			aggregate.SnapshotVersion = snapshot.Version
+		}
+	}
+
+	for {
+		events, err := aggregateService.Repo.ListEventsWithContext(ctx, accountID, aggregate.Version, toVersion, replayBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			if err := aggregate.Apply(event); err != nil {
+				return nil, err
+			}
+		}
+		if len(events) < replayBatchSize {
+			break
+		}
+	}
+
+	if aggregate.Version == 0 {
+		return nil, nil
+	}
+	return aggregate, nil
+}
+
+func sameAccount(stored model.Account, replayed model.Account) bool {
+	return stored.AccountType == replayed.AccountType && stored.Balance.Equal(replayed.Balance) &&
+		stored.InitialBalance.Equal(replayed.InitialBalance) && stored.CreatedAt.Equal(replayed.CreatedAt)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/model"
+
+	"github.com/shopspring/decimal"
+)
+
+// Turns the transaction feed into the events of a single account, for dashboards following it
+// over Server-Sent Events or WebSocket
+type AccountEventService struct {
+	AccountRepo        AccountRepository
+	TransactionService *TransactionService
+}
+
+func NewAccountEventService(accountRepo AccountRepository, transactionService *TransactionService) *AccountEventService {
+	return &AccountEventService{
+		AccountRepo:        accountRepo,
+		TransactionService: transactionService,
+	}
+}
+
+// Sends a transaction event for every transaction touching the account, replaying those after lastEventID first
+// when it is not zero, and a balance event after the replay and after every new transaction. Runs until ctx ends,
+// send fails or the feed drops the subscription, see TransactionService.StreamTransactions.
+func (accountEventService *AccountEventService) StreamAccountEvents(ctx context.Context, accountID int, lastEventID int,
+	send func(model.AccountEvent) error) error {
+	sendBalance := func() error {
+		lookupCtx, cancel := context.WithTimeout(ctx, OperationTimeout)
+		defer cancel()
+		account, err := accountEventService.AccountRepo.GetAccountByIDWithContext(lookupCtx, accountID)
+		if err != nil {
+			return fmt.Errorf("error getting account by ID: %w", err)
+		}
+		if account == nil {
+			return fmt.Errorf("account %d not found", accountID)
+		}
+		return send(model.AccountEvent{Type: model.AccountEventBalance, AccountID: accountID, Balance: &account.Balance})
+	}
+
+	replaying := true
+	return accountEventService.TransactionService.StreamTransactions(ctx, accountID, lastEventID, func(transaction model.Transaction) error {
+		change := balanceChange(transaction, accountID)
+		event := model.AccountEvent{
+			Type:          model.AccountEventTransaction,
+			EventID:       transaction.TransactionID,
+			AccountID:     accountID,
+			Transaction:   &transaction,
+			BalanceChange: &change,
+		}
+		if err := send(event); err != nil {
+			return err
+		}
+		if replaying {
+			return nil
+		}
+		return sendBalance()
+	}, func() error {
+		replaying = false
+		return sendBalance()
+	})
+}
+
+// Signed change of an account's balance by a transaction: the amount for the destination, the amount and
+// the fee taken from the source
+func balanceChange(transaction model.Transaction, accountID int) decimal.Decimal {
+	change := decimal.Zero
+	if transaction.DestinationAccountID == accountID {
+		change = change.Add(transaction.Amount)
+	}
+	if transaction.SourceAccountID == accountID {
+		change = change.Sub(transaction.Amount).Sub(transaction.FeeAmount)
+	}
+	return change
+}
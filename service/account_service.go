@@ -1,41 +1,54 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"internal-transfers/common"
+	"internal-transfers/metrics"
 	"internal-transfers/model"
-	"internal-transfers/persistence"
 	"time"
 
 	"context"
-
-	"github.com/shopspring/decimal"
 )
 
+// Returned by CreateAccount when an account with the same ID exists
+var ErrAccountExists = errors.New("account already exists")
+
 type AccountService struct {
-	Repo        *persistence.AccountRepository
+	Repo        AccountRepository
 	AuditLogger *common.AuditLogger
 }
 
-func NewAccountService(accountRepo *persistence.AccountRepository, auditLogger *common.AuditLogger) *AccountService {
+func NewAccountService(accountRepo AccountRepository, auditLogger *common.AuditLogger) *AccountService {
 	return &AccountService{
 		Repo:        accountRepo,
 		AuditLogger: auditLogger,
 	}
 }
 
-// Creates a new account with retry mechanism for database errors
+// Creates a new account with retry mechanism for database errors. Returns a common.ValidationError when the
+// account is invalid, see ValidateAccount.
 func (accountService *AccountService) CreateAccount(account model.Account) error {
+	if account.AccountType == "" {
+		account.AccountType = model.DefaultAccountType
+	}
+	if err := ValidateAccount(account); err != nil {
+		return err
+	}
+
 	var err error
-	for i := 0; i < 2; i++ {
+	for i := 0; i < RetryAttempts; i++ {
+		if i > 0 {
+			metrics.ServiceRetries.Inc("create_account")
+		}
 		err = accountService.createAccountWithRetry(account)
 		if err == nil {
 			return nil
 		}
 
 		// Retry if it's a database error or timeout
-		if err.Error() == "context deadline exceeded" || err.Error() == "pq: deadlock detected" {
-			time.Sleep(2 * time.Second)
+		if retryable(err) {
+			time.Sleep(RetryDelay)
 			continue
 		}
 		break
@@ -43,24 +56,24 @@ func (accountService *AccountService) CreateAccount(account model.Account) error
 	return err
 }
 
-// createAccount with Retry actually handles the creation with context and timeout context 30 seconds
+// createAccount with Retry actually handles the creation with context and timeout context OperationTimeout
 func (accountService *AccountService) createAccountWithRetry(account model.Account) error {
-	// Set a timeout context (30 seconds)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Set a timeout context (OperationTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
 	defer cancel()
 
 	existingAccount, err := accountService.Repo.GetAccountByIDWithContext(ctx, account.AccountID)
 	if err != nil {
-		return fmt.Errorf("error checking if account exists: %v", err)
+		return fmt.Errorf("error checking if account exists: %w", err)
 	}
 
 	if existingAccount != nil {
-		return fmt.Errorf("account already exists")
+		return ErrAccountExists
 	}
 
 	err = accountService.Repo.CreateAccountWithContext(ctx, account)
 	if err != nil {
-		return fmt.Errorf("error creating account: %v", err)
+		return fmt.Errorf("error creating account: %w", err)
 	}
 
 	return nil
@@ -69,14 +82,18 @@ func (accountService *AccountService) createAccountWithRetry(account model.Accou
 // Retrieves an account by its ID with retry mechanism
 func (accountService *AccountService) GetAccountByID(accountID int) (*model.Account, error) {
 	var err error
-	for i := 0; i < 2; i++ {
-		account, err := accountService.getAccountByIDWithRetry(accountID)
+	for i := 0; i < RetryAttempts; i++ {
+		if i > 0 {
+			metrics.ServiceRetries.Inc("get_account")
+		}
+		var account *model.Account
+		account, err = accountService.getAccountByIDWithRetry(accountID)
 		if err == nil {
 			return account, nil
 		}
 
-		if err.Error() == "context deadline exceeded" || err.Error() == "pq: deadlock detected" {
-			time.Sleep(2 * time.Second)
+		if retryable(err) {
+			time.Sleep(RetryDelay)
 			continue
 		}
 		break
@@ -86,44 +103,13 @@ func (accountService *AccountService) GetAccountByID(accountID int) (*model.Acco
 
 // Handles the retrieval with context and timeout
 func (accountService *AccountService) getAccountByIDWithRetry(accountID int) (*model.Account, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
 	defer cancel()
 
 	account, err := accountService.Repo.GetAccountByIDWithContext(ctx, accountID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting account by ID: %v", err)
+		return nil, fmt.Errorf("error getting account by ID: %w", err)
 	}
 
 	return account, nil
 }
-
-// Updates the balance of an existing account with retry mechanism
-func (accountService *AccountService) UpdateAccountBalance(accountID int, newBalance decimal.Decimal) error {
-	var err error
-	for i := 0; i < 2; i++ {
-		err = accountService.updateAccountBalanceWithRetry(accountID, newBalance)
-		if err == nil {
-			return nil
-		}
-
-		if err.Error() == "context deadline exceeded" || err.Error() == "pq: deadlock detected" {
-			time.Sleep(2 * time.Second)
-			continue
-		}
-		break
-	}
-	return err
-}
-
-// Handles the balance update with context and timeout
-func (accountService *AccountService) updateAccountBalanceWithRetry(accountID int, newBalance decimal.Decimal) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	err := accountService.Repo.UpdateAccountBalanceWithContext(ctx, accountID, newBalance)
-	if err != nil {
-		return fmt.Errorf("error updating account balance: %v", err)
-	}
-
-	return nil
-}
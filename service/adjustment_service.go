@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Longest reference accepted on an adjustment
+const maxAdjustmentReferenceLength = 100
+
+// Responsible for operator initiated balance adjustments. Every adjustment is posted as a transaction
+// against the suspense account, so the ledger stays balanced and the change is attributable.
+type AdjustmentService struct {
+	Repo        AdjustmentRepository
+	AccountRepo AccountRepository
+	AuditLogger *common.AuditLogger
+	// Account adjustments are offset against, adjustments are disabled when zero
+	SuspenseAccountID int
+}
+
+func NewAdjustmentService(adjustmentRepo AdjustmentRepository, accountRepo AccountRepository, auditLogger *common.AuditLogger,
+	suspenseAccountID int) *AdjustmentService {
+	return &AdjustmentService{
+		Repo:              adjustmentRepo,
+		AccountRepo:       accountRepo,
+		AuditLogger:       auditLogger,
+		SuspenseAccountID: suspenseAccountID,
+	}
+}
+
+// Checks an adjustment request before anything is posted
+func ValidateAdjustment(input model.CreateAdjustmentInput) error {
+	if input.Amount.IsZero() {
+		return &common.ValidationError{Message: "amount must be a non-zero change to the balance"}
+	}
+	if !slices.Contains(model.AdjustmentReasonCodes, input.ReasonCode) {
+		return &common.ValidationError{Message: fmt.Sprintf("reason_code must be one of %s", strings.Join(model.AdjustmentReasonCodes, ", "))}
+	}
+	reference := strings.TrimSpace(input.Reference)
+	if reference == "" {
+		return &common.ValidationError{Message: "reference must be provided"}
+	}
+	if len(reference) > maxAdjustmentReferenceLength {
+		return &common.ValidationError{Message: fmt.Sprintf("reference must be at most %d characters", maxAdjustmentReferenceLength)}
+	}
+	if strings.TrimSpace(input.OperatorID) == "" {
+		return &common.ValidationError{Message: "operator must be provided"}
+	}
+	return nil
+}
+
+// Stores an adjustment together with its transaction and ledger legs atomically, setting the generated IDs on them
+type AdjustmentPoster func(ctx context.Context, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) error
+
+// Adjusts an account's balance by the signed amount, offsetting it against the suspense account.
+// Returns nil when the account does not exist.
+func (adjustmentService *AdjustmentService) CreateAdjustment(ctx context.Context, accountID int, input model.CreateAdjustmentInput) (*model.Adjustment, error) {
+	return adjustmentService.CreateAdjustmentPostedBy(ctx, accountID, input, nil)
+}
+
+// Creates an adjustment like CreateAdjustment, storing it with post instead of Repo so the caller can record its
+// own changes in the same database transaction
+func (adjustmentService *AdjustmentService) CreateAdjustmentPostedBy(ctx context.Context, accountID int, input model.CreateAdjustmentInput, post AdjustmentPoster) (*model.Adjustment, error) {
+	if adjustmentService.SuspenseAccountID == 0 {
+		return nil, &common.ValidationError{Message: "adjustments require a configured suspense account"}
+	}
+	if accountID == adjustmentService.SuspenseAccountID {
+		return nil, &common.ValidationError{Message: "the suspense account cannot be adjusted against itself"}
+	}
+	if err := ValidateAdjustment(input); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	account, err := adjustmentService.AccountRepo.GetAccountByIDWithContext(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting account by ID: %w", err)
+	}
+	if account == nil {
+		return nil, nil
+	}
+	if account.Balance.Add(input.Amount).IsNegative() {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("adjustment would take account %d below zero", accountID)}
+	}
+
+	adjustment := &model.Adjustment{
+		AccountID:         accountID,
+		SuspenseAccountID: adjustmentService.SuspenseAccountID,
+		Amount:            input.Amount,
+		ReasonCode:        input.ReasonCode,
+		Reference:         strings.TrimSpace(input.Reference),
+		OperatorID:        strings.TrimSpace(input.OperatorID),
+	}
+	transaction, entries := adjustmentPosting(accountID, adjustmentService.SuspenseAccountID, input.Amount)
+	if post == nil {
+		post = adjustmentService.Repo.CreateAdjustmentWithContext
+	}
+	if err := post(ctx, adjustment, transaction, entries); err != nil {
+		return nil, fmt.Errorf("failed to post adjustment: %w", err)
+	}
+	if adjustmentService.AuditLogger != nil {
+		adjustmentService.AuditLogger.LogActionWithContext(ctx, "Balance Adjusted", fmt.Sprintf("Adjustment ID: %d, Account ID: %d, Amount: %s, Reason: %s, Reference: %s, Operator: %s, Transaction ID: %d",
+			adjustment.AdjustmentID, accountID, adjustment.Amount.String(), adjustment.ReasonCode, adjustment.Reference, adjustment.OperatorID, adjustment.TransactionID))
+	}
+
+	return adjustment, nil
+}
+
+// Retrieves the adjustments made to an account
+func (adjustmentService *AdjustmentService) ListAdjustments(accountID int) ([]model.Adjustment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return adjustmentService.Repo.ListAdjustmentsWithContext(ctx, accountID)
+}
+
+// Builds the transaction for an adjustment: a credit is paid out of suspense, a debit is paid into it
+func adjustmentPosting(accountID int, suspenseAccountID int, amount decimal.Decimal) (*model.Transaction, []model.LedgerEntry) {
+	transaction := &model.Transaction{
+		TransactionType:      model.TransactionTypeAdjustment,
+		SourceAccountID:      suspenseAccountID,
+		DestinationAccountID: accountID,
+		Amount:               amount.Abs(),
+		FeeAmount:            decimal.Zero,
+	}
+	if amount.IsNegative() {
+		transaction.SourceAccountID, transaction.DestinationAccountID = accountID, suspenseAccountID
+	}
+
+	entries := []model.LedgerEntry{
+		{AccountID: accountID, Amount: amount, EntryType: model.TransactionTypeAdjustment},
+		{AccountID: suspenseAccountID, Amount: amount.Neg(), EntryType: model.TransactionTypeAdjustment, AllowNegative: true},
+	}
+	return transaction, entries
+}
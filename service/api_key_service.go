@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"strings"
+)
+
+// Longest name accepted for an API key
+const maxAPIKeyNameLength = 100
+
+// Responsible for issuing and revoking API keys
+type APIKeyService struct {
+	Repo        APIKeyRepository
+	AuditLogger *common.AuditLogger
+}
+
+func NewAPIKeyService(apiKeyRepo APIKeyRepository, auditLogger *common.AuditLogger) *APIKeyService {
+	return &APIKeyService{
+		Repo:        apiKeyRepo,
+		AuditLogger: auditLogger,
+	}
+}
+
+// Issues a new API key with the given roles and linked accounts. The returned key is the only copy, only its hash is stored.
+func (apiKeyService *APIKeyService) CreateAPIKey(ctx context.Context, input model.CreateAPIKeyInput, createdBy string) (*model.CreatedAPIKey, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, &common.ValidationError{Message: "name must be provided"}
+	}
+	if len(name) > maxAPIKeyNameLength {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("name must be at most %d characters", maxAPIKeyNameLength)}
+	}
+
+	if len(input.Roles) == 0 {
+		return nil, &common.ValidationError{Message: "at least one role must be provided"}
+	}
+	for _, role := range input.Roles {
+		if !auth.ValidRole(role) {
+			return nil, &common.ValidationError{Message: fmt.Sprintf("role must be one of %s", strings.Join(auth.Roles, ", "))}
+		}
+	}
+	accountIDs := make([]int64, 0, len(input.AccountIDs))
+	for _, accountID := range input.AccountIDs {
+		if accountID <= 0 {
+			return nil, &common.ValidationError{Message: "account_ids must be positive account IDs"}
+		}
+		accountIDs = append(accountIDs, int64(accountID))
+	}
+
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	created := &model.CreatedAPIKey{
+		APIKey: model.APIKey{
+			Name:       name,
+			KeyPrefix:  auth.APIKeyDisplayPrefix(key),
+			KeyHash:    auth.HashAPIKey(key),
+			Roles:      input.Roles,
+			AccountIDs: accountIDs,
+			CreatedBy:  createdBy,
+		},
+		Key: key,
+	}
+	if err := apiKeyService.Repo.CreateAPIKeyWithContext(ctx, &created.APIKey); err != nil {
+		return nil, err
+	}
+
+	if apiKeyService.AuditLogger != nil {
+		apiKeyService.AuditLogger.LogActionWithContext(ctx, "API Key Created", fmt.Sprintf("Key ID: %d, Name: %s, Prefix: %s, Roles: %v, Accounts: %v, Created By: %s",
+			created.KeyID, created.Name, created.KeyPrefix, input.Roles, input.AccountIDs, createdBy))
+	}
+	return created, nil
+}
+
+// Retrieves all API keys without their secrets
+func (apiKeyService *APIKeyService) ListAPIKeys() ([]model.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return apiKeyService.Repo.ListAPIKeysWithContext(ctx)
+}
+
+// Revokes an API key, returning false when no active key has the given ID
+func (apiKeyService *APIKeyService) RevokeAPIKey(ctx context.Context, keyID int, revokedBy string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	revoked, err := apiKeyService.Repo.RevokeAPIKeyWithContext(ctx, keyID)
+	if err != nil || !revoked {
+		return false, err
+	}
+
+	if apiKeyService.AuditLogger != nil {
+		apiKeyService.AuditLogger.LogActionWithContext(ctx, "API Key Revoked", fmt.Sprintf("Key ID: %d, Revoked By: %s", keyID, revokedBy))
+	}
+	return true, nil
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Number of operations returned when listing approvals
+const approvalListLimit = 100
+
+// How long an operation may stay approved without being executed before ExpireOperations marks it failed. Well above
+// the longest an execution can run, its retries included.
+const staleApprovalAge = 10 * time.Minute
+
+// Responsible for the maker-checker workflow: high-value transfers and manual adjustments are held
+// as pending operations and only executed once a different operator approves them
+type ApprovalService struct {
+	Repo               ApprovalRepository
+	AccountRepo        AccountRepository
+	TransactionService *TransactionService
+	AdjustmentService  *AdjustmentService
+	AuditLogger        *common.AuditLogger
+	// Transfers above this amount are held for approval, none are held when zero
+	TransferThreshold decimal.Decimal
+	// How long an operation waits for a decision before it expires
+	Timeout time.Duration
+}
+
+func NewApprovalService(approvalRepo ApprovalRepository, accountRepo AccountRepository, transactionService *TransactionService,
+	adjustmentService *AdjustmentService, auditLogger *common.AuditLogger, transferThreshold decimal.Decimal, timeout time.Duration) *ApprovalService {
+	return &ApprovalService{
+		Repo:               approvalRepo,
+		AccountRepo:        accountRepo,
+		TransactionService: transactionService,
+		AdjustmentService:  adjustmentService,
+		AuditLogger:        auditLogger,
+		TransferThreshold:  transferThreshold,
+		Timeout:            timeout,
+	}
+}
+
+// Reports whether a transfer of the given amount must be approved before it is executed
+func (approvalService *ApprovalService) RequiresApproval(amount decimal.Decimal) bool {
+	return approvalService.TransferThreshold.IsPositive() && amount.GreaterThan(approvalService.TransferThreshold)
+}
+
+// Holds a transfer for approval after checking it could be executed
+func (approvalService *ApprovalService) SubmitTransfer(ctx context.Context, request model.TransactionRequest, requestedBy string) (*model.PendingOperation, error) {
+	if err := ValidateTransferAmount(request.Amount); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	for _, accountID := range []int{request.SourceAccountID, request.DestinationAccountID} {
+		if err := approvalService.checkAccountExists(ctx, accountID); err != nil {
+			return nil, err
+		}
+	}
+
+	return approvalService.submit(ctx, model.OperationTypeTransfer, request.Amount, &model.OperationPayload{Transfer: &request}, requestedBy)
+}
+
+// Holds an adjustment for approval after checking it could be executed, the requester is the adjustment's operator
+func (approvalService *ApprovalService) SubmitAdjustment(ctx context.Context, accountID int, input model.CreateAdjustmentInput) (*model.PendingOperation, error) {
+	if approvalService.AdjustmentService.SuspenseAccountID == 0 {
+		return nil, &common.ValidationError{Message: "adjustments require a configured suspense account"}
+	}
+	if accountID == approvalService.AdjustmentService.SuspenseAccountID {
+		return nil, &common.ValidationError{Message: "the suspense account cannot be adjusted against itself"}
+	}
+	if err := ValidateAdjustment(input); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	if err := approvalService.checkAccountExists(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	payload := &model.OperationPayload{Adjustment: &model.AdjustmentRequest{AccountID: accountID, CreateAdjustmentInput: input}}
+	return approvalService.submit(ctx, model.OperationTypeAdjustment, input.Amount, payload, input.OperatorID)
+}
+
+func (approvalService *ApprovalService) checkAccountExists(ctx context.Context, accountID int) error {
+	account, err := approvalService.AccountRepo.GetAccountByIDWithContext(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("error getting account by ID: %w", err)
+	}
+	if account == nil {
+		return &common.ValidationError{Message: fmt.Sprintf("account %d not found", accountID)}
+	}
+	return nil
+}
+
+func (approvalService *ApprovalService) submit(ctx context.Context, operationType string, amount decimal.Decimal,
+	payload *model.OperationPayload, requestedBy string) (*model.PendingOperation, error) {
+	requestedBy = strings.TrimSpace(requestedBy)
+	if requestedBy == "" {
+		return nil, &common.ValidationError{Message: "operations requiring approval must identify the requesting operator"}
+	}
+
+	now := time.Now().UTC()
+	operation := &model.PendingOperation{
+		OperationType: operationType,
+		Status:        model.OperationStatusPending,
+		Amount:        amount,
+		Payload:       payload,
+		RequestedBy:   requestedBy,
+		RequestedAt:   now,
+		ExpiresAt:     now.Add(approvalService.Timeout),
+	}
+	if err := approvalService.Repo.CreateOperationWithContext(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	approvalService.audit(ctx, "Approval Requested", operation, fmt.Sprintf("Requested By: %s, Expires At: %s",
+		requestedBy, operation.ExpiresAt.Format(time.RFC3339)))
+	return operation, nil
+}
+
+// Approves a pending operation and executes it. The approver must differ from the requester.
+// The posting and the executed status are stored together, a failed execution is recorded on the operation
+// rather than returned as an error. Returns nil when the operation does not exist.
+func (approvalService *ApprovalService) Approve(ctx context.Context, operationID int, approvedBy string) (*model.PendingOperation, error) {
+	// Once approved the operation is executed and its outcome recorded even when the caller goes away
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), OperationTimeout)
+	defer cancel()
+
+	operation, err := approvalService.decide(ctx, operationID, model.OperationStatusApproved, approvedBy, nil)
+	if err != nil || operation == nil {
+		return nil, err
+	}
+
+	transactionID, execErr := approvalService.execute(ctx, operationID, *operation.Payload)
+	if execErr != nil {
+		// Left approved when this fails too, until ExpireOperations marks it failed
+		reason := execErr.Error()
+		if err := approvalService.Repo.CompleteOperationWithContext(ctx, operationID, model.OperationStatusFailed, nil, &reason); err != nil {
+			return nil, err
+		}
+	}
+
+	if execErr != nil {
+		approvalService.audit(ctx, "Approved Operation Failed", operation, fmt.Sprintf("Approved By: %s, Error: %v", approvedBy, execErr))
+	} else {
+		approvalService.audit(ctx, "Approved Operation Executed", operation, fmt.Sprintf("Approved By: %s, Transaction ID: %d", approvedBy, *transactionID))
+	}
+
+	return approvalService.Repo.GetOperationWithContext(ctx, operationID)
+}
+
+// Rejects a pending operation. The rejecting operator must differ from the requester.
+// Returns nil when the operation does not exist.
+func (approvalService *ApprovalService) Reject(ctx context.Context, operationID int, rejectedBy string, reason string) (*model.PendingOperation, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, &common.ValidationError{Message: "reason must be provided"}
+	}
+
+	operation, err := approvalService.decide(ctx, operationID, model.OperationStatusRejected, rejectedBy, &reason)
+	if err != nil || operation == nil {
+		return nil, err
+	}
+
+	return approvalService.Repo.GetOperationWithContext(ctx, operationID)
+}
+
+// Moves a pending operation to approved or rejected, enforcing the four-eyes rule and the deadline
+func (approvalService *ApprovalService) decide(ctx context.Context, operationID int, status string, decidedBy string,
+	reason *string) (*model.PendingOperation, error) {
+	decidedBy = strings.TrimSpace(decidedBy)
+	if decidedBy == "" {
+		return nil, &common.ValidationError{Message: "the deciding operator must be identified"}
+	}
+
+	operation, err := approvalService.Repo.GetOperationWithContext(ctx, operationID)
+	if err != nil || operation == nil {
+		return nil, err
+	}
+	if operation.RequestedBy == decidedBy {
+		return nil, &common.ValidationError{Message: "an operation must be approved or rejected by a different operator than the one who requested it"}
+	}
+	if operation.Status != model.OperationStatusPending {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("operation %d is already %s", operationID, operation.Status)}
+	}
+
+	now := time.Now().UTC()
+	if !now.Before(operation.ExpiresAt) {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("operation %d expired at %s", operationID, operation.ExpiresAt.Format(time.RFC3339))}
+	}
+
+	decided, err := approvalService.Repo.DecideOperationWithContext(ctx, operationID, status, decidedBy, reason, now)
+	if err != nil {
+		return nil, err
+	}
+	if !decided {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("operation %d was decided concurrently", operationID)}
+	}
+
+	if status == model.OperationStatusApproved {
+		approvalService.audit(ctx, "Approval Granted", operation, fmt.Sprintf("Approved By: %s", decidedBy))
+	} else {
+		approvalService.audit(ctx, "Approval Rejected", operation, fmt.Sprintf("Rejected By: %s, Reason: %s", decidedBy, *reason))
+	}
+	return operation, nil
+}
+
+// Executes an approved operation, returning the ID of the transaction it posted. The operation is marked executed
+// in the database transaction posting it.
+func (approvalService *ApprovalService) execute(ctx context.Context, operationID int, payload model.OperationPayload) (*int, error) {
+	switch {
+	case payload.Transfer != nil:
+		post := func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			executed, err := approvalService.Repo.ExecuteTransferWithContext(ctx, operationID, transaction, entries)
+			return operationExecuted(operationID, executed, err)
+		}
+		posted, err := approvalService.TransactionService.PerformTransactionPostedBy(ctx, model.Transaction{
+			TransactionType:      model.TransactionTypeTransfer,
+			SourceAccountID:      payload.Transfer.SourceAccountID,
+			DestinationAccountID: payload.Transfer.DestinationAccountID,
+			Amount:               payload.Transfer.Amount,
+		}, post)
+		if err != nil {
+			return nil, err
+		}
+		return &posted.TransactionID, nil
+	case payload.Adjustment != nil:
+		post := func(ctx context.Context, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			executed, err := approvalService.Repo.ExecuteAdjustmentWithContext(ctx, operationID, adjustment, transaction, entries)
+			return operationExecuted(operationID, executed, err)
+		}
+		adjustment, err := approvalService.AdjustmentService.CreateAdjustmentPostedBy(ctx, payload.Adjustment.AccountID, payload.Adjustment.CreateAdjustmentInput, post)
+		if err != nil {
+			return nil, err
+		}
+		if adjustment == nil {
+			return nil, fmt.Errorf("account %d not found", payload.Adjustment.AccountID)
+		}
+		return &adjustment.TransactionID, nil
+	default:
+		return nil, fmt.Errorf("operation has no payload to execute")
+	}
+}
+
+func operationExecuted(operationID int, executed bool, err error) error {
+	if err != nil {
+		return err
+	}
+	if !executed {
+		return fmt.Errorf("operation %d is no longer approved", operationID)
+	}
+	return nil
+}
+
+// Expires every pending operation past its deadline, and fails every operation left approved for longer than
+// staleApprovalAge because its execution was interrupted before an outcome was recorded
+func (approvalService *ApprovalService) ExpireOperations(ctx context.Context) error {
+	now := time.Now().UTC()
+	expired, err := approvalService.Repo.ExpireOperationsWithContext(ctx, now)
+	if err != nil {
+		return err
+	}
+	for i := range expired {
+		approvalService.audit(ctx, "Approval Expired", &expired[i], fmt.Sprintf("Expired At: %s", expired[i].ExpiresAt.Format(time.RFC3339)))
+	}
+
+	stale, err := approvalService.Repo.FailStaleOperationsWithContext(ctx, now.Add(-staleApprovalAge), "execution was interrupted, nothing was posted")
+	if err != nil {
+		return err
+	}
+	for i := range stale {
+		approvalService.audit(ctx, "Approved Operation Failed", &stale[i], "Error: execution was interrupted, nothing was posted")
+	}
+	return nil
+}
+
+// Retrieves the most recent operations, optionally only those in the given status
+func (approvalService *ApprovalService) ListOperations(status string) ([]model.PendingOperation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return approvalService.Repo.ListOperationsWithContext(ctx, status, approvalListLimit)
+}
+
+// Retrieves an operation by its ID, nil when it does not exist
+func (approvalService *ApprovalService) GetOperation(operationID int) (*model.PendingOperation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return approvalService.Repo.GetOperationWithContext(ctx, operationID)
+}
+
+func (approvalService *ApprovalService) audit(ctx context.Context, action string, operation *model.PendingOperation, details string) {
+	if approvalService.AuditLogger != nil {
+		approvalService.AuditLogger.LogActionWithContext(ctx, action, fmt.Sprintf("Operation ID: %d, Type: %s, Amount: %s, %s",
+			operation.OperationID, operation.OperationType, operation.Amount.String(), details))
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"time"
+)
+
+// Upper bound on snapshots written per database transaction when catching up
+const snapshotBatchSize = 500
+
+// Responsible for historical balances and the daily balance snapshots backing them
+type BalanceService struct {
+	AccountRepo     AccountRepository
+	TransactionRepo TransactionRepository
+	SnapshotRepo    BalanceSnapshotRepository
+}
+
+func NewBalanceService(accountRepo AccountRepository, transactionRepo TransactionRepository, snapshotRepo BalanceSnapshotRepository) *BalanceService {
+	return &BalanceService{
+		AccountRepo:     accountRepo,
+		TransactionRepo: transactionRepo,
+		SnapshotRepo:    snapshotRepo,
+	}
+}
+
+// Reconstructs the balance of an account at a point in time from the latest daily snapshot before it,
+// or from the initial balance, plus the ledger movements up to asOf. Returns nil when the account does not exist.
+func (balanceService *BalanceService) GetBalanceAsOf(accountID int, asOf time.Time) (*model.HistoricalBalance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return balanceService.GetBalanceAsOfWithContext(ctx, accountID, asOf)
+}
+
+func (balanceService *BalanceService) GetBalanceAsOfWithContext(ctx context.Context, accountID int, asOf time.Time) (*model.HistoricalBalance, error) {
+	asOf = asOf.UTC()
+
+	account, err := balanceService.AccountRepo.GetAccountByIDWithContext(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting account by ID: %w", err)
+	}
+	if account == nil {
+		return nil, nil
+	}
+	if asOf.Before(account.CreatedAt) {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("account %d did not exist at %s", accountID, asOf.Format(time.RFC3339))}
+	}
+
+	result := &model.HistoricalBalance{AccountID: accountID, AsOf: asOf}
+
+	snapshot, err := balanceService.SnapshotRepo.GetLatestSnapshotBeforeWithContext(ctx, accountID, truncateToDay(asOf))
+	if err != nil {
+		return nil, err
+	}
+
+	from := account.CreatedAt
+	opening := account.InitialBalance
+	if snapshot != nil {
+		snapshotDate := snapshot.SnapshotDate.UTC()
+		from = truncateToDay(snapshotDate).AddDate(0, 0, 1)
+		opening = snapshot.Balance
+		result.SnapshotDate = &snapshotDate
+	}
+
+	movements, err := balanceService.TransactionRepo.SumLedgerEntriesBetweenWithContext(ctx, accountID, from, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Balance = opening.Add(movements)
+	return result, nil
+}
+
+// Records the closing balance of every account for each day not yet snapshotted, up to and including through
+func (balanceService *BalanceService) SnapshotBalances(ctx context.Context, through time.Time) error {
+	through = truncateToDay(through.UTC())
+
+	accounts, err := balanceService.AccountRepo.ListAccountsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		if err := balanceService.snapshotAccount(ctx, account, through); err != nil {
+			return fmt.Errorf("snapshotting account %d: %w", account.AccountID, err)
+		}
+	}
+	return nil
+}
+
+func (balanceService *BalanceService) snapshotAccount(ctx context.Context, account model.Account, through time.Time) error {
+	end := through.AddDate(0, 0, 1)
+
+	last, err := balanceService.SnapshotRepo.GetLatestSnapshotBeforeWithContext(ctx, account.AccountID, end)
+	if err != nil {
+		return err
+	}
+
+	day := truncateToDay(account.CreatedAt.UTC())
+	balance := account.InitialBalance
+	if last != nil {
+		day = truncateToDay(last.SnapshotDate.UTC()).AddDate(0, 0, 1)
+		balance = last.Balance
+	}
+	if day.After(through) {
+		return nil
+	}
+
+	dailyMovements, err := balanceService.TransactionRepo.SumDailyLedgerEntriesWithContext(ctx, account.AccountID, day, end)
+	if err != nil {
+		return err
+	}
+
+	snapshots := make([]model.BalanceSnapshot, 0, snapshotBatchSize)
+	for ; !day.After(through); day = day.AddDate(0, 0, 1) {
+		balance = balance.Add(dailyMovements[day])
+		snapshots = append(snapshots, model.BalanceSnapshot{AccountID: account.AccountID, SnapshotDate: day, Balance: balance})
+
+		if len(snapshots) == snapshotBatchSize {
+			if err := balanceService.SnapshotRepo.SaveSnapshotsWithContext(ctx, snapshots); err != nil {
+				return err
+			}
+			snapshots = snapshots[:0]
+		}
+	}
+	return balanceService.SnapshotRepo.SaveSnapshotsWithContext(ctx, snapshots)
+}
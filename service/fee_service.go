@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Fees are stored with the same precision as balances
+const feePrecision = 5
+
+// Responsible for managing fee schedules and calculating transfer fees
+type FeeService struct {
+	Repo        FeeScheduleRepository
+	AuditLogger *common.AuditLogger
+	// Account credited with collected fees, fees are disabled when zero
+	FeeAccountID int
+}
+
+func NewFeeService(feeScheduleRepo FeeScheduleRepository, auditLogger *common.AuditLogger, feeAccountID int) *FeeService {
+	return &FeeService{
+		Repo:         feeScheduleRepo,
+		AuditLogger:  auditLogger,
+		FeeAccountID: feeAccountID,
+	}
+}
+
+// Calculates the fee for a transfer using the most specific schedule in effect.
+// Returns a nil breakdown when fees are disabled or no schedule matches.
+func (feeService *FeeService) CalculateFee(ctx context.Context, source, destination *model.Account, amount decimal.Decimal) (*model.FeeBreakdown, error) {
+	if feeService.FeeAccountID == 0 {
+		return nil, nil
+	}
+
+	schedules, err := feeService.Repo.ListEffectiveFeeSchedulesWithContext(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error loading fee schedules: %w", err)
+	}
+
+	schedule := SelectFeeSchedule(schedules, source, destination)
+	if schedule == nil {
+		return nil, nil
+	}
+
+	calculated, err := calculateScheduleFee(*schedule, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	charged, capApplied := applyFeeCaps(*schedule, calculated)
+
+	return &model.FeeBreakdown{
+		FeeScheduleID:  schedule.FeeScheduleID,
+		ScheduleName:   schedule.Name,
+		FeeType:        schedule.FeeType,
+		FeeAccountID:   feeService.FeeAccountID,
+		CalculatedFee:  calculated,
+		CapApplied:     capApplied,
+		ChargedFee:     charged,
+		TransferAmount: amount,
+	}, nil
+}
+
+// Picks the schedule that matches the transfer most specifically. Account pair matches outrank
+// account type matches; among equally specific schedules the first one wins, so callers pass
+// schedules ordered by effective date, latest first.
+func SelectFeeSchedule(schedules []model.FeeSchedule, source, destination *model.Account) *model.FeeSchedule {
+	var selected *model.FeeSchedule
+	bestScore := -1
+	for i := range schedules {
+		score, ok := feeScheduleScore(schedules[i], source, destination)
+		if ok && score > bestScore {
+			selected = &schedules[i]
+			bestScore = score
+		}
+	}
+	return selected
+}
+
+func feeScheduleScore(schedule model.FeeSchedule, source, destination *model.Account) (int, bool) {
+	score := 0
+	if schedule.SourceAccountID != nil {
+		if *schedule.SourceAccountID != source.AccountID {
+			return 0, false
+		}
+		score += 4
+	}
+	if schedule.DestinationAccountID != nil {
+		if *schedule.DestinationAccountID != destination.AccountID {
+			return 0, false
+		}
+		score += 4
+	}
+	if schedule.SourceAccountType != "" {
+		if schedule.SourceAccountType != source.AccountType {
+			return 0, false
+		}
+		score++
+	}
+	if schedule.DestinationAccountType != "" {
+		if schedule.DestinationAccountType != destination.AccountType {
+			return 0, false
+		}
+		score++
+	}
+	return score, true
+}
+
+func calculateScheduleFee(schedule model.FeeSchedule, amount decimal.Decimal) (decimal.Decimal, error) {
+	hundred := decimal.NewFromInt(100)
+	switch schedule.FeeType {
+	case model.FeeTypeFlat:
+		return schedule.FlatAmount.Round(feePrecision), nil
+	case model.FeeTypePercentage:
+		return amount.Mul(schedule.Percentage).Div(hundred).Round(feePrecision), nil
+	case model.FeeTypeTiered:
+		tier := selectFeeTier(schedule.Tiers, amount)
+		if tier == nil {
+			return decimal.Zero, fmt.Errorf("fee schedule %d has no tier for amount %s", schedule.FeeScheduleID, amount.String())
+		}
+		return tier.FlatAmount.Add(amount.Mul(tier.Percentage).Div(hundred)).Round(feePrecision), nil
+	default:
+		return decimal.Zero, fmt.Errorf("fee schedule %d has unknown fee type %q", schedule.FeeScheduleID, schedule.FeeType)
+	}
+}
+
+// Returns the lowest tier whose upper bound covers the amount, an unbounded tier covers everything
+func selectFeeTier(tiers model.FeeTiers, amount decimal.Decimal) *model.FeeTier {
+	sorted := make(model.FeeTiers, len(tiers))
+	copy(sorted, tiers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].UpTo.Valid || !sorted[j].UpTo.Valid {
+			return sorted[i].UpTo.Valid
+		}
+		return sorted[i].UpTo.Decimal.LessThan(sorted[j].UpTo.Decimal)
+	})
+
+	for i := range sorted {
+		if !sorted[i].UpTo.Valid || amount.LessThanOrEqual(sorted[i].UpTo.Decimal) {
+			return &sorted[i]
+		}
+	}
+	return nil
+}
+
+func applyFeeCaps(schedule model.FeeSchedule, fee decimal.Decimal) (decimal.Decimal, string) {
+	if schedule.MinFee.Valid && fee.LessThan(schedule.MinFee.Decimal) {
+		return schedule.MinFee.Decimal, "min"
+	}
+	if schedule.MaxFee.Valid && fee.GreaterThan(schedule.MaxFee.Decimal) {
+		return schedule.MaxFee.Decimal, "max"
+	}
+	return fee, ""
+}
+
+// Validates and stores a new fee schedule. Schedules are never edited; a new schedule with a later
+// effective_from supersedes the previous one.
+func (feeService *FeeService) CreateFeeSchedule(ctx context.Context, input model.CreateFeeScheduleInput) (*model.FeeSchedule, error) {
+	schedule := model.FeeSchedule{
+		Name:                   strings.TrimSpace(input.Name),
+		SourceAccountType:      input.SourceAccountType,
+		DestinationAccountType: input.DestinationAccountType,
+		SourceAccountID:        input.SourceAccountID,
+		DestinationAccountID:   input.DestinationAccountID,
+		FeeType:                input.FeeType,
+		FlatAmount:             input.FlatAmount,
+		Percentage:             input.Percentage,
+		Tiers:                  input.Tiers,
+		MinFee:                 input.MinFee,
+		MaxFee:                 input.MaxFee,
+		EffectiveFrom:          time.Now(),
+	}
+	if input.EffectiveFrom != nil {
+		schedule.EffectiveFrom = *input.EffectiveFrom
+	}
+
+	if err := ValidateFeeSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	if err := feeService.Repo.CreateFeeScheduleWithContext(ctx, &schedule); err != nil {
+		return nil, fmt.Errorf("error creating fee schedule: %w", err)
+	}
+
+	if feeService.AuditLogger != nil {
+		feeService.AuditLogger.LogActionWithContext(ctx, "Fee Schedule Created", fmt.Sprintf("Fee Schedule ID: %d, Name: %s, Type: %s, Effective From: %s",
+			schedule.FeeScheduleID, schedule.Name, schedule.FeeType, schedule.EffectiveFrom.Format(time.RFC3339)))
+	}
+
+	return &schedule, nil
+}
+
+// Checks a fee schedule is complete and internally consistent
+func ValidateFeeSchedule(schedule model.FeeSchedule) error {
+	if schedule.Name == "" {
+		return &common.ValidationError{Message: "fee schedule name must be provided"}
+	}
+
+	switch schedule.FeeType {
+	case model.FeeTypeFlat:
+		if schedule.FlatAmount.IsNegative() {
+			return &common.ValidationError{Message: "flat_amount must not be negative"}
+		}
+	case model.FeeTypePercentage:
+		if schedule.Percentage.IsNegative() {
+			return &common.ValidationError{Message: "percentage must not be negative"}
+		}
+	case model.FeeTypeTiered:
+		if len(schedule.Tiers) == 0 {
+			return &common.ValidationError{Message: "tiered fee schedules need at least one tier"}
+		}
+		unbounded := 0
+		for _, tier := range schedule.Tiers {
+			if tier.FlatAmount.IsNegative() || tier.Percentage.IsNegative() {
+				return &common.ValidationError{Message: "tier amounts must not be negative"}
+			}
+			if !tier.UpTo.Valid {
+				unbounded++
+			}
+		}
+		if unbounded > 1 {
+			return &common.ValidationError{Message: "only one tier may omit up_to"}
+		}
+	default:
+		return &common.ValidationError{Message: fmt.Sprintf("fee_type must be one of %s, %s, %s", model.FeeTypeFlat, model.FeeTypePercentage, model.FeeTypeTiered)}
+	}
+
+	if schedule.MinFee.Valid && schedule.MinFee.Decimal.IsNegative() {
+		return &common.ValidationError{Message: "min_fee must not be negative"}
+	}
+	if schedule.MinFee.Valid && schedule.MaxFee.Valid && schedule.MinFee.Decimal.GreaterThan(schedule.MaxFee.Decimal) {
+		return &common.ValidationError{Message: "min_fee must not exceed max_fee"}
+	}
+
+	return nil
+}
+
+// Retrieves all fee schedules, including superseded and future ones
+func (feeService *FeeService) ListFeeSchedules() ([]model.FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return feeService.Repo.ListFeeSchedulesWithContext(ctx)
+}
+
+// Retrieves a fee schedule by its ID, returns nil when it does not exist
+func (feeService *FeeService) GetFeeScheduleByID(feeScheduleID int) (*model.FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return feeService.Repo.GetFeeScheduleByIDWithContext(ctx, feeScheduleID)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"internal-transfers/model"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reported by the draining check once shutdown has begun
+var ErrDraining = errors.New("server is shutting down")
+
+// A dependency the service needs to serve requests
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Runs the readiness checks. Readiness fails once draining starts, so load balancers stop routing new requests
+// while in-flight ones finish.
+type HealthService struct {
+	Checks []HealthCheck
+	// Deadline of each check
+	Timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewHealthService(timeout time.Duration) *HealthService {
+	return &HealthService{Timeout: timeout}
+}
+
+// Adds a readiness check
+func (healthService *HealthService) Register(name string, check func(ctx context.Context) error) {
+	healthService.Checks = append(healthService.Checks, HealthCheck{Name: name, Check: check})
+}
+
+// Marks the service as shutting down, failing readiness from now on
+func (healthService *HealthService) StartDraining() {
+	healthService.draining.Store(true)
+}
+
+func (healthService *HealthService) Draining() bool {
+	return healthService.draining.Load()
+}
+
+// Runs every check concurrently, each with its own timeout, and reports the result per dependency
+func (healthService *HealthService) Readiness(ctx context.Context) model.HealthReport {
+	report := model.HealthReport{Status: model.HealthStatusOK, Checks: map[string]model.HealthCheckResult{}}
+	var mutex sync.Mutex
+	record := func(name string, duration time.Duration, err error) {
+		result := model.HealthCheckResult{Status: model.HealthStatusOK, DurationMs: float64(duration.Microseconds()) / 1000}
+		if err != nil {
+			result.Status = model.HealthStatusFailing
+			result.Error = err.Error()
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		report.Checks[name] = result
+		if err != nil {
+			report.Status = model.HealthStatusFailing
+		}
+	}
+
+	var draining error
+	if healthService.Draining() {
+		draining = ErrDraining
+	}
+	record("draining", 0, draining)
+
+	var wait sync.WaitGroup
+	for _, check := range healthService.Checks {
+		wait.Add(1)
+		go func(check HealthCheck) {
+			defer wait.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthService.Timeout)
+			defer cancel()
+
+			started := time.Now()
+			err := check.Check(checkCtx)
+			if err == nil && checkCtx.Err() != nil {
+				err = checkCtx.Err()
+			}
+			record(check.Name, time.Since(started), err)
+		}(check)
+	}
+	wait.Wait()
+	return report
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Accruals keep extra precision, the posted amount is rounded to the balance precision
+const accrualPrecision = 10
+
+// Returned by the poster of an interest transaction when another run claimed the accruals it pays first
+var errInterestClaimed = errors.New("interest accruals were claimed by another run")
+
+// Responsible for interest configuration, daily accrual and periodic posting
+type InterestService struct {
+	Repo            InterestRepository
+	AccountRepo     AccountRepository
+	TransactionRepo TransactionRepository
+	// Posts interest like any other transfer
+	TransactionService *TransactionService
+	AuditLogger        *common.AuditLogger
+	// Account interest is paid from and received into, interest is disabled when zero
+	ExpenseAccountID int
+}
+
+func NewInterestService(interestRepo InterestRepository, accountRepo AccountRepository, transactionRepo TransactionRepository,
+	transactionService *TransactionService, auditLogger *common.AuditLogger, expenseAccountID int) *InterestService {
+	return &InterestService{
+		Repo:               interestRepo,
+		AccountRepo:        accountRepo,
+		TransactionRepo:    transactionRepo,
+		TransactionService: transactionService,
+		AuditLogger:        auditLogger,
+		ExpenseAccountID:   expenseAccountID,
+	}
+}
+
+// Returns the fraction of a year between start and end under the given day-count convention
+func DayCountFraction(convention string, start, end time.Time) (decimal.Decimal, error) {
+	switch convention {
+	case model.DayCountActual365:
+		days := int(truncateToDay(end).Sub(truncateToDay(start)).Hours() / 24)
+		return decimal.NewFromInt(int64(days)).Div(decimal.NewFromInt(365)), nil
+	case model.DayCount30360:
+		// US (NASD) 30/360: day 31 counts as day 30, and the end day only when the start day is 30 or 31
+		y1, m1, d1 := start.Date()
+		y2, m2, d2 := end.Date()
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 && d1 == 30 {
+			d2 = 30
+		}
+		days := 360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1)
+		return decimal.NewFromInt(int64(days)).Div(decimal.NewFromInt(360)), nil
+	default:
+		return decimal.Zero, &common.ValidationError{Message: fmt.Sprintf("unsupported day count convention %q", convention)}
+	}
+}
+
+// Calculates one day's interest on a closing balance
+func DailyInterest(closingBalance, annualRate decimal.Decimal, convention string, day time.Time) (decimal.Decimal, error) {
+	fraction, err := DayCountFraction(convention, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return closingBalance.Mul(annualRate).Div(decimal.NewFromInt(100)).Mul(fraction).Round(accrualPrecision), nil
+}
+
+// Creates or replaces the interest configuration of an account
+func (interestService *InterestService) ConfigureInterest(ctx context.Context, accountID int, input model.InterestConfigInput) (*model.InterestConfig, error) {
+	if input.DayCountConvention != model.DayCountActual365 && input.DayCountConvention != model.DayCount30360 {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("day_count_convention must be %s or %s", model.DayCountActual365, model.DayCount30360)}
+	}
+	if input.AnnualRate.Abs().GreaterThan(decimal.NewFromInt(100)) {
+		return nil, &common.ValidationError{Message: "annual_rate must be between -100 and 100 percent"}
+	}
+	if accountID == interestService.ExpenseAccountID {
+		return nil, &common.ValidationError{Message: "the interest expense account cannot earn interest"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	account, err := interestService.AccountRepo.GetAccountByIDWithContext(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking account: %w", err)
+	}
+	if account == nil {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("account %d not found", accountID)}
+	}
+
+	config := model.InterestConfig{
+		AccountID:          accountID,
+		AnnualRate:         input.AnnualRate,
+		DayCountConvention: input.DayCountConvention,
+		Enabled:            true,
+		StartDate:          truncateToDay(time.Now().UTC()),
+	}
+	if input.Enabled != nil {
+		config.Enabled = *input.Enabled
+	}
+	if input.StartDate != nil {
+		config.StartDate = truncateToDay(input.StartDate.UTC())
+	}
+
+	if err := interestService.Repo.SaveInterestConfigWithContext(ctx, &config); err != nil {
+		return nil, err
+	}
+
+	if interestService.AuditLogger != nil {
+		interestService.AuditLogger.LogActionWithContext(ctx, "Interest Configured", fmt.Sprintf("Account ID: %d, Annual Rate: %s%%, Day Count: %s, Enabled: %t",
+			config.AccountID, config.AnnualRate.String(), config.DayCountConvention, config.Enabled))
+	}
+
+	return &config, nil
+}
+
+// Retrieves the interest configuration of an account, returns nil when none is set
+func (interestService *InterestService) GetInterestConfig(accountID int) (*model.InterestConfig, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return interestService.Repo.GetInterestConfigWithContext(ctx, accountID)
+}
+
+// Retrieves the daily accruals of an account within a date range
+func (interestService *InterestService) ListAccruals(accountID int, from, to time.Time) ([]model.InterestAccrual, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return interestService.Repo.ListAccrualsWithContext(ctx, accountID, from, to)
+}
+
+// Accrues interest for every enabled account for each day not yet accrued, up to and including through.
+// Closing balances are derived from the current balance by backing out later ledger movements. An account that fails
+// does not hold back the others, the errors of all failed accounts are returned together.
+func (interestService *InterestService) AccrueInterest(ctx context.Context, through time.Time) error {
+	if interestService.ExpenseAccountID == 0 {
+		return nil
+	}
+
+	through = truncateToDay(through.UTC())
+	configs, err := interestService.Repo.ListEnabledInterestConfigsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, config := range configs {
+		if err := interestService.accrueAccount(ctx, config, through); err != nil {
+			errs = append(errs, fmt.Errorf("accruing interest for account %d: %w", config.AccountID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (interestService *InterestService) accrueAccount(ctx context.Context, config model.InterestConfig, through time.Time) error {
+	day := truncateToDay(config.StartDate.UTC())
+	last, err := interestService.Repo.GetLastAccrualDateWithContext(ctx, config.AccountID)
+	if err != nil {
+		return err
+	}
+	if last != nil && !truncateToDay(last.UTC()).Before(day) {
+		day = truncateToDay(last.UTC()).AddDate(0, 0, 1)
+	}
+	if day.After(through) {
+		return nil
+	}
+
+	account, err := interestService.AccountRepo.GetAccountByIDWithContext(ctx, config.AccountID)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return fmt.Errorf("account not found")
+	}
+
+	for ; !day.After(through); day = day.AddDate(0, 0, 1) {
+		laterMovements, err := interestService.TransactionRepo.SumLedgerEntriesSinceWithContext(ctx, config.AccountID, day.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+		closingBalance := account.Balance.Sub(laterMovements)
+
+		amount, err := DailyInterest(closingBalance, config.AnnualRate, config.DayCountConvention, day)
+		if err != nil {
+			return err
+		}
+
+		accrual := model.InterestAccrual{
+			AccountID:          config.AccountID,
+			AccrualDate:        day,
+			ClosingBalance:     closingBalance,
+			AnnualRate:         config.AnnualRate,
+			DayCountConvention: config.DayCountConvention,
+			Amount:             amount,
+		}
+		if err := interestService.Repo.CreateAccrualWithContext(ctx, accrual); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Posts all unposted accrued interest through the transfer path. Positive interest is paid from the expense account
+// into the account, negative interest is collected the other way. Each account is posted in its own database
+// transaction together with the accruals it pays, so a run never posts them twice; an account that fails is logged and
+// the next one is posted.
+func (interestService *InterestService) PostInterest(ctx context.Context) error {
+	if interestService.ExpenseAccountID == 0 {
+		return nil
+	}
+
+	unposted, err := interestService.Repo.ListUnpostedInterestWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, pending := range unposted {
+		if err := interestService.postAccount(ctx, pending); err != nil {
+			common.LogErrorWithContext(ctx, "Error posting interest", "account_id", pending.AccountID, "error", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("posting interest failed for %d of %d accounts", failed, len(unposted))
+	}
+	return nil
+}
+
+// Posts the pending interest of one account, skipping it when another run claimed its accruals first
+func (interestService *InterestService) postAccount(ctx context.Context, pending model.UnpostedInterest) error {
+	amount := pending.Total.RoundBank(feePrecision)
+	if amount.IsZero() {
+		return nil
+	}
+
+	transaction := model.Transaction{
+		TransactionType:      model.TransactionTypeInterest,
+		SourceAccountID:      interestService.ExpenseAccountID,
+		DestinationAccountID: pending.AccountID,
+		Amount:               amount.Abs(),
+	}
+	if amount.IsNegative() {
+		transaction.SourceAccountID, transaction.DestinationAccountID = pending.AccountID, interestService.ExpenseAccountID
+	}
+	post := func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+		posted, err := interestService.Repo.PostInterestWithContext(ctx, pending, transaction, entries)
+		if err != nil {
+			return err
+		}
+		if !posted {
+			return errInterestClaimed
+		}
+		return nil
+	}
+
+	posted, err := interestService.TransactionService.PerformTransactionPostedBy(ctx, transaction, post)
+	if errors.Is(err, errInterestClaimed) {
+		common.LogInfoWithContext(ctx, "Interest accruals were claimed by another run, skipping", "account_id", pending.AccountID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if interestService.AuditLogger != nil {
+		interestService.AuditLogger.LogActionWithContext(ctx, "Interest Posted", fmt.Sprintf("Account ID: %d, Amount: %s, Transaction ID: %d",
+			pending.AccountID, amount.String(), posted.TransactionID))
+	}
+	return nil
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Number of runs returned when listing reconciliation history
+const reconciliationRunHistory = 50
+
+// Responsible for verifying stored balances against transaction history and booking approved corrections
+type ReconciliationService struct {
+	Repo        ReconciliationRepository
+	AuditLogger *common.AuditLogger
+	// Account unexplained differences are booked against, corrections are disabled when zero
+	SuspenseAccountID int
+}
+
+func NewReconciliationService(reconciliationRepo ReconciliationRepository, auditLogger *common.AuditLogger, suspenseAccountID int) *ReconciliationService {
+	return &ReconciliationService{
+		Repo:              reconciliationRepo,
+		AuditLogger:       auditLogger,
+		SuspenseAccountID: suspenseAccountID,
+	}
+}
+
+// Recomputes every account's expected balance as its initial balance plus its transactions,
+// and stores a run listing each account whose stored balance or ledger entries disagree
+func (reconciliationService *ReconciliationService) Reconcile(ctx context.Context) (*model.ReconciliationRun, error) {
+	run := &model.ReconciliationRun{StartedAt: time.Now().UTC(), Mismatches: []model.ReconciliationMismatch{}}
+
+	accounts, err := reconciliationService.Repo.ListAccountReconciliationsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		if mismatch := CompareAccountHistory(account); mismatch != nil {
+			run.Mismatches = append(run.Mismatches, *mismatch)
+		}
+	}
+
+	completedAt := time.Now().UTC()
+	run.CompletedAt = &completedAt
+	run.AccountsChecked = len(accounts)
+	run.MismatchCount = len(run.Mismatches)
+
+	if err := reconciliationService.Repo.SaveRunWithContext(ctx, run); err != nil {
+		return nil, err
+	}
+
+	if reconciliationService.AuditLogger != nil {
+		reconciliationService.AuditLogger.LogActionWithContext(ctx, "Reconciliation Completed", fmt.Sprintf("Run ID: %d, Accounts Checked: %d, Mismatches: %d",
+			run.RunID, run.AccountsChecked, run.MismatchCount))
+		for _, mismatch := range run.Mismatches {
+			reconciliationService.AuditLogger.LogActionWithContext(ctx, "Reconciliation Mismatch", fmt.Sprintf("Run ID: %d, Account ID: %d, %s",
+				run.RunID, mismatch.AccountID, mismatch.Details))
+		}
+	}
+
+	return run, nil
+}
+
+// Returns the mismatch for an account, or nil when its stored balance, transactions and ledger agree
+func CompareAccountHistory(account model.AccountReconciliation) *model.ReconciliationMismatch {
+	expected := account.InitialBalance.Add(account.TransactionTotal)
+	ledger := account.InitialBalance.Add(account.LedgerTotal)
+	difference := account.StoredBalance.Sub(expected)
+
+	var details []string
+	if !difference.IsZero() {
+		details = append(details, fmt.Sprintf("stored balance %s differs from initial balance plus transactions %s by %s",
+			account.StoredBalance.String(), expected.String(), difference.String()))
+	}
+	if !ledger.Equal(expected) {
+		details = append(details, fmt.Sprintf("ledger entries imply %s, transactions imply %s", ledger.String(), expected.String()))
+	}
+	if len(details) == 0 {
+		return nil
+	}
+
+	return &model.ReconciliationMismatch{
+		AccountID:       account.AccountID,
+		StoredBalance:   account.StoredBalance,
+		ExpectedBalance: expected,
+		LedgerBalance:   ledger,
+		Difference:      difference,
+		Details:         strings.Join(details, "; "),
+		Status:          model.MismatchStatusOpen,
+	}
+}
+
+// Retrieves the most recent reconciliation runs
+func (reconciliationService *ReconciliationService) ListRuns() ([]model.ReconciliationRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return reconciliationService.Repo.ListRunsWithContext(ctx, reconciliationRunHistory)
+}
+
+// Retrieves a run with its mismatches, nil when it does not exist
+func (reconciliationService *ReconciliationService) GetRun(runID int) (*model.ReconciliationRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return reconciliationService.Repo.GetRunWithContext(ctx, runID)
+}
+
+// Books the unexplained difference of an open mismatch against the suspense account. The stored balance
+// is kept as the truth: the account's leg is recorded without changing its balance, and the suspense
+// account carries the offsetting movement for investigation. Returns nil when the mismatch does not exist.
+func (reconciliationService *ReconciliationService) ApproveCorrection(ctx context.Context, runID int, mismatchID int, approvedBy string) (*model.ReconciliationMismatch, error) {
+	if reconciliationService.SuspenseAccountID == 0 {
+		return nil, &common.ValidationError{Message: "corrections require a configured suspense account"}
+	}
+	approvedBy = strings.TrimSpace(approvedBy)
+	if approvedBy == "" {
+		return nil, &common.ValidationError{Message: "approved_by must be provided"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	mismatch, err := reconciliationService.Repo.GetMismatchWithContext(ctx, runID, mismatchID)
+	if err != nil || mismatch == nil {
+		return nil, err
+	}
+	if mismatch.Status != model.MismatchStatusOpen {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("mismatch %d is already %s", mismatchID, mismatch.Status)}
+	}
+	if mismatch.AccountID == reconciliationService.SuspenseAccountID {
+		return nil, &common.ValidationError{Message: "the suspense account cannot be corrected against itself"}
+	}
+
+	// Re-check so a correction is never booked for a difference that has since been resolved
+	current, err := reconciliationService.Repo.GetAccountReconciliationWithContext(ctx, mismatch.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("account %d no longer exists", mismatch.AccountID)
+	}
+	difference := current.StoredBalance.Sub(current.InitialBalance.Add(current.TransactionTotal))
+	if !difference.Equal(mismatch.Difference) {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("difference for account %d is now %s, run reconciliation again", mismatch.AccountID, difference.String())}
+	}
+	if difference.IsZero() {
+		return nil, &common.ValidationError{Message: "stored balance matches transaction history, there is nothing to correct"}
+	}
+
+	transaction, entries := reconciliationCorrection(mismatch.AccountID, reconciliationService.SuspenseAccountID, difference)
+	corrected, err := reconciliationService.Repo.CorrectMismatchWithContext(ctx, mismatch.MismatchID, approvedBy, transaction, entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post correction: %w", err)
+	}
+	if !corrected {
+		return nil, &common.ValidationError{Message: fmt.Sprintf("mismatch %d is no longer open", mismatchID)}
+	}
+	if reconciliationService.AuditLogger != nil {
+		reconciliationService.AuditLogger.LogActionWithContext(ctx, "Reconciliation Correction Posted", fmt.Sprintf("Run ID: %d, Mismatch ID: %d, Account ID: %d, Difference: %s, Transaction ID: %d, Approved By: %s",
+			runID, mismatch.MismatchID, mismatch.AccountID, difference.String(), transaction.TransactionID, approvedBy))
+	}
+
+	return reconciliationService.Repo.GetMismatchWithContext(ctx, runID, mismatchID)
+}
+
+// Builds the transaction explaining a positive difference as a receipt from suspense, a negative one as a payment to it
+func reconciliationCorrection(accountID int, suspenseAccountID int, difference decimal.Decimal) (*model.Transaction, []model.LedgerEntry) {
+	transaction := &model.Transaction{
+		TransactionType:      model.TransactionTypeReconciliation,
+		SourceAccountID:      suspenseAccountID,
+		DestinationAccountID: accountID,
+		Amount:               difference.Abs(),
+		FeeAmount:            decimal.Zero,
+	}
+	if difference.IsNegative() {
+		transaction.SourceAccountID, transaction.DestinationAccountID = accountID, suspenseAccountID
+	}
+
+	entries := []model.LedgerEntry{
+		{AccountID: accountID, Amount: difference, EntryType: model.TransactionTypeReconciliation, RecordOnly: true},
+		{AccountID: suspenseAccountID, Amount: difference.Neg(), EntryType: model.TransactionTypeReconciliation, AllowNegative: true},
+	}
+	return transaction, entries
+}
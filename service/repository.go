@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"internal-transfers/model"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Persistence operations the services depend on, implemented by the persistence package
+// and mocked in unit tests.
+
+type AccountRepository interface {
+	GetAccountByIDWithContext(ctx context.Context, accountID int) (*model.Account, error)
+	CreateAccountWithContext(ctx context.Context, account model.Account) error
+	ListAccountsWithContext(ctx context.Context) ([]model.Account, error)
+}
+
+type TransactionRepository interface {
+	PostTransactionWithContext(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error
+	SumLedgerEntriesSinceWithContext(ctx context.Context, accountID int, since time.Time) (decimal.Decimal, error)
+	SumLedgerEntriesBetweenWithContext(ctx context.Context, accountID int, from, to time.Time) (decimal.Decimal, error)
+	SumDailyLedgerEntriesWithContext(ctx context.Context, accountID int, from, to time.Time) (map[time.Time]decimal.Decimal, error)
+	ListStatementLinesWithContext(ctx context.Context, accountID int, from, to time.Time) ([]model.StatementLine, error)
+	ListTransactionsWithContext(ctx context.Context, accountID int, afterID int, limit int) ([]model.Transaction, error)
+	ListTransactionsByIDWithContext(ctx context.Context, transactionIDs []int) ([]model.Transaction, error)
+	GetLastTransactionIDWithContext(ctx context.Context) (int, error)
+}
+
+type FeeScheduleRepository interface {
+	ListFeeSchedulesWithContext(ctx context.Context) ([]model.FeeSchedule, error)
+	ListEffectiveFeeSchedulesWithContext(ctx context.Context, asOf time.Time) ([]model.FeeSchedule, error)
+	GetFeeScheduleByIDWithContext(ctx context.Context, feeScheduleID int) (*model.FeeSchedule, error)
+	CreateFeeScheduleWithContext(ctx context.Context, schedule *model.FeeSchedule) error
+}
+
+type InterestRepository interface {
+	GetInterestConfigWithContext(ctx context.Context, accountID int) (*model.InterestConfig, error)
+	ListEnabledInterestConfigsWithContext(ctx context.Context) ([]model.InterestConfig, error)
+	SaveInterestConfigWithContext(ctx context.Context, config *model.InterestConfig) error
+	GetLastAccrualDateWithContext(ctx context.Context, accountID int) (*time.Time, error)
+	CreateAccrualWithContext(ctx context.Context, accrual model.InterestAccrual) error
+	ListAccrualsWithContext(ctx context.Context, accountID int, from, to time.Time) ([]model.InterestAccrual, error)
+	ListUnpostedInterestWithContext(ctx context.Context) ([]model.UnpostedInterest, error)
+	PostInterestWithContext(ctx context.Context, pending model.UnpostedInterest, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error)
+}
+
+type BalanceSnapshotRepository interface {
+	GetLatestSnapshotBeforeWithContext(ctx context.Context, accountID int, before time.Time) (*model.BalanceSnapshot, error)
+	SaveSnapshotsWithContext(ctx context.Context, snapshots []model.BalanceSnapshot) error
+}
+
+type ReconciliationRepository interface {
+	ListAccountReconciliationsWithContext(ctx context.Context) ([]model.AccountReconciliation, error)
+	GetAccountReconciliationWithContext(ctx context.Context, accountID int) (*model.AccountReconciliation, error)
+	SaveRunWithContext(ctx context.Context, run *model.ReconciliationRun) error
+	ListRunsWithContext(ctx context.Context, limit int) ([]model.ReconciliationRun, error)
+	GetRunWithContext(ctx context.Context, runID int) (*model.ReconciliationRun, error)
+	GetMismatchWithContext(ctx context.Context, runID int, mismatchID int) (*model.ReconciliationMismatch, error)
+	CorrectMismatchWithContext(ctx context.Context, mismatchID int, approvedBy string, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error)
+}
+
+type AdjustmentRepository interface {
+	CreateAdjustmentWithContext(ctx context.Context, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) error
+	ListAdjustmentsWithContext(ctx context.Context, accountID int) ([]model.Adjustment, error)
+}
+
+type ApprovalRepository interface {
+	CreateOperationWithContext(ctx context.Context, operation *model.PendingOperation) error
+	GetOperationWithContext(ctx context.Context, operationID int) (*model.PendingOperation, error)
+	ListOperationsWithContext(ctx context.Context, status string, limit int) ([]model.PendingOperation, error)
+	DecideOperationWithContext(ctx context.Context, operationID int, status string, decidedBy string, reason *string, decidedAt time.Time) (bool, error)
+	CompleteOperationWithContext(ctx context.Context, operationID int, status string, transactionID *int, reason *string) error
+	ExecuteTransferWithContext(ctx context.Context, operationID int, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error)
+	ExecuteAdjustmentWithContext(ctx context.Context, operationID int, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error)
+	FailStaleOperationsWithContext(ctx context.Context, approvedBefore time.Time, reason string) ([]model.PendingOperation, error)
+	ExpireOperationsWithContext(ctx context.Context, now time.Time) ([]model.PendingOperation, error)
+}
+
+type APIKeyRepository interface {
+	CreateAPIKeyWithContext(ctx context.Context, apiKey *model.APIKey) error
+	GetAPIKeyByHashWithContext(ctx context.Context, keyHash string) (*model.APIKey, error)
+	ListAPIKeysWithContext(ctx context.Context) ([]model.APIKey, error)
+	RevokeAPIKeyWithContext(ctx context.Context, keyID int) (bool, error)
+}
+
+type OutboxRepository interface {
+	AddEventWithContext(ctx context.Context, eventType string, payload any) error
+}
+
+type WebhookRepository interface {
+	CreateEndpointWithContext(ctx context.Context, endpoint *model.WebhookEndpoint) error
+	ListEndpointsWithContext(ctx context.Context) ([]model.WebhookEndpoint, error)
+	DisableEndpointWithContext(ctx context.Context, endpointID int) (bool, error)
+	FanOutEventsWithContext(ctx context.Context, limit int) (int, error)
+	ClaimDueDeliveriesWithContext(ctx context.Context, limit int, lease time.Duration) ([]model.DueWebhookDelivery, error)
+	MarkDeliveredWithContext(ctx context.Context, deliveryID int64, statusCode int) error
+	MarkFailedWithContext(ctx context.Context, deliveryID int64, statusCode *int, message string, retryAfter time.Duration, dead bool) error
+	ListDeliveriesWithContext(ctx context.Context, status string, limit int) ([]model.WebhookDelivery, error)
+	ReplayDeliveryWithContext(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error)
+	ReplayEventsWithContext(ctx context.Context, endpointID int, fromEventID int64, toEventID int64) (int, error)
+}
+
+type AccountEventStoreRepository interface {
+	ListEventsWithContext(ctx context.Context, accountID int, afterVersion int64, toVersion int64, limit int) ([]model.StoredAccountEvent, error)
+	GetVersionAtWithContext(ctx context.Context, accountID int, asOf time.Time) (int64, error)
+	ListAccountIDsWithContext(ctx context.Context) ([]int, error)
+	GetLatestSnapshotWithContext(ctx context.Context, accountID int, maxVersion int64) (*model.AccountAggregateSnapshot, error)
+	SaveSnapshotWithContext(ctx context.Context, snapshot model.AccountAggregateSnapshot) error
+	ListAccountsDueForSnapshotWithContext(ctx context.Context, every int) ([]int, error)
+	ProjectAccountWithContext(ctx context.Context, account model.Account, version int64) (bool, error)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Operation settings shared by the services, set from the configuration at startup
+var (
+	// Deadline of each operation against the database
+	OperationTimeout = 30 * time.Second
+	// Attempts made by operations retried on timeouts and deadlocks (account creation and lookup, transfers)
+	RetryAttempts = 2
+	// Pause between retry attempts
+	RetryDelay = 2 * time.Second
+)
+
+// PostgreSQL error codes of transactions aborted by a concurrent one, which succeed when run again
+const (
+	pqDeadlockDetected     = "40P01"
+	pqSerializationFailure = "40001"
+)
+
+// Reports whether an operation failed transiently and is worth another attempt: it timed out, or the database
+// aborted it as a deadlock or serialization failure
+func retryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Code == pqDeadlockDetected || pqErr.Code == pqSerializationFailure)
+}
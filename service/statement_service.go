@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Responsible for producing account statements and the monthly pre-generated statement files
+type StatementService struct {
+	AccountRepo     AccountRepository
+	TransactionRepo TransactionRepository
+	BalanceService  *BalanceService
+	AuditLogger     *common.AuditLogger
+	// Directory monthly statements are written to, monthly generation is disabled when empty
+	OutputDir string
+	// Formats written by the monthly job
+	Formats []string
+}
+
+func NewStatementService(accountRepo AccountRepository, transactionRepo TransactionRepository, balanceService *BalanceService,
+	auditLogger *common.AuditLogger, outputDir string, formats []string) *StatementService {
+	return &StatementService{
+		AccountRepo:     accountRepo,
+		TransactionRepo: transactionRepo,
+		BalanceService:  balanceService,
+		AuditLogger:     auditLogger,
+		OutputDir:       outputDir,
+		Formats:         formats,
+	}
+}
+
+// Checks the format is one of csv, json or pdf
+func ValidateStatementFormat(format string) error {
+	switch format {
+	case model.StatementFormatCSV, model.StatementFormatJSON, model.StatementFormatPDF:
+		return nil
+	default:
+		return &common.ValidationError{Message: fmt.Sprintf("format must be one of %s, %s, %s", model.StatementFormatCSV, model.StatementFormatJSON, model.StatementFormatPDF)}
+	}
+}
+
+// Builds the statement of an account for [from, to]. Returns nil when the account does not exist
+func (statementService *StatementService) GenerateStatement(accountID int, from, to time.Time) (*model.Statement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return statementService.GenerateStatementWithContext(ctx, accountID, from, to)
+}
+
+func (statementService *StatementService) GenerateStatementWithContext(ctx context.Context, accountID int, from, to time.Time) (*model.Statement, error) {
+	from, to = from.UTC(), to.UTC()
+	if to.Before(from) {
+		return nil, &common.ValidationError{Message: "from must not be after to"}
+	}
+
+	account, err := statementService.AccountRepo.GetAccountByIDWithContext(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting account by ID: %w", err)
+	}
+	if account == nil {
+		return nil, nil
+	}
+
+	// Nothing was posted before the account existed, so its initial balance opens any earlier period
+	opening := account.InitialBalance
+	if from.After(account.CreatedAt) {
+		balance, err := statementService.BalanceService.GetBalanceAsOfWithContext(ctx, accountID, from.Add(-time.Microsecond))
+		if err != nil {
+			return nil, err
+		}
+		opening = balance.Balance
+	}
+
+	lines, err := statementService.TransactionRepo.ListStatementLinesWithContext(ctx, accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &model.Statement{
+		AccountID:      accountID,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		TotalCredits:   decimal.Zero,
+		TotalDebits:    decimal.Zero,
+		Lines:          lines,
+		GeneratedAt:    time.Now().UTC(),
+	}
+
+	running := opening
+	for i := range statement.Lines {
+		amount := statement.Lines[i].Amount
+		running = running.Add(amount)
+		statement.Lines[i].RunningBalance = running
+		if amount.IsNegative() {
+			statement.TotalDebits = statement.TotalDebits.Add(amount.Neg())
+		} else {
+			statement.TotalCredits = statement.TotalCredits.Add(amount)
+		}
+	}
+	statement.ClosingBalance = running
+
+	return statement, nil
+}
+
+// Writes the statement in the requested format
+func RenderStatement(w io.Writer, statement *model.Statement, format string) error {
+	switch format {
+	case model.StatementFormatCSV:
+		return renderStatementCSV(w, statement)
+	case model.StatementFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(statement)
+	case model.StatementFormatPDF:
+		return renderStatementPDF(w, statement)
+	default:
+		return ValidateStatementFormat(format)
+	}
+}
+
+func renderStatementCSV(w io.Writer, statement *model.Statement) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{
+		{"account_id", strconv.Itoa(statement.AccountID)},
+		{"from", statement.From.Format(time.RFC3339)},
+		{"to", statement.To.Format(time.RFC3339)},
+		{"opening_balance", statement.OpeningBalance.String()},
+		{},
+		{"posted_at", "transaction_id", "transaction_type", "entry_type", "counterparty_account_id", "amount", "running_balance"},
+	}
+	for _, line := range statement.Lines {
+		records = append(records, []string{
+			line.PostedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(line.TransactionID),
+			line.TransactionType,
+			line.EntryType,
+			strconv.Itoa(line.CounterpartyAccountID),
+			line.Amount.String(),
+			line.RunningBalance.String(),
+		})
+	}
+	records = append(records,
+		[]string{},
+		[]string{"total_credits", statement.TotalCredits.String()},
+		[]string{"total_debits", statement.TotalDebits.String()},
+		[]string{"closing_balance", statement.ClosingBalance.String()},
+	)
+
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("error writing CSV statement: %w", err)
+	}
+	return nil
+}
+
+func renderStatementPDF(w io.Writer, statement *model.Statement) error {
+	doc := common.NewPDFDocument()
+	doc.AddLine(fmt.Sprintf("Account Statement - Account %d", statement.AccountID))
+	doc.AddLine(fmt.Sprintf("Period: %s to %s", statement.From.Format(time.RFC3339), statement.To.Format(time.RFC3339)))
+	doc.AddLine(fmt.Sprintf("Generated: %s", statement.GeneratedAt.Format(time.RFC3339)))
+	doc.AddLine("")
+	doc.AddLine(fmt.Sprintf("Opening balance: %s", statement.OpeningBalance.StringFixed(5)))
+	doc.AddLine("")
+
+	header := fmt.Sprintf("%-20s %-8s %-11s %-12s %18s %18s", "Posted (UTC)", "Txn", "Type", "Counterparty", "Amount", "Balance")
+	doc.AddLine(header)
+	for _, line := range statement.Lines {
+		doc.AddLine(fmt.Sprintf("%-20s %-8d %-11s %-12d %18s %18s",
+			line.PostedAt.UTC().Format("2006-01-02 15:04:05"),
+			line.TransactionID,
+			line.EntryType,
+			line.CounterpartyAccountID,
+			line.Amount.StringFixed(5),
+			line.RunningBalance.StringFixed(5)))
+	}
+
+	doc.AddLine("")
+	doc.AddLine(fmt.Sprintf("Total credits:   %s", statement.TotalCredits.StringFixed(5)))
+	doc.AddLine(fmt.Sprintf("Total debits:    %s", statement.TotalDebits.StringFixed(5)))
+	doc.AddLine(fmt.Sprintf("Closing balance: %s", statement.ClosingBalance.StringFixed(5)))
+
+	if _, err := doc.WriteTo(w); err != nil {
+		return fmt.Errorf("error writing PDF statement: %w", err)
+	}
+	return nil
+}
+
+// Writes last month's statement of every account to OutputDir/YYYY-MM/account-<id>.<format>.
+// Files that already exist are skipped, so the job can run more often than monthly.
+func (statementService *StatementService) GenerateMonthlyStatements(ctx context.Context, now time.Time) error {
+	if statementService.OutputDir == "" {
+		return nil
+	}
+
+	now = now.UTC()
+	periodEnd := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periodStart := periodEnd.AddDate(0, -1, 0)
+	dir := filepath.Join(statementService.OutputDir, periodStart.Format("2006-01"))
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating statement directory: %w", err)
+	}
+
+	accounts, err := statementService.AccountRepo.ListAccountsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	generated := 0
+	for _, account := range accounts {
+		if !account.CreatedAt.Before(periodEnd) {
+			continue
+		}
+
+		var statement *model.Statement
+		for _, format := range statementService.Formats {
+			path := filepath.Join(dir, fmt.Sprintf("account-%d.%s", account.AccountID, format))
+			if _, err := os.Stat(path); err == nil {
+				continue
+			}
+
+			if statement == nil {
+				statement, err = statementService.GenerateStatementWithContext(ctx, account.AccountID, periodStart, periodEnd.Add(-time.Microsecond))
+				if err != nil {
+					return fmt.Errorf("generating statement for account %d: %w", account.AccountID, err)
+				}
+			}
+
+			if err := writeStatementFile(path, statement, format); err != nil {
+				return err
+			}
+			generated++
+		}
+	}
+
+	if statementService.AuditLogger != nil && generated > 0 {
+		statementService.AuditLogger.LogActionWithContext(ctx, "Monthly Statements Generated", fmt.Sprintf("Period: %s, Files: %d, Directory: %s",
+			periodStart.Format("2006-01"), generated, dir))
+	}
+	return nil
+}
+
+// Writes to a temporary file first so a crash never leaves a partial statement behind
+func writeStatementFile(path string, statement *model.Statement, format string) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("error creating statement file: %w", err)
+	}
+
+	if err := RenderStatement(file, statement, format); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing statement file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error writing statement file: %w", err)
+	}
+	return nil
+}
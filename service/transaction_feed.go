@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Ends a subscription whose reader fell more than its buffer behind the posted transactions
+var ErrFeedLagged = errors.New("subscriber fell too far behind the transaction feed")
+
+// Ends the subscriptions when the feed is closed at shutdown
+var ErrFeedClosed = errors.New("transaction feed is closed, the server is shutting down")
+
+// Fans committed transactions out to the subscribers streaming them. Follow publishes every transaction committed to
+// the database, whichever instance posted it. Subscribers that cannot keep up are dropped and resume from the last
+// transaction they received.
+type TransactionFeed struct {
+	mutex       sync.Mutex
+	subscribers map[*TransactionSubscription]struct{}
+	closed      bool
+}
+
+func NewTransactionFeed() *TransactionFeed {
+	return &TransactionFeed{subscribers: map[*TransactionSubscription]struct{}{}}
+}
+
+// Transactions posted after Subscribe, in the order they were published
+type TransactionSubscription struct {
+	feed   *TransactionFeed
+	events chan model.Transaction
+	err    error
+}
+
+// Delivers the transactions, closed when the subscription ends
+func (subscription *TransactionSubscription) Events() <-chan model.Transaction {
+	return subscription.events
+}
+
+// Why the subscription ended: ErrFeedLagged, ErrFeedClosed, or nil when the subscriber closed it
+func (subscription *TransactionSubscription) Err() error {
+	subscription.feed.mutex.Lock()
+	defer subscription.feed.mutex.Unlock()
+	return subscription.err
+}
+
+// Ends the subscription, safe to call more than once
+func (subscription *TransactionSubscription) Close() {
+	subscription.feed.mutex.Lock()
+	defer subscription.feed.mutex.Unlock()
+	subscription.feed.remove(subscription, nil)
+}
+
+// Starts receiving the transactions published from now on, holding up to buffer of them for a slow reader
+func (feed *TransactionFeed) Subscribe(buffer int) *TransactionSubscription {
+	subscription := &TransactionSubscription{feed: feed, events: make(chan model.Transaction, buffer)}
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+	if feed.closed {
+		subscription.err = ErrFeedClosed
+		close(subscription.events)
+		return subscription
+	}
+	feed.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// Hands a posted transaction to every subscriber, dropping those whose buffer is full
+func (feed *TransactionFeed) Publish(transaction model.Transaction) {
+	if feed == nil {
+		return
+	}
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+	for subscription := range feed.subscribers {
+		select {
+		case subscription.events <- transaction:
+		default:
+			feed.remove(subscription, ErrFeedLagged)
+		}
+	}
+}
+
+// Ends every subscription with ErrFeedClosed and refuses new ones
+func (feed *TransactionFeed) Close() {
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+	feed.closed = true
+	for subscription := range feed.subscribers {
+		feed.remove(subscription, ErrFeedClosed)
+	}
+}
+
+// Number of open subscriptions
+func (feed *TransactionFeed) Subscribers() int {
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+	return len(feed.subscribers)
+}
+
+// Ends a subscription with err, the caller holds the mutex
+func (feed *TransactionFeed) remove(subscription *TransactionSubscription, err error) {
+	if _, found := feed.subscribers[subscription]; !found {
+		return
+	}
+	delete(feed.subscribers, subscription)
+	subscription.err = err
+	close(subscription.events)
+}
+
+// Transaction IDs are allocated before commit. An ID missing below the highest one seen is looked for again until the
+// transaction that may hold it can no longer commit.
+func feedGapTimeout() time.Duration {
+	return OperationTimeout + time.Minute
+}
+
+// Publishes the transactions committed to the database, polling repo every interval until ctx ends. Transactions
+// committed before the first successful poll are not published, streams replay those from the database. Failed polls
+// are logged and retried on the next tick.
+func (feed *TransactionFeed) Follow(ctx context.Context, repo TransactionRepository, interval time.Duration) {
+	follower := &feedFollower{feed: feed, repo: repo, missing: map[int]time.Time{}}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := follower.poll(ctx); err != nil && ctx.Err() == nil {
+				common.LogErrorWithContext(ctx, "Error polling committed transactions", "error", err)
+			}
+		}
+	}
+}
+
+// Position of Follow in the transactions table
+type feedFollower struct {
+	feed *TransactionFeed
+	repo TransactionRepository
+	// Highest transaction ID published, set from the database by the first poll
+	lastID  int
+	started bool
+	// IDs below lastID not committed yet, with the time they were first missed
+	missing map[int]time.Time
+}
+
+// Publishes the transactions committed since the last poll, in ID order for each query
+func (follower *feedFollower) poll(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	now := time.Now()
+
+	if !follower.started {
+		lastID, err := follower.repo.GetLastTransactionIDWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		follower.lastID, follower.started = lastID, true
+		return nil
+	}
+
+	if len(follower.missing) > 0 {
+		late, err := follower.repo.ListTransactionsByIDWithContext(ctx, slices.Sorted(maps.Keys(follower.missing)))
+		if err != nil {
+			return err
+		}
+		for _, transaction := range late {
+			delete(follower.missing, transaction.TransactionID)
+			follower.feed.Publish(transaction)
+		}
+		for id, missedAt := range follower.missing {
+			if now.Sub(missedAt) > feedGapTimeout() {
+				delete(follower.missing, id)
+			}
+		}
+	}
+
+	for {
+		page, err := follower.repo.ListTransactionsWithContext(ctx, 0, follower.lastID, MaxTransactionPageSize)
+		if err != nil {
+			return err
+		}
+		for _, transaction := range page {
+			for id := follower.lastID + 1; id < transaction.TransactionID; id++ {
+				follower.missing[id] = now
+			}
+			follower.lastID = transaction.TransactionID
+			follower.feed.Publish(transaction)
+		}
+		if len(page) < MaxTransactionPageSize {
+			return nil
+		}
+	}
+}
@@ -2,114 +2,388 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"internal-transfers/common"
+	"internal-transfers/metrics"
 	"internal-transfers/model"
-	"internal-transfers/persistence"
+	"internal-transfers/tracing"
+	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Returned for transfers requested after AbortTransfers
+var ErrTransfersAborted = errors.New("transfers are stopped, the server is shutting down")
+
+// Causes of failed transfers, wrapped in the errors PerformTransaction returns and classified by TransferErrorClass
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	ErrFeeCalculation  = errors.New("fee calculation failed")
+	ErrPostingFailed   = errors.New("failed to save transaction")
 )
 
 // Responsible for handling the transaction related business logic
 type TransactionService struct {
-	AccountRepo     *persistence.AccountRepository
-	TransactionRepo *persistence.TransactionRepository
+	AccountRepo     AccountRepository
+	TransactionRepo TransactionRepository
 	AuditLogger     *common.AuditLogger
+	// Evaluates transfer fees, transfers are free when nil
+	FeeService *FeeService
+	// Internal accounts (fee revenue, interest expense) allowed to run a negative balance
+	SystemAccounts map[int]bool
+	// Followed by StreamTransactions, streams fail with ErrFeedClosed when nil
+	Feed *TransactionFeed
+	// Records a TransferFailed event for every failed transfer, none are recorded when nil. Completed transfers
+	// get their event from TransactionRepo, in the database transaction posting them.
+	Outbox OutboxRepository
+
+	// Transfers in progress, waited for at shutdown
+	inFlight sync.WaitGroup
+	// Cancelled by AbortTransfers, rolling back the transfers still running
+	abortCtx context.Context
+	abort    context.CancelFunc
 }
 
-func NewTransactionService(accountRepo *persistence.AccountRepository, transactionRepo *persistence.TransactionRepository, auditLogger *common.AuditLogger) *TransactionService {
+func NewTransactionService(accountRepo AccountRepository, transactionRepo TransactionRepository, auditLogger *common.AuditLogger) *TransactionService {
+	abortCtx, abort := context.WithCancel(context.Background())
 	return &TransactionService{
 		AccountRepo:     accountRepo,
 		TransactionRepo: transactionRepo,
 		AuditLogger:     auditLogger,
+		SystemAccounts:  map[int]bool{},
+		abortCtx:        abortCtx,
+		abort:           abort,
+	}
+}
+
+// Waits until no transfer is in progress, or fails with the context error when ctx ends first
+func (transactionService *TransactionService) WaitForTransfers(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		transactionService.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Cancels the transfers in progress, their database transactions roll back, and fails any transfer started later
+func (transactionService *TransactionService) AbortTransfers() {
+	if transactionService.abort != nil {
+		transactionService.abort()
 	}
 }
 
-// Handles the logic for performing a transaction with retry and timeout
-func (transactionService *TransactionService) PerformTransaction(transaction model.Transaction) error {
+// Marks an internal account as allowed to run a negative balance
+func (transactionService *TransactionService) RegisterSystemAccount(accountID int) {
+	if accountID != 0 {
+		transactionService.SystemAccounts[accountID] = true
+	}
+}
+
+// Handles the logic for performing a transaction with retry and timeout.
+// Returns the stored transaction including any fee charged.
+func (transactionService *TransactionService) PerformTransaction(transaction model.Transaction) (*model.Transaction, error) {
+	return transactionService.PerformTransactionWithContext(context.Background(), transaction)
+}
+
+// Stores a transaction and its ledger legs atomically, setting the generated ID and timestamps on them
+type TransactionPoster func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error
+
+// Performs a transaction as part of the trace in ctx. Cancelling ctx does not abandon the transaction,
+// each attempt still runs until it completes, OperationTimeout passes or AbortTransfers is called.
+func (transactionService *TransactionService) PerformTransactionWithContext(ctx context.Context, transaction model.Transaction) (*model.Transaction, error) {
+	return transactionService.PerformTransactionPostedBy(ctx, transaction, nil)
+}
+
+// Performs a transaction like PerformTransactionWithContext, storing it with post instead of TransactionRepo so the
+// caller can record its own changes in the same database transaction
+func (transactionService *TransactionService) PerformTransactionPostedBy(ctx context.Context, transaction model.Transaction, post TransactionPoster) (_ *model.Transaction, err error) {
+	transactionService.inFlight.Add(1)
+	defer transactionService.inFlight.Done()
+
+	transactionType := transaction.TransactionType
+	if transactionType == "" {
+		transactionType = model.TransactionTypeTransfer
+	}
+
+	ctx, span := tracing.Start(ctx, "TransactionService.PerformTransaction",
+		attribute.String("transaction.type", transactionType),
+		tracing.AccountID("transaction.source_account_id", transaction.SourceAccountID),
+		tracing.AccountID("transaction.destination_account_id", transaction.DestinationAccountID),
+		tracing.Amount("transaction.amount", transaction.Amount))
+	defer tracing.End(span, &err)
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	if transactionService.abortCtx != nil {
+		if transactionService.abortCtx.Err() != nil {
+			return nil, ErrTransfersAborted
+		}
+		stop := context.AfterFunc(transactionService.abortCtx, cancel)
+		defer stop()
+	}
+
 	// Retry mechanism
-	var err error
-	for i := 0; i < 2; i++ {
-		err = transactionService.performTransactionWithRetry(transaction)
+	for i := 0; i < RetryAttempts; i++ {
+		if i > 0 {
+			metrics.ServiceRetries.Inc("transfer")
+			span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", i+1)))
+		}
+		var posted *model.Transaction
+		posted, err = transactionService.performTransactionWithRetry(ctx, transaction, post)
 		if err == nil {
-			return nil
+			metrics.TransfersTotal.Inc(transactionType, "success")
+			metrics.TransferAmount.Observe(posted.Amount.InexactFloat64(), transactionType)
+			span.SetAttributes(attribute.Int("transaction.id", posted.TransactionID))
+			return posted, nil
 		}
 
-		if err.Error() == "context deadline exceeded" || err.Error() == "pq: deadlock detected" {
-			time.Sleep(2 * time.Second) // wait for 2 seconds before retrying
+		if retryable(err) {
+			time.Sleep(RetryDelay) // wait before retrying
 			continue
 		}
 		break
 	}
-	return err
+	metrics.TransfersTotal.Inc(transactionType, TransferErrorClass(err))
+	if transactionType == model.TransactionTypeTransfer {
+		transactionService.recordFailure(ctx, transaction, err)
+	}
+	return nil, err
+}
+
+// Messages of the TransferFailed events by error class. Webhook receivers are outside the service, so the events never
+// carry the error itself, which may hold database or internal details.
+var transferFailureMessages = map[string]string{
+	"invalid_amount":     "the transfer amount is invalid",
+	"account_not_found":  "the source or destination account does not exist",
+	"insufficient_funds": "the source account has insufficient funds",
+	"fee_error":          "the transfer fee could not be calculated",
+	"timeout":            "the transfer timed out",
+	"posting_failed":     "the transfer could not be posted",
+	"error":              "the transfer failed",
+}
+
+// Writes the TransferFailed event of a transfer, even when the transfer was aborted. A failure to write it is
+// logged, the transfer's own error is what the caller gets.
+func (transactionService *TransactionService) recordFailure(ctx context.Context, transaction model.Transaction, transferErr error) {
+	if transactionService.Outbox == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), OperationTimeout)
+	defer cancel()
+
+	reason := TransferErrorClass(transferErr)
+	failure := model.TransferFailure{
+		SourceAccountID:      transaction.SourceAccountID,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Amount:               transaction.Amount,
+		Reason:               reason,
+		Error:                transferFailureMessages[reason],
+	}
+	if err := transactionService.Outbox.AddEventWithContext(ctx, model.EventTransferFailed, failure); err != nil {
+		common.LogErrorWithContext(ctx, "Error recording failed transfer", "error", err)
+	}
+}
+
+// Classifies a failed transfer for the transfers_total metric and the gRPC status codes
+func TransferErrorClass(err error) string {
+	var validationError *common.ValidationError
+	switch {
+	case errors.As(err, &validationError):
+		return "invalid_amount"
+	case errors.Is(err, ErrAccountNotFound):
+		return "account_not_found"
+	case errors.Is(err, common.ErrInsufficientBalance):
+		return "insufficient_funds"
+	case errors.Is(err, ErrFeeCalculation):
+		return "fee_error"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrPostingFailed):
+		return "posting_failed"
+	}
+	return "error"
 }
 
 // performTransactionWithRetry actually handles the transaction with context and timeout
-func (transactionService *TransactionService) performTransactionWithRetry(transaction model.Transaction) error {
-	// Set a timeout context (30 seconds)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func (transactionService *TransactionService) performTransactionWithRetry(ctx context.Context, transaction model.Transaction, post TransactionPoster) (*model.Transaction, error) {
+	// Set a timeout context (OperationTimeout)
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
 	defer cancel()
 
 	if transactionService.AuditLogger != nil {
-		transactionService.AuditLogger.LogAction("Transaction Initiated", fmt.Sprintf("Source Account ID: %d, Destination Account ID: %d, Amount: %s",
+		transactionService.AuditLogger.LogActionWithContext(ctx, "Transaction Initiated", fmt.Sprintf("Source Account ID: %d, Destination Account ID: %d, Amount: %s",
 			transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount.String()))
 	}
 
-	if transaction.Amount.LessThanOrEqual(decimal.NewFromInt(0)) {
-		return fmt.Errorf("transaction amount must be greater than zero")
+	if err := ValidateTransferAmount(transaction.Amount); err != nil {
+		return nil, err
+	}
+
+	if transaction.TransactionType == "" {
+		transaction.TransactionType = model.TransactionTypeTransfer
 	}
 
 	if transactionService.AuditLogger != nil {
-		transactionService.AuditLogger.LogAction("Amount Validation", fmt.Sprintf("Transaction Amount: %s (Valid: %t)", transaction.Amount.String(), transaction.Amount.GreaterThan(decimal.NewFromInt(0))))
+		transactionService.AuditLogger.LogActionWithContext(ctx, "Amount Validation", fmt.Sprintf("Transaction Amount: %s (Valid: %t)", transaction.Amount.String(), transaction.Amount.GreaterThan(decimal.NewFromInt(0))))
 	}
 
 	sourceAccount, err := transactionService.AccountRepo.GetAccountByIDWithContext(ctx, transaction.SourceAccountID)
 	if err != nil {
-		return fmt.Errorf("source account validation failed: %v", err)
+		return nil, fmt.Errorf("source account validation failed: %w", err)
 	}
-
-	if sourceAccount.Balance.LessThan(transaction.Amount) {
-		return fmt.Errorf("insufficient balance in source account")
+	if sourceAccount == nil {
+		return nil, fmt.Errorf("source account validation failed: %w: %d", ErrAccountNotFound, transaction.SourceAccountID)
 	}
 
 	destinationAccount, err := transactionService.AccountRepo.GetAccountByIDWithContext(ctx, transaction.DestinationAccountID)
 	if err != nil {
-		return fmt.Errorf("destination account validation failed: %v", err)
+		return nil, fmt.Errorf("destination account validation failed: %w", err)
+	}
+	if destinationAccount == nil {
+		return nil, fmt.Errorf("destination account validation failed: %w: %d", ErrAccountNotFound, transaction.DestinationAccountID)
 	}
 
 	if transactionService.AuditLogger != nil {
-		transactionService.AuditLogger.LogAction("Destination Account Found", fmt.Sprintf("Destination Account ID: %d, Balance: %s", destinationAccount.AccountID, destinationAccount.Balance.String()))
+		transactionService.AuditLogger.LogActionWithContext(ctx, "Destination Account Found", fmt.Sprintf("Destination Account ID: %d, Balance: %s", destinationAccount.AccountID, destinationAccount.Balance.String()))
+	}
+
+	transaction.FeeAmount = decimal.Zero
+	transaction.FeeBreakdown = nil
+	if transactionService.FeeService != nil && transaction.TransactionType == model.TransactionTypeTransfer {
+		breakdown, err := transactionService.FeeService.CalculateFee(ctx, sourceAccount, destinationAccount, transaction.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFeeCalculation, err)
+		}
+		if breakdown != nil && breakdown.ChargedFee.IsPositive() {
+			transaction.FeeAmount = breakdown.ChargedFee
+			transaction.FeeBreakdown = breakdown
+		}
 	}
 
-	if err := transactionService.updateAccountBalanceWithContext(ctx, sourceAccount, transaction.Amount.Neg()); err != nil {
-		return err
+	sourceIsSystem := transactionService.SystemAccounts[transaction.SourceAccountID]
+	if !sourceIsSystem && sourceAccount.Balance.LessThan(transaction.Amount.Add(transaction.FeeAmount)) {
+		return nil, common.ErrInsufficientBalance
 	}
 
-	if err := transactionService.updateAccountBalanceWithContext(ctx, destinationAccount, transaction.Amount); err != nil {
-		transactionService.updateAccountBalanceWithContext(ctx, sourceAccount, transaction.Amount)
-		return err
+	entries := []model.LedgerEntry{
+		{AccountID: transaction.SourceAccountID, Amount: transaction.Amount.Neg(), EntryType: transaction.TransactionType, AllowNegative: sourceIsSystem},
+		{AccountID: transaction.DestinationAccountID, Amount: transaction.Amount, EntryType: transaction.TransactionType},
 	}
+	if transaction.FeeBreakdown != nil {
+		entries = append(entries,
+			model.LedgerEntry{AccountID: transaction.SourceAccountID, Amount: transaction.FeeAmount.Neg(), EntryType: model.EntryTypeFee, AllowNegative: sourceIsSystem},
+			model.LedgerEntry{AccountID: transaction.FeeBreakdown.FeeAccountID, Amount: transaction.FeeAmount, EntryType: model.EntryTypeFee})
 
-	if err := transactionService.TransactionRepo.SaveTransactionWithContext(ctx, transaction); err != nil {
-		transactionService.updateAccountBalanceWithContext(ctx, sourceAccount, transaction.Amount)
-		transactionService.updateAccountBalanceWithContext(ctx, destinationAccount, transaction.Amount.Neg())
-		return fmt.Errorf("failed to save transaction: %v", err)
+		if transactionService.AuditLogger != nil {
+			transactionService.AuditLogger.LogActionWithContext(ctx, "Fee Calculated", fmt.Sprintf("Fee Schedule ID: %d, Fee: %s, Fee Account ID: %d",
+				transaction.FeeBreakdown.FeeScheduleID, transaction.FeeAmount.String(), transaction.FeeBreakdown.FeeAccountID))
+		}
+	}
+
+	if post == nil {
+		post = transactionService.TransactionRepo.PostTransactionWithContext
+	}
+	if err := post(ctx, &transaction, entries); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPostingFailed, err)
 	}
 
 	if transactionService.AuditLogger != nil {
-		transactionService.AuditLogger.LogAction("Transaction Completed", fmt.Sprintf("Transaction from Account %d to Account %d for Amount: %s",
-			transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount.String()))
+		transactionService.AuditLogger.LogActionWithContext(ctx, "Transaction Completed", fmt.Sprintf("Transaction from Account %d to Account %d for Amount: %s, Fee: %s",
+			transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount.String(), transaction.FeeAmount.String()))
 	}
 
-	return nil
+	return &transaction, nil
 }
 
-// updateAccountBalanceWithContext updates the account balance with retry and context
-func (transactionService *TransactionService) updateAccountBalanceWithContext(ctx context.Context, account *model.Account, amount decimal.Decimal) error {
-	account.Balance = account.Balance.Add(amount)
-	if err := transactionService.AccountRepo.UpdateAccountBalanceWithContext(ctx, account.AccountID, account.Balance); err != nil {
-		return fmt.Errorf("failed to update account balance for Account ID %d: %v", account.AccountID, err)
+// Page sizes of ListTransactions
+const (
+	DefaultTransactionPageSize = 100
+	MaxTransactionPageSize     = 500
+)
+
+// Transactions a stream subscriber may fall behind by before it is dropped with ErrFeedLagged
+const transactionStreamBuffer = 256
+
+// Retrieves a page of the transactions touching an account (every account when zero) with an ID above afterID.
+// limit defaults to DefaultTransactionPageSize and is capped at MaxTransactionPageSize.
+func (transactionService *TransactionService) ListTransactions(ctx context.Context, accountID int, afterID int, limit int) ([]model.Transaction, error) {
+	if limit <= 0 {
+		limit = DefaultTransactionPageSize
+	}
+	limit = min(limit, MaxTransactionPageSize)
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	return transactionService.TransactionRepo.ListTransactionsWithContext(ctx, accountID, afterID, limit)
+}
+
+// Sends the transactions touching an account (every account when zero) as they are posted, first replaying the
+// stored ones with an ID above afterID when it is not zero. replayed, when not nil, is called once between the replay
+// and the first new transaction. Runs until ctx ends, a callback fails or the feed drops the subscription, returning
+// ErrFeedLagged or ErrFeedClosed in the latter case.
+func (transactionService *TransactionService) StreamTransactions(ctx context.Context, accountID int, afterID int,
+	send func(model.Transaction) error, replayed func() error) error {
+	if transactionService.Feed == nil {
+		return ErrFeedClosed
+	}
+	// Subscribe before replaying so nothing posted meanwhile is missed, transactions seen in both are sent once
+	subscription := transactionService.Feed.Subscribe(transactionStreamBuffer)
+	defer subscription.Close()
+	// A transaction committed after subscribing was created at most one operation timeout earlier
+	overlapStart := time.Now().Add(-OperationTimeout - time.Minute)
+
+	sentDuringReplay := map[int]bool{}
+	for afterID > 0 {
+		page, err := transactionService.ListTransactions(ctx, accountID, afterID, MaxTransactionPageSize)
+		if err != nil {
+			return err
+		}
+		for _, transaction := range page {
+			if err := send(transaction); err != nil {
+				return err
+			}
+			if transaction.CreatedAt.After(overlapStart) {
+				sentDuringReplay[transaction.TransactionID] = true
+			}
+			afterID = transaction.TransactionID
+		}
+		if len(page) < MaxTransactionPageSize {
+			break
+		}
+	}
+	if replayed != nil {
+		if err := replayed(); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case transaction, open := <-subscription.Events():
+			if !open {
+				return subscription.Err()
+			}
+			if sentDuringReplay[transaction.TransactionID] {
+				delete(sentDuringReplay, transaction.TransactionID)
+				continue
+			}
+			if accountID != 0 && transaction.SourceAccountID != accountID && transaction.DestinationAccountID != accountID {
+				continue
+			}
+			if err := send(transaction); err != nil {
+				return err
+			}
+		}
 	}
-	return nil
 }
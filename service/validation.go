@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"regexp"
+
+	"github.com/shopspring/decimal"
+)
+
+// Decimal places kept for amounts and balances, the scale of the DECIMAL(15, 5) columns
+const AmountScale = 5
+
+// Amounts must stay below this, the DECIMAL(15, 5) columns keep 10 digits before the point
+var maxAmount = decimal.New(1, 15-AmountScale)
+
+// Account types are lower case words joined by underscores, e.g. standard or business_savings, fitting the
+// VARCHAR(50) column
+var accountTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// Checks that an amount can be stored without rounding: at most AmountScale decimal places and below 10^10
+func ValidateAmount(field string, amount decimal.Decimal) error {
+	if amount.Abs().GreaterThanOrEqual(maxAmount) {
+		return &common.ValidationError{Message: fmt.Sprintf("%s must be below %s", field, maxAmount.String())}
+	}
+	if !amount.Equal(amount.Truncate(AmountScale)) {
+		return &common.ValidationError{Message: fmt.Sprintf("%s must have at most %d decimal places", field, AmountScale)}
+	}
+	return nil
+}
+
+// Checks the amount of a transfer, held for approval or executed right away
+func ValidateTransferAmount(amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return &common.ValidationError{Message: "transaction amount must be greater than zero"}
+	}
+	return ValidateAmount("transaction amount", amount)
+}
+
+// Checks an account before it is created
+func ValidateAccount(account model.Account) error {
+	if account.AccountID <= 0 {
+		return &common.ValidationError{Message: "account_id must be a positive number"}
+	}
+	if account.InitialBalance.IsNegative() {
+		return &common.ValidationError{Message: "initial_balance must not be negative"}
+	}
+	if err := ValidateAmount("initial_balance", account.InitialBalance); err != nil {
+		return err
+	}
+	if !accountTypePattern.MatchString(account.AccountType) {
+		return &common.ValidationError{Message: fmt.Sprintf("account_type must match %s", accountTypePattern.String())}
+	}
+	return nil
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/metrics"
+	"internal-transfers/model"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Headers of a webhook request
+const (
+	WebhookEventIDHeader   = "X-Webhook-Event-ID"
+	WebhookEventTypeHeader = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// Events fanned out and deliveries claimed per round of a dispatch
+const webhookBatchSize = 100
+
+// Deliveries sent at the same time by a dispatch
+const webhookConcurrency = 8
+
+// Number of deliveries returned when listing them
+const webhookDeliveryListLimit = 100
+
+// Responsible for webhook endpoints and for delivering the outbox events to them
+type WebhookService struct {
+	Repo        WebhookRepository
+	AuditLogger *common.AuditLogger
+	Client      *http.Client
+	// Deadline of each delivery attempt
+	Timeout time.Duration
+	// Attempts before a delivery is moved to the dead letters
+	MaxAttempts int
+	// Wait after the first failed attempt, doubled after each further failure up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Lets endpoints use plain http and reach private, loopback and link-local addresses, for local development only
+	AllowPrivateEndpoints bool
+}
+
+func NewWebhookService(webhookRepo WebhookRepository, auditLogger *common.AuditLogger, timeout time.Duration, maxAttempts int,
+	backoff time.Duration, maxBackoff time.Duration) *WebhookService {
+	webhookService := &WebhookService{
+		Repo:        webhookRepo,
+		AuditLogger: auditLogger,
+		Timeout:     timeout,
+		MaxAttempts: maxAttempts,
+		Backoff:     backoff,
+		MaxBackoff:  maxBackoff,
+	}
+	// The address is checked when connecting, after the endpoint's host was resolved, so a name that starts resolving
+	// to an internal address after registration is still refused. Redirects are not followed and count as a failure.
+	dialer := &net.Dialer{Timeout: timeout, Control: webhookService.checkDialAddress}
+	webhookService.Client = &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout, MaxIdleConnsPerHost: webhookConcurrency},
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return webhookService
+}
+
+// Shared address space of carrier-grade NAT, not covered by netip.Addr.IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Reports whether webhooks may not be sent to the address: loopback, private, link-local, shared, multicast or unspecified
+func internalAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr)
+}
+
+// Refuses connections to internal addresses unless AllowPrivateEndpoints is set
+func (webhookService *WebhookService) checkDialAddress(network string, address string, conn syscall.RawConn) error {
+	if webhookService.AllowPrivateEndpoints {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("could not parse webhook address %s: %w", address, err)
+	}
+	if internalAddress(addrPort.Addr()) {
+		return fmt.Errorf("webhook address %s is internal", addrPort.Addr())
+	}
+	return nil
+}
+
+// Checks an endpoint URL: absolute, https unless AllowPrivateEndpoints, and not naming or resolving to an internal address
+func (webhookService *WebhookService) validateEndpointURL(ctx context.Context, rawURL string) (*url.URL, error) {
+	endpointURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || endpointURL.Host == "" {
+		return nil, &common.ValidationError{Message: "url must be an absolute http or https URL"}
+	}
+	if webhookService.AllowPrivateEndpoints {
+		return endpointURL, nil
+	}
+	if endpointURL.Scheme != "https" {
+		return nil, &common.ValidationError{Message: "url must use https"}
+	}
+
+	host := endpointURL.Hostname()
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return nil, &common.ValidationError{Message: "url must not point to an internal address"}
+	} else if resolved, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host); err == nil {
+		// A host that does not resolve yet is accepted, the address is checked again on every delivery
+		addrs = resolved
+	}
+	if slices.ContainsFunc(addrs, internalAddress) {
+		return nil, &common.ValidationError{Message: "url must not point to an internal address"}
+	}
+	return endpointURL, nil
+}
+
+// Computes the hex encoded signature of a webhook request: HMAC-SHA256 with the endpoint's secret of the timestamp
+// and the hex SHA-256 of the body, separated by a newline. Receivers recompute it to authenticate the request.
+func SignWebhook(secret string, timestamp string, body []byte) string {
+	digest := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + hex.EncodeToString(digest[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Registers an endpoint for the given event types, every type when none are given. The returned secret is the only
+// time it is shown.
+func (webhookService *WebhookService) CreateEndpoint(ctx context.Context, input model.CreateWebhookEndpointInput, createdBy string) (*model.CreatedWebhookEndpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	endpointURL, err := webhookService.validateEndpointURL(ctx, input.URL)
+	if err != nil {
+		return nil, err
+	}
+	eventTypes := []string{}
+	for _, eventType := range input.EventTypes {
+		if !slices.Contains(model.EventTypes, eventType) {
+			return nil, &common.ValidationError{Message: fmt.Sprintf("event_types must be among %s", strings.Join(model.EventTypes, ", "))}
+		}
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("could not generate webhook secret: %w", err)
+	}
+
+	created := &model.CreatedWebhookEndpoint{
+		WebhookEndpoint: model.WebhookEndpoint{
+			URL:        endpointURL.String(),
+			Secret:     "whsec_" + hex.EncodeToString(secret),
+			EventTypes: eventTypes,
+			CreatedBy:  createdBy,
+		},
+	}
+	if err := webhookService.Repo.CreateEndpointWithContext(ctx, &created.WebhookEndpoint); err != nil {
+		return nil, err
+	}
+	created.Secret = created.WebhookEndpoint.Secret
+
+	if webhookService.AuditLogger != nil {
+		webhookService.AuditLogger.LogActionWithContext(ctx, "Webhook Endpoint Created", fmt.Sprintf("Endpoint ID: %d, URL: %s, Event Types: %v, Created By: %s",
+			created.EndpointID, created.URL, eventTypes, createdBy))
+	}
+	return created, nil
+}
+
+// Retrieves all endpoints without their secrets
+func (webhookService *WebhookService) ListEndpoints() ([]model.WebhookEndpoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return webhookService.Repo.ListEndpointsWithContext(ctx)
+}
+
+// Stops deliveries to an endpoint, returning false when no active endpoint has the given ID. Its pending deliveries
+// stay queued and are not attempted.
+func (webhookService *WebhookService) DisableEndpoint(ctx context.Context, endpointID int, disabledBy string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	disabled, err := webhookService.Repo.DisableEndpointWithContext(ctx, endpointID)
+	if err != nil || !disabled {
+		return false, err
+	}
+
+	if webhookService.AuditLogger != nil {
+		webhookService.AuditLogger.LogActionWithContext(ctx, "Webhook Endpoint Disabled", fmt.Sprintf("Endpoint ID: %d, Disabled By: %s", endpointID, disabledBy))
+	}
+	return true, nil
+}
+
+// Retrieves the latest deliveries with the given status (pending, delivered or dead), of every status when empty
+func (webhookService *WebhookService) ListDeliveries(status string) ([]model.WebhookDelivery, error) {
+	if status != "" && !slices.Contains([]string{model.WebhookDeliveryPending, model.WebhookDeliveryDelivered, model.WebhookDeliveryDead}, status) {
+		return nil, &common.ValidationError{Message: "status must be pending, delivered or dead"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+
+	return webhookService.Repo.ListDeliveriesWithContext(ctx, status, webhookDeliveryListLimit)
+}
+
+// Sends a delivery again at the next dispatch with a fresh set of attempts, typically one from the dead letters.
+// Returns nil when the delivery does not exist.
+func (webhookService *WebhookService) ReplayDelivery(ctx context.Context, deliveryID int64, replayedBy string) (*model.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	delivery, err := webhookService.Repo.ReplayDeliveryWithContext(ctx, deliveryID)
+	if err != nil || delivery == nil {
+		return nil, err
+	}
+
+	if webhookService.AuditLogger != nil {
+		webhookService.AuditLogger.LogActionWithContext(ctx, "Webhook Delivery Replayed", fmt.Sprintf("Delivery ID: %d, Event ID: %d, Endpoint ID: %d, Replayed By: %s",
+			delivery.DeliveryID, delivery.EventID, delivery.EndpointID, replayedBy))
+	}
+	return delivery, nil
+}
+
+// Sends the events in a range to an endpoint again at the next dispatch, e.g. after it was down or when it was added
+// after the events. Returns the number of deliveries queued, and false when no active endpoint has the given ID.
+func (webhookService *WebhookService) ReplayEvents(ctx context.Context, endpointID int, input model.ReplayWebhookEventsInput, replayedBy string) (int, bool, error) {
+	if input.FromEventID < 1 {
+		return 0, false, &common.ValidationError{Message: "from_event_id must be a positive event ID"}
+	}
+	if input.ToEventID != 0 && input.ToEventID < input.FromEventID {
+		return 0, false, &common.ValidationError{Message: "to_event_id must not be lower than from_event_id"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	endpoints, err := webhookService.Repo.ListEndpointsWithContext(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	if !slices.ContainsFunc(endpoints, func(endpoint model.WebhookEndpoint) bool {
+		return endpoint.EndpointID == endpointID && endpoint.DisabledAt == nil
+	}) {
+		return 0, false, nil
+	}
+	queued, err := webhookService.Repo.ReplayEventsWithContext(ctx, endpointID, input.FromEventID, input.ToEventID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if webhookService.AuditLogger != nil {
+		webhookService.AuditLogger.LogActionWithContext(ctx, "Webhook Events Replayed", fmt.Sprintf("Endpoint ID: %d, From Event ID: %d, To Event ID: %d, Queued: %d, Replayed By: %s",
+			endpointID, input.FromEventID, input.ToEventID, queued, replayedBy))
+	}
+	return queued, true, nil
+}
+
+// Creates the deliveries of new outbox events, then sends the deliveries that are due until none are left or ctx
+// ends. Failed attempts are retried with exponential backoff and moved to the dead letters after MaxAttempts.
+// Run by the webhook-dispatch job; several servers may dispatch at once, each delivery is claimed by one of them.
+func (webhookService *WebhookService) Dispatch(ctx context.Context) error {
+	for {
+		fanOutCtx, cancel := context.WithTimeout(ctx, OperationTimeout)
+		dispatched, err := webhookService.Repo.FanOutEventsWithContext(fanOutCtx, webhookBatchSize)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if dispatched < webhookBatchSize {
+			break
+		}
+	}
+
+	// A claim outlasts the attempt and the recording of its result
+	lease := webhookService.Timeout + OperationTimeout
+	for ctx.Err() == nil {
+		claimCtx, cancel := context.WithTimeout(ctx, OperationTimeout)
+		due, err := webhookService.Repo.ClaimDueDeliveriesWithContext(claimCtx, webhookBatchSize, lease)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		slots := make(chan struct{}, webhookConcurrency)
+		var wg sync.WaitGroup
+		for _, delivery := range due {
+			slots <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				webhookService.deliver(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(due) < webhookBatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// Attempts a delivery and records the outcome. The result is recorded even when ctx ends mid-attempt, so the
+// delivery is retried rather than left claimed until its lease runs out.
+func (webhookService *WebhookService) deliver(ctx context.Context, delivery model.DueWebhookDelivery) {
+	statusCode, err := webhookService.send(ctx, delivery)
+
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), OperationTimeout)
+	defer cancel()
+	if err == nil {
+		metrics.WebhookDeliveries.Inc(delivery.EventType, "delivered")
+		if err := webhookService.Repo.MarkDeliveredWithContext(recordCtx, delivery.DeliveryID, *statusCode); err != nil {
+			common.LogErrorWithContext(recordCtx, "Error recording webhook delivery", "delivery_id", delivery.DeliveryID, "error", err)
+		}
+		return
+	}
+
+	dead := delivery.Attempts >= webhookService.MaxAttempts
+	retryAfter := webhookService.backoff(delivery.Attempts)
+	outcome := "retry"
+	if dead {
+		outcome = "dead"
+	}
+	metrics.WebhookDeliveries.Inc(delivery.EventType, outcome)
+	slog.WarnContext(ctx, "webhook delivery failed", "delivery_id", delivery.DeliveryID, "event_id", delivery.EventID,
+		"endpoint_id", delivery.EndpointID, "attempt", delivery.Attempts, "dead", dead, "error", err)
+	if err := webhookService.Repo.MarkFailedWithContext(recordCtx, delivery.DeliveryID, statusCode, err.Error(), retryAfter, dead); err != nil {
+		common.LogErrorWithContext(recordCtx, "Error recording webhook delivery", "delivery_id", delivery.DeliveryID, "error", err)
+	}
+}
+
+// Posts the event to the endpoint, any 2xx response counts as delivered. Returns the response's status code, nil
+// when no response was received.
+func (webhookService *WebhookService) send(ctx context.Context, delivery model.DueWebhookDelivery) (*int, error) {
+	body, err := json.Marshal(delivery.OutboxEvent)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode event: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookService.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "internal-transfers-webhooks")
+	request.Header.Set(WebhookEventIDHeader, strconv.FormatInt(delivery.EventID, 10))
+	request.Header.Set(WebhookEventTypeHeader, delivery.EventType)
+	request.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.DeliveryID, 10))
+	request.Header.Set(WebhookTimestampHeader, timestamp)
+	request.Header.Set(WebhookSignatureHeader, SignWebhook(delivery.Secret, timestamp, body))
+
+	response, err := webhookService.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	statusCode := response.StatusCode
+	if statusCode < 200 || statusCode > 299 {
+		return &statusCode, fmt.Errorf("endpoint answered %s", response.Status)
+	}
+	return &statusCode, nil
+}
+
+// Wait before the attempt following the given number of failed ones
+func (webhookService *WebhookService) backoff(failedAttempts int) time.Duration {
+	wait := webhookService.Backoff
+	for i := 1; i < failedAttempts && wait < webhookService.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, webhookService.MaxBackoff)
+}
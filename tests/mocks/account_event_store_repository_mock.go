@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+	"time"
+)
+
+type MockAccountEventStoreRepository struct {
+	MockListEventsWithContext                 func(ctx context.Context, accountID int, afterVersion int64, toVersion int64, limit int) ([]model.StoredAccountEvent, error)
+	MockGetVersionAtWithContext               func(ctx context.Context, accountID int, asOf time.Time) (int64, error)
+	MockListAccountIDsWithContext             func(ctx context.Context) ([]int, error)
+	MockGetLatestSnapshotWithContext          func(ctx context.Context, accountID int, maxVersion int64) (*model.AccountAggregateSnapshot, error)
+	MockSaveSnapshotWithContext               func(ctx context.Context, snapshot model.AccountAggregateSnapshot) error
+	MockListAccountsDueForSnapshotWithContext func(ctx context.Context, every int) ([]int, error)
+	MockProjectAccountWithContext             func(ctx context.Context, account model.Account, version int64) (bool, error)
+}
+
+func (m *MockAccountEventStoreRepository) ListEventsWithContext(ctx context.Context, accountID int, afterVersion int64, toVersion int64, limit int) ([]model.StoredAccountEvent, error) {
+	return m.MockListEventsWithContext(ctx, accountID, afterVersion, toVersion, limit)
+}
+
+func (m *MockAccountEventStoreRepository) GetVersionAtWithContext(ctx context.Context, accountID int, asOf time.Time) (int64, error) {
+	return m.MockGetVersionAtWithContext(ctx, accountID, asOf)
+}
+
+func (m *MockAccountEventStoreRepository) ListAccountIDsWithContext(ctx context.Context) ([]int, error) {
+	return m.MockListAccountIDsWithContext(ctx)
+}
+
+func (m *MockAccountEventStoreRepository) GetLatestSnapshotWithContext(ctx context.Context, accountID int, maxVersion int64) (*model.AccountAggregateSnapshot, error) {
+	return m.MockGetLatestSnapshotWithContext(ctx, accountID, maxVersion)
+}
+
+func (m *MockAccountEventStoreRepository) SaveSnapshotWithContext(ctx context.Context, snapshot model.AccountAggregateSnapshot) error {
+	return m.MockSaveSnapshotWithContext(ctx, snapshot)
+}
+
+func (m *MockAccountEventStoreRepository) ListAccountsDueForSnapshotWithContext(ctx context.Context, every int) ([]int, error) {
+	return m.MockListAccountsDueForSnapshotWithContext(ctx, every)
+}
+
+func (m *MockAccountEventStoreRepository) ProjectAccountWithContext(ctx context.Context, account model.Account, version int64) (bool, error) {
+	return m.MockProjectAccountWithContext(ctx, account, version)
+}
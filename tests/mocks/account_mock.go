@@ -3,7 +3,6 @@ package mocks
 import (
 	"internal-transfers/model"
 
-	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -23,9 +22,3 @@ func (m *MockAccountService) CreateAccount(account model.Account) error {
 	args := m.Called(account)
 	return args.Error(0)
 }
-
-// Implement the UpdateAccountBalance method (from service.AccountService)
-func (m *MockAccountService) UpdateAccountBalance(accountID int, newBalance decimal.Decimal) error {
-	args := m.Called(accountID, newBalance)
-	return args.Error(0)
-}
@@ -3,14 +3,12 @@ package mocks
 import (
 	"context"
 	"internal-transfers/model"
-
-	"github.com/shopspring/decimal"
 )
 
 type MockAccountRepository struct {
-	MockGetAccountByIDWithContext       func(ctx context.Context, accountID int) (*model.Account, error)
-	MockCreateAccountWithContext        func(ctx context.Context, account model.Account) error
-	MockUpdateAccountBalanceWithContext func(ctx context.Context, accountID int, newBalance decimal.Decimal) error
+	MockGetAccountByIDWithContext func(ctx context.Context, accountID int) (*model.Account, error)
+	MockCreateAccountWithContext  func(ctx context.Context, account model.Account) error
+	MockListAccountsWithContext   func(ctx context.Context) ([]model.Account, error)
 }
 
 func (m *MockAccountRepository) GetAccountByIDWithContext(ctx context.Context, accountID int) (*model.Account, error) {
@@ -21,6 +19,6 @@ func (m *MockAccountRepository) CreateAccountWithContext(ctx context.Context, ac
 	return m.MockCreateAccountWithContext(ctx, account)
 }
 
-func (m *MockAccountRepository) UpdateAccountBalanceWithContext(ctx context.Context, accountID int, newBalance decimal.Decimal) error {
-	return m.MockUpdateAccountBalanceWithContext(ctx, accountID, newBalance)
+func (m *MockAccountRepository) ListAccountsWithContext(ctx context.Context) ([]model.Account, error) {
+	return m.MockListAccountsWithContext(ctx)
 }
@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+)
+
+type MockAdjustmentRepository struct {
+	MockCreateAdjustmentWithContext func(ctx context.Context, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) error
+	MockListAdjustmentsWithContext  func(ctx context.Context, accountID int) ([]model.Adjustment, error)
+}
+
+func (m *MockAdjustmentRepository) CreateAdjustmentWithContext(ctx context.Context, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) error {
+	return m.MockCreateAdjustmentWithContext(ctx, adjustment, transaction, entries)
+}
+
+func (m *MockAdjustmentRepository) ListAdjustmentsWithContext(ctx context.Context, accountID int) ([]model.Adjustment, error) {
+	return m.MockListAdjustmentsWithContext(ctx, accountID)
+}
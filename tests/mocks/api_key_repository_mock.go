@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+)
+
+type MockAPIKeyRepository struct {
+	MockCreateAPIKeyWithContext    func(ctx context.Context, apiKey *model.APIKey) error
+	MockGetAPIKeyByHashWithContext func(ctx context.Context, keyHash string) (*model.APIKey, error)
+	MockListAPIKeysWithContext     func(ctx context.Context) ([]model.APIKey, error)
+	MockRevokeAPIKeyWithContext    func(ctx context.Context, keyID int) (bool, error)
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKeyWithContext(ctx context.Context, apiKey *model.APIKey) error {
+	return m.MockCreateAPIKeyWithContext(ctx, apiKey)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByHashWithContext(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	return m.MockGetAPIKeyByHashWithContext(ctx, keyHash)
+}
+
+func (m *MockAPIKeyRepository) ListAPIKeysWithContext(ctx context.Context) ([]model.APIKey, error) {
+	return m.MockListAPIKeysWithContext(ctx)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKeyWithContext(ctx context.Context, keyID int) (bool, error) {
+	return m.MockRevokeAPIKeyWithContext(ctx, keyID)
+}
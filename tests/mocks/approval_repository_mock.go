@@ -0,0 +1,55 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+	"time"
+)
+
+type MockApprovalRepository struct {
+	MockCreateOperationWithContext     func(ctx context.Context, operation *model.PendingOperation) error
+	MockGetOperationWithContext        func(ctx context.Context, operationID int) (*model.PendingOperation, error)
+	MockListOperationsWithContext      func(ctx context.Context, status string, limit int) ([]model.PendingOperation, error)
+	MockDecideOperationWithContext     func(ctx context.Context, operationID int, status string, decidedBy string, reason *string, decidedAt time.Time) (bool, error)
+	MockCompleteOperationWithContext   func(ctx context.Context, operationID int, status string, transactionID *int, reason *string) error
+	MockExecuteTransferWithContext     func(ctx context.Context, operationID int, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error)
+	MockExecuteAdjustmentWithContext   func(ctx context.Context, operationID int, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error)
+	MockFailStaleOperationsWithContext func(ctx context.Context, approvedBefore time.Time, reason string) ([]model.PendingOperation, error)
+	MockExpireOperationsWithContext    func(ctx context.Context, now time.Time) ([]model.PendingOperation, error)
+}
+
+func (m *MockApprovalRepository) CreateOperationWithContext(ctx context.Context, operation *model.PendingOperation) error {
+	return m.MockCreateOperationWithContext(ctx, operation)
+}
+
+func (m *MockApprovalRepository) GetOperationWithContext(ctx context.Context, operationID int) (*model.PendingOperation, error) {
+	return m.MockGetOperationWithContext(ctx, operationID)
+}
+
+func (m *MockApprovalRepository) ListOperationsWithContext(ctx context.Context, status string, limit int) ([]model.PendingOperation, error) {
+	return m.MockListOperationsWithContext(ctx, status, limit)
+}
+
+func (m *MockApprovalRepository) DecideOperationWithContext(ctx context.Context, operationID int, status string, decidedBy string, reason *string, decidedAt time.Time) (bool, error) {
+	return m.MockDecideOperationWithContext(ctx, operationID, status, decidedBy, reason, decidedAt)
+}
+
+func (m *MockApprovalRepository) CompleteOperationWithContext(ctx context.Context, operationID int, status string, transactionID *int, reason *string) error {
+	return m.MockCompleteOperationWithContext(ctx, operationID, status, transactionID, reason)
+}
+
+func (m *MockApprovalRepository) ExecuteTransferWithContext(ctx context.Context, operationID int, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+	return m.MockExecuteTransferWithContext(ctx, operationID, transaction, entries)
+}
+
+func (m *MockApprovalRepository) ExecuteAdjustmentWithContext(ctx context.Context, operationID int, adjustment *model.Adjustment, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+	return m.MockExecuteAdjustmentWithContext(ctx, operationID, adjustment, transaction, entries)
+}
+
+func (m *MockApprovalRepository) FailStaleOperationsWithContext(ctx context.Context, approvedBefore time.Time, reason string) ([]model.PendingOperation, error) {
+	return m.MockFailStaleOperationsWithContext(ctx, approvedBefore, reason)
+}
+
+func (m *MockApprovalRepository) ExpireOperationsWithContext(ctx context.Context, now time.Time) ([]model.PendingOperation, error) {
+	return m.MockExpireOperationsWithContext(ctx, now)
+}
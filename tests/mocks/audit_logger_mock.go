@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+)
+
+// MockAuditLogger is a mock implementation of the common.Auditor interface that records every action
+type MockAuditLogger struct {
+	mu      sync.Mutex
+	Actions []string
+}
+
+func (m *MockAuditLogger) LogAction(action string, details string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Actions = append(m.Actions, action)
+}
+
+func (m *MockAuditLogger) LogActionWithContext(ctx context.Context, action string, details string) {
+	m.LogAction(action, details)
+}
@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+	"time"
+)
+
+type MockBalanceSnapshotRepository struct {
+	MockGetLatestSnapshotBeforeWithContext func(ctx context.Context, accountID int, before time.Time) (*model.BalanceSnapshot, error)
+	MockSaveSnapshotsWithContext           func(ctx context.Context, snapshots []model.BalanceSnapshot) error
+}
+
+func (m *MockBalanceSnapshotRepository) GetLatestSnapshotBeforeWithContext(ctx context.Context, accountID int, before time.Time) (*model.BalanceSnapshot, error) {
+	return m.MockGetLatestSnapshotBeforeWithContext(ctx, accountID, before)
+}
+
+func (m *MockBalanceSnapshotRepository) SaveSnapshotsWithContext(ctx context.Context, snapshots []model.BalanceSnapshot) error {
+	return m.MockSaveSnapshotsWithContext(ctx, snapshots)
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+	"time"
+)
+
+type MockFeeScheduleRepository struct {
+	MockListFeeSchedulesWithContext          func(ctx context.Context) ([]model.FeeSchedule, error)
+	MockListEffectiveFeeSchedulesWithContext func(ctx context.Context, asOf time.Time) ([]model.FeeSchedule, error)
+	MockGetFeeScheduleByIDWithContext        func(ctx context.Context, feeScheduleID int) (*model.FeeSchedule, error)
+	MockCreateFeeScheduleWithContext         func(ctx context.Context, schedule *model.FeeSchedule) error
+}
+
+func (m *MockFeeScheduleRepository) ListFeeSchedulesWithContext(ctx context.Context) ([]model.FeeSchedule, error) {
+	return m.MockListFeeSchedulesWithContext(ctx)
+}
+
+func (m *MockFeeScheduleRepository) ListEffectiveFeeSchedulesWithContext(ctx context.Context, asOf time.Time) ([]model.FeeSchedule, error) {
+	return m.MockListEffectiveFeeSchedulesWithContext(ctx, asOf)
+}
+
+func (m *MockFeeScheduleRepository) GetFeeScheduleByIDWithContext(ctx context.Context, feeScheduleID int) (*model.FeeSchedule, error) {
+	return m.MockGetFeeScheduleByIDWithContext(ctx, feeScheduleID)
+}
+
+func (m *MockFeeScheduleRepository) CreateFeeScheduleWithContext(ctx context.Context, schedule *model.FeeSchedule) error {
+	return m.MockCreateFeeScheduleWithContext(ctx, schedule)
+}
@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+	"time"
+)
+
+type MockInterestRepository struct {
+	MockGetInterestConfigWithContext          func(ctx context.Context, accountID int) (*model.InterestConfig, error)
+	MockListEnabledInterestConfigsWithContext func(ctx context.Context) ([]model.InterestConfig, error)
+	MockSaveInterestConfigWithContext         func(ctx context.Context, config *model.InterestConfig) error
+	MockGetLastAccrualDateWithContext         func(ctx context.Context, accountID int) (*time.Time, error)
+	MockCreateAccrualWithContext              func(ctx context.Context, accrual model.InterestAccrual) error
+	MockListAccrualsWithContext               func(ctx context.Context, accountID int, from, to time.Time) ([]model.InterestAccrual, error)
+	MockListUnpostedInterestWithContext       func(ctx context.Context) ([]model.UnpostedInterest, error)
+	MockPostInterestWithContext               func(ctx context.Context, pending model.UnpostedInterest, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error)
+}
+
+func (m *MockInterestRepository) GetInterestConfigWithContext(ctx context.Context, accountID int) (*model.InterestConfig, error) {
+	return m.MockGetInterestConfigWithContext(ctx, accountID)
+}
+
+func (m *MockInterestRepository) ListEnabledInterestConfigsWithContext(ctx context.Context) ([]model.InterestConfig, error) {
+	return m.MockListEnabledInterestConfigsWithContext(ctx)
+}
+
+func (m *MockInterestRepository) SaveInterestConfigWithContext(ctx context.Context, config *model.InterestConfig) error {
+	return m.MockSaveInterestConfigWithContext(ctx, config)
+}
+
+func (m *MockInterestRepository) GetLastAccrualDateWithContext(ctx context.Context, accountID int) (*time.Time, error) {
+	return m.MockGetLastAccrualDateWithContext(ctx, accountID)
+}
+
+func (m *MockInterestRepository) CreateAccrualWithContext(ctx context.Context, accrual model.InterestAccrual) error {
+	return m.MockCreateAccrualWithContext(ctx, accrual)
+}
+
+func (m *MockInterestRepository) ListAccrualsWithContext(ctx context.Context, accountID int, from, to time.Time) ([]model.InterestAccrual, error) {
+	return m.MockListAccrualsWithContext(ctx, accountID, from, to)
+}
+
+func (m *MockInterestRepository) ListUnpostedInterestWithContext(ctx context.Context) ([]model.UnpostedInterest, error) {
+	return m.MockListUnpostedInterestWithContext(ctx)
+}
+
+func (m *MockInterestRepository) PostInterestWithContext(ctx context.Context, pending model.UnpostedInterest, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+	return m.MockPostInterestWithContext(ctx, pending, transaction, entries)
+}
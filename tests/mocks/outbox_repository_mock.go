@@ -0,0 +1,13 @@
+package mocks
+
+import (
+	"context"
+)
+
+type MockOutboxRepository struct {
+	MockAddEventWithContext func(ctx context.Context, eventType string, payload any) error
+}
+
+func (m *MockOutboxRepository) AddEventWithContext(ctx context.Context, eventType string, payload any) error {
+	return m.MockAddEventWithContext(ctx, eventType, payload)
+}
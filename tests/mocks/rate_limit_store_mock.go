@@ -0,0 +1,14 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/ratelimit"
+)
+
+type MockRateLimitStore struct {
+	MockTake func(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error)
+}
+
+func (m *MockRateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+	return m.MockTake(ctx, key, limit)
+}
@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+)
+
+type MockReconciliationRepository struct {
+	MockListAccountReconciliationsWithContext func(ctx context.Context) ([]model.AccountReconciliation, error)
+	MockGetAccountReconciliationWithContext   func(ctx context.Context, accountID int) (*model.AccountReconciliation, error)
+	MockSaveRunWithContext                    func(ctx context.Context, run *model.ReconciliationRun) error
+	MockListRunsWithContext                   func(ctx context.Context, limit int) ([]model.ReconciliationRun, error)
+	MockGetRunWithContext                     func(ctx context.Context, runID int) (*model.ReconciliationRun, error)
+	MockGetMismatchWithContext                func(ctx context.Context, runID int, mismatchID int) (*model.ReconciliationMismatch, error)
+	MockCorrectMismatchWithContext            func(ctx context.Context, mismatchID int, approvedBy string, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error)
+}
+
+func (m *MockReconciliationRepository) ListAccountReconciliationsWithContext(ctx context.Context) ([]model.AccountReconciliation, error) {
+	return m.MockListAccountReconciliationsWithContext(ctx)
+}
+
+func (m *MockReconciliationRepository) GetAccountReconciliationWithContext(ctx context.Context, accountID int) (*model.AccountReconciliation, error) {
+	return m.MockGetAccountReconciliationWithContext(ctx, accountID)
+}
+
+func (m *MockReconciliationRepository) SaveRunWithContext(ctx context.Context, run *model.ReconciliationRun) error {
+	return m.MockSaveRunWithContext(ctx, run)
+}
+
+func (m *MockReconciliationRepository) ListRunsWithContext(ctx context.Context, limit int) ([]model.ReconciliationRun, error) {
+	return m.MockListRunsWithContext(ctx, limit)
+}
+
+func (m *MockReconciliationRepository) GetRunWithContext(ctx context.Context, runID int) (*model.ReconciliationRun, error) {
+	return m.MockGetRunWithContext(ctx, runID)
+}
+
+func (m *MockReconciliationRepository) GetMismatchWithContext(ctx context.Context, runID int, mismatchID int) (*model.ReconciliationMismatch, error) {
+	return m.MockGetMismatchWithContext(ctx, runID, mismatchID)
+}
+
+func (m *MockReconciliationRepository) CorrectMismatchWithContext(ctx context.Context, mismatchID int, approvedBy string, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+	return m.MockCorrectMismatchWithContext(ctx, mismatchID, approvedBy, transaction, entries)
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type MockTransactionRepository struct {
+	MockPostTransactionWithContext         func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error
+	MockSumLedgerEntriesSinceWithContext   func(ctx context.Context, accountID int, since time.Time) (decimal.Decimal, error)
+	MockSumLedgerEntriesBetweenWithContext func(ctx context.Context, accountID int, from, to time.Time) (decimal.Decimal, error)
+	MockSumDailyLedgerEntriesWithContext   func(ctx context.Context, accountID int, from, to time.Time) (map[time.Time]decimal.Decimal, error)
+	MockListStatementLinesWithContext      func(ctx context.Context, accountID int, from, to time.Time) ([]model.StatementLine, error)
+	MockListTransactionsWithContext        func(ctx context.Context, accountID int, afterID int, limit int) ([]model.Transaction, error)
+	MockListTransactionsByIDWithContext    func(ctx context.Context, transactionIDs []int) ([]model.Transaction, error)
+	MockGetLastTransactionIDWithContext    func(ctx context.Context) (int, error)
+}
+
+func (m *MockTransactionRepository) PostTransactionWithContext(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+	return m.MockPostTransactionWithContext(ctx, transaction, entries)
+}
+
+func (m *MockTransactionRepository) SumLedgerEntriesSinceWithContext(ctx context.Context, accountID int, since time.Time) (decimal.Decimal, error) {
+	return m.MockSumLedgerEntriesSinceWithContext(ctx, accountID, since)
+}
+
+func (m *MockTransactionRepository) SumLedgerEntriesBetweenWithContext(ctx context.Context, accountID int, from, to time.Time) (decimal.Decimal, error) {
+	return m.MockSumLedgerEntriesBetweenWithContext(ctx, accountID, from, to)
+}
+
+func (m *MockTransactionRepository) SumDailyLedgerEntriesWithContext(ctx context.Context, accountID int, from, to time.Time) (map[time.Time]decimal.Decimal, error) {
+	return m.MockSumDailyLedgerEntriesWithContext(ctx, accountID, from, to)
+}
+
+func (m *MockTransactionRepository) ListStatementLinesWithContext(ctx context.Context, accountID int, from, to time.Time) ([]model.StatementLine, error) {
+	return m.MockListStatementLinesWithContext(ctx, accountID, from, to)
+}
+
+func (m *MockTransactionRepository) ListTransactionsWithContext(ctx context.Context, accountID int, afterID int, limit int) ([]model.Transaction, error) {
+	return m.MockListTransactionsWithContext(ctx, accountID, afterID, limit)
+}
+
+func (m *MockTransactionRepository) ListTransactionsByIDWithContext(ctx context.Context, transactionIDs []int) ([]model.Transaction, error) {
+	return m.MockListTransactionsByIDWithContext(ctx, transactionIDs)
+}
+
+func (m *MockTransactionRepository) GetLastTransactionIDWithContext(ctx context.Context) (int, error) {
+	return m.MockGetLastTransactionIDWithContext(ctx)
+}
@@ -0,0 +1,60 @@
+package mocks
+
+import (
+	"context"
+	"internal-transfers/model"
+	"time"
+)
+
+type MockWebhookRepository struct {
+	MockCreateEndpointWithContext     func(ctx context.Context, endpoint *model.WebhookEndpoint) error
+	MockListEndpointsWithContext      func(ctx context.Context) ([]model.WebhookEndpoint, error)
+	MockDisableEndpointWithContext    func(ctx context.Context, endpointID int) (bool, error)
+	MockFanOutEventsWithContext       func(ctx context.Context, limit int) (int, error)
+	MockClaimDueDeliveriesWithContext func(ctx context.Context, limit int, lease time.Duration) ([]model.DueWebhookDelivery, error)
+	MockMarkDeliveredWithContext      func(ctx context.Context, deliveryID int64, statusCode int) error
+	MockMarkFailedWithContext         func(ctx context.Context, deliveryID int64, statusCode *int, message string, retryAfter time.Duration, dead bool) error
+	MockListDeliveriesWithContext     func(ctx context.Context, status string, limit int) ([]model.WebhookDelivery, error)
+	MockReplayDeliveryWithContext     func(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error)
+	MockReplayEventsWithContext       func(ctx context.Context, endpointID int, fromEventID int64, toEventID int64) (int, error)
+}
+
+func (m *MockWebhookRepository) CreateEndpointWithContext(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	return m.MockCreateEndpointWithContext(ctx, endpoint)
+}
+
+func (m *MockWebhookRepository) ListEndpointsWithContext(ctx context.Context) ([]model.WebhookEndpoint, error) {
+	return m.MockListEndpointsWithContext(ctx)
+}
+
+func (m *MockWebhookRepository) DisableEndpointWithContext(ctx context.Context, endpointID int) (bool, error) {
+	return m.MockDisableEndpointWithContext(ctx, endpointID)
+}
+
+func (m *MockWebhookRepository) FanOutEventsWithContext(ctx context.Context, limit int) (int, error) {
+	return m.MockFanOutEventsWithContext(ctx, limit)
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveriesWithContext(ctx context.Context, limit int, lease time.Duration) ([]model.DueWebhookDelivery, error) {
+	return m.MockClaimDueDeliveriesWithContext(ctx, limit, lease)
+}
+
+func (m *MockWebhookRepository) MarkDeliveredWithContext(ctx context.Context, deliveryID int64, statusCode int) error {
+	return m.MockMarkDeliveredWithContext(ctx, deliveryID, statusCode)
+}
+
+func (m *MockWebhookRepository) MarkFailedWithContext(ctx context.Context, deliveryID int64, statusCode *int, message string, retryAfter time.Duration, dead bool) error {
+	return m.MockMarkFailedWithContext(ctx, deliveryID, statusCode, message, retryAfter, dead)
+}
+
+func (m *MockWebhookRepository) ListDeliveriesWithContext(ctx context.Context, status string, limit int) ([]model.WebhookDelivery, error) {
+	return m.MockListDeliveriesWithContext(ctx, status, limit)
+}
+
+func (m *MockWebhookRepository) ReplayDeliveryWithContext(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error) {
+	return m.MockReplayDeliveryWithContext(ctx, deliveryID)
+}
+
+func (m *MockWebhookRepository) ReplayEventsWithContext(ctx context.Context, endpointID int, fromEventID int64, toEventID int64) (int, error) {
+	return m.MockReplayEventsWithContext(ctx, endpointID, fromEventID, toEventID)
+}
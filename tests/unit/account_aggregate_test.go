@@ -0,0 +1,194 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var openedAt = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func openedEvent(accountID int, initialBalance int64) model.StoredAccountEvent {
+	data, _ := json.Marshal(model.AccountOpened{AccountType: model.DefaultAccountType, InitialBalance: decimal.NewFromInt(initialBalance)})
+	return model.StoredAccountEvent{AccountID: accountID, Version: 1, EventType: model.AccountOpenedEvent, Data: data, CreatedAt: openedAt}
+}
+
+func balanceEvent(accountID int, version int64, amount int64) model.StoredAccountEvent {
+	eventType := model.AccountCreditedEvent
+	if amount < 0 {
+		eventType = model.AccountDebitedEvent
+	}
+	change := model.AccountBalanceChange{TransactionID: int(version), EntryType: model.TransactionTypeTransfer, Amount: decimal.NewFromInt(amount).Abs()}
+	data, _ := json.Marshal(change)
+	return model.StoredAccountEvent{AccountID: accountID, Version: version, EventType: eventType, Data: data,
+		CreatedAt: openedAt.Add(time.Duration(version) * time.Hour)}
+}
+
+// An event store holding the given events, without snapshots
+func newEventStore(events ...model.StoredAccountEvent) *mocks.MockAccountEventStoreRepository {
+	return &mocks.MockAccountEventStoreRepository{
+		MockListEventsWithContext: func(ctx context.Context, accountID int, afterVersion int64, toVersion int64, limit int) ([]model.StoredAccountEvent, error) {
+			page := []model.StoredAccountEvent{}
+			for _, event := range events {
+				if event.AccountID == accountID && event.Version > afterVersion && (toVersion == 0 || event.Version <= toVersion) && len(page) < limit {
+					page = append(page, event)
+				}
+			}
+			return page, nil
+		},
+		MockGetLatestSnapshotWithContext: func(ctx context.Context, accountID int, maxVersion int64) (*model.AccountAggregateSnapshot, error) {
+			return nil, nil
+		},
+		MockListAccountIDsWithContext: func(ctx context.Context) ([]int, error) {
+			accountIDs := []int{}
+			for _, event := range events {
+				if event.Version == 1 {
+					accountIDs = append(accountIDs, event.AccountID)
+				}
+			}
+			return accountIDs, nil
+		},
+	}
+}
+
+func TestAccountAggregate_AppliesEventsInVersionOrder(t *testing.T) {
+	aggregate := &model.AccountAggregate{}
+
+	assert.Error(t, aggregate.Apply(balanceEvent(1, 1, 10)), "an account is opened before its balance changes")
+	assert.NoError(t, aggregate.Apply(openedEvent(1, 100)))
+	assert.NoError(t, aggregate.Apply(balanceEvent(1, 2, 50)))
+	assert.NoError(t, aggregate.Apply(balanceEvent(1, 3, -30)))
+	assert.ErrorContains(t, aggregate.Apply(balanceEvent(1, 5, 10)), "expected 4", "a gap in the versions is rejected")
+
+	assert.Equal(t, "120", aggregate.Balance.String())
+	assert.Equal(t, "100", aggregate.InitialBalance.String())
+	assert.Equal(t, openedAt, aggregate.CreatedAt)
+	assert.Equal(t, int64(3), aggregate.Version)
+	assert.Equal(t, 3, aggregate.ReplayedEvents)
+}
+
+func TestLoadAccount_ReplaysFromLatestSnapshot(t *testing.T) {
+	eventStore := newEventStore(openedEvent(1, 100), balanceEvent(1, 2, 50), balanceEvent(1, 3, -30), balanceEvent(1, 4, 5))
+	eventStore.MockGetLatestSnapshotWithContext = func(ctx context.Context, accountID int, maxVersion int64) (*model.AccountAggregateSnapshot, error) {
+		return &model.AccountAggregateSnapshot{
+			Account: model.Account{AccountID: 1, AccountType: model.DefaultAccountType, Balance: decimal.NewFromInt(150),
+				InitialBalance: decimal.NewFromInt(100), CreatedAt: openedAt},
+			Version: 2,
+		}, nil
+	}
+	aggregateService := service.NewAccountAggregateService(eventStore, nil, nil, 100)
+
+	aggregate, err := aggregateService.LoadAccount(1, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "125", aggregate.Balance.String())
+	assert.Equal(t, int64(4), aggregate.Version)
+	assert.Equal(t, int64(2), aggregate.SnapshotVersion)
+	assert.Equal(t, 2, aggregate.ReplayedEvents, "only the events after the snapshot are replayed")
+
+	aggregate, err = aggregateService.LoadAccount(1, 3, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "120", aggregate.Balance.String())
+	assert.Equal(t, int64(3), aggregate.Version)
+}
+
+func TestLoadAccount_AsOfAndValidation(t *testing.T) {
+	eventStore := newEventStore(openedEvent(1, 100), balanceEvent(1, 2, 50), balanceEvent(1, 3, -30))
+	eventStore.MockGetVersionAtWithContext = func(ctx context.Context, accountID int, asOf time.Time) (int64, error) {
+		if asOf.Before(openedAt) {
+			return 0, nil
+		}
+		return 2, nil
+	}
+	aggregateService := service.NewAccountAggregateService(eventStore, nil, nil, 100)
+
+	asOf := openedAt.Add(150 * time.Minute)
+	aggregate, err := aggregateService.LoadAccount(1, 0, &asOf)
+	assert.NoError(t, err)
+	assert.Equal(t, "150", aggregate.Balance.String())
+
+	beforeOpening := openedAt.Add(-time.Hour)
+	_, err = aggregateService.LoadAccount(1, 0, &beforeOpening)
+	assert.IsType(t, &common.ValidationError{}, err)
+	_, err = aggregateService.LoadAccount(1, 9, nil)
+	assert.IsType(t, &common.ValidationError{}, err, "the account never reached version 9")
+	_, err = aggregateService.LoadAccount(1, 2, &asOf)
+	assert.IsType(t, &common.ValidationError{}, err)
+
+	aggregate, err = aggregateService.LoadAccount(2, 0, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, aggregate, "an account without events does not exist")
+}
+
+func TestSnapshotAccounts_SavesLatestState(t *testing.T) {
+	eventStore := newEventStore(openedEvent(1, 100), balanceEvent(1, 2, 50), balanceEvent(1, 3, -30))
+	eventStore.MockListAccountsDueForSnapshotWithContext = func(ctx context.Context, every int) ([]int, error) {
+		assert.Equal(t, 3, every)
+		return []int{1}, nil
+	}
+	var saved []model.AccountAggregateSnapshot
+	eventStore.MockSaveSnapshotWithContext = func(ctx context.Context, snapshot model.AccountAggregateSnapshot) error {
+		saved = append(saved, snapshot)
+		return nil
+	}
+	aggregateService := service.NewAccountAggregateService(eventStore, nil, nil, 3)
+
+	assert.NoError(t, aggregateService.SnapshotAccounts(context.Background()))
+
+	assert.Len(t, saved, 1)
+	assert.Equal(t, int64(3), saved[0].Version)
+	assert.Equal(t, "120", saved[0].Balance.String())
+}
+
+func TestRebuildProjection_RewritesRowsThatDifferFromEvents(t *testing.T) {
+	eventStore := newEventStore(
+		openedEvent(1, 100), balanceEvent(1, 2, 20),
+		openedEvent(2, 50), balanceEvent(2, 2, -10),
+	)
+	var projected []model.Account
+	refused := false
+	eventStore.MockProjectAccountWithContext = func(ctx context.Context, account model.Account, version int64) (bool, error) {
+		assert.Equal(t, int64(2), version)
+		if !refused {
+			// A transfer appended an event between the replay and the write, the account is replayed again
+			refused = true
+			return false, nil
+		}
+		projected = append(projected, account)
+		return true, nil
+	}
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, AccountType: model.DefaultAccountType, Balance: decimal.NewFromInt(120), InitialBalance: decimal.NewFromInt(100), CreatedAt: openedAt},
+		model.Account{AccountID: 2, AccountType: model.DefaultAccountType, Balance: decimal.NewFromInt(45), InitialBalance: decimal.NewFromInt(50), CreatedAt: openedAt},
+		model.Account{AccountID: 3, AccountType: model.DefaultAccountType, Balance: decimal.NewFromInt(7)},
+	)
+	accountRepo.MockListAccountsWithContext = func(ctx context.Context) ([]model.Account, error) {
+		return []model.Account{{AccountID: 1}, {AccountID: 2}, {AccountID: 3}}, nil
+	}
+	aggregateService := service.NewAccountAggregateService(eventStore, accountRepo, nil, 100)
+
+	dryRun, err := aggregateService.RebuildProjection(context.Background(), true)
+	assert.NoError(t, err)
+	assert.Empty(t, projected, "a dry run writes nothing")
+	assert.Equal(t, 2, dryRun.AccountsReplayed)
+	assert.Len(t, dryRun.Differences, 1)
+	assert.Equal(t, 2, dryRun.Differences[0].AccountID)
+	assert.Equal(t, "45", dryRun.Differences[0].Stored.Balance.String())
+	assert.Equal(t, "40", dryRun.Differences[0].Replayed.Balance.String())
+	assert.Equal(t, []int{3}, dryRun.AccountsWithoutEvents)
+
+	rebuild, err := aggregateService.RebuildProjection(context.Background(), false)
+	assert.NoError(t, err)
+	assert.True(t, refused)
+	assert.Equal(t, 1, rebuild.AccountsUpdated)
+	assert.Len(t, projected, 1)
+	assert.Equal(t, 2, projected[0].AccountID)
+	assert.Equal(t, "40", projected[0].Balance.String())
+}
@@ -0,0 +1,187 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	v1 "internal-transfers/api/v1"
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"internal-transfers/metrics"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"internal-transfers/tracing"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// Serves the account event routes behind the tracing, access log and metrics wrappers, which must let
+// streamed responses flush and WebSockets hijack the connection
+func startAccountEventServer(t *testing.T, stored []model.Transaction, accounts ...model.Account) (*httptest.Server, *service.TransactionFeed) {
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockListTransactionsWithContext: func(ctx context.Context, accountID int, afterID int, limit int) ([]model.Transaction, error) {
+			var page []model.Transaction
+			for _, transaction := range stored {
+				if transaction.TransactionID > afterID && len(page) < limit &&
+					(transaction.SourceAccountID == accountID || transaction.DestinationAccountID == accountID) {
+					page = append(page, transaction)
+				}
+			}
+			return page, nil
+		},
+	}
+	accountRepo := newAccountRepository(accounts...)
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+	feed := service.NewTransactionFeed()
+	transactionService.Feed = feed
+
+	router := mux.NewRouter()
+	router.Use(tracing.Middleware, common.AccessLogMiddleware)
+	v1.RegisterAccountEventRoutes(router, service.NewAccountEventService(accountRepo, transactionService),
+		service.NewAccountService(accountRepo, &common.AuditLogger{}), (*auth.Authorizer)(nil))
+	server := httptest.NewServer(metrics.InstrumentRouter(router))
+	t.Cleanup(server.Close)
+	t.Cleanup(feed.Close)
+	return server, feed
+}
+
+type serverSentEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// Reads the next event of a text/event-stream, skipping comments and the retry field
+func readServerSentEvent(t *testing.T, reader *bufio.Reader) serverSentEvent {
+	var event serverSentEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.event != "":
+			return event
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestAccountEvents_ServerSentEventsResumeAndFollowTheFeed(t *testing.T) {
+	now := time.Now()
+	stored := []model.Transaction{
+		{TransactionID: 4, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(4), CreatedAt: now},
+		{TransactionID: 5, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(5), FeeAmount: decimal.NewFromInt(1), CreatedAt: now},
+		{TransactionID: 6, SourceAccountID: 3, DestinationAccountID: 1, Amount: decimal.NewFromInt(6), CreatedAt: now},
+	}
+	server, feed := startAccountEventServer(t, stored, model.Account{AccountID: 1, Balance: decimal.NewFromInt(90)})
+
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/accounts/1/events", nil)
+	assert.NoError(t, err)
+	request.Header.Set("Last-Event-ID", "4")
+	response, err := http.DefaultClient.Do(request)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	reader := bufio.NewReader(response.Body)
+
+	first := readServerSentEvent(t, reader)
+	assert.Equal(t, serverSentEvent{id: "5", event: "transaction"}, serverSentEvent{id: first.id, event: first.event})
+	var event model.AccountEvent
+	assert.NoError(t, json.Unmarshal([]byte(first.data), &event))
+	assert.Equal(t, 5, event.Transaction.TransactionID)
+	assert.Equal(t, "-6", event.BalanceChange.String(), "the source pays the amount and the fee")
+
+	second := readServerSentEvent(t, reader)
+	assert.Equal(t, "6", second.id)
+	assert.NoError(t, json.Unmarshal([]byte(second.data), &event))
+	assert.Equal(t, "6", event.BalanceChange.String())
+
+	balance := readServerSentEvent(t, reader)
+	assert.Equal(t, serverSentEvent{event: "balance", data: `{"type":"balance","account_id":1,"balance":"90"}`}, balance,
+		"the replay ends with the stored balance")
+
+	assert.Eventually(t, func() bool { return feed.Subscribers() == 1 }, time.Second, 10*time.Millisecond)
+	feed.Publish(model.Transaction{TransactionID: 7, SourceAccountID: 2, DestinationAccountID: 3, CreatedAt: now})
+	feed.Publish(model.Transaction{TransactionID: 8, SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(8), CreatedAt: now})
+	live := readServerSentEvent(t, reader)
+	assert.Equal(t, "8", live.id, "other accounts' transactions are skipped")
+	assert.Equal(t, "balance", readServerSentEvent(t, reader).event)
+
+	feed.Close()
+	closed := readServerSentEvent(t, reader)
+	assert.Equal(t, "error", closed.event)
+	assert.Contains(t, closed.data, service.ErrFeedClosed.Error())
+}
+
+func TestAccountEvents_RejectsUnknownAccountsAndBadEventIDs(t *testing.T) {
+	server, _ := startAccountEventServer(t, nil, model.Account{AccountID: 1})
+
+	response, err := http.Get(server.URL + "/api/v1/accounts/9/events")
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	response, err = http.Get(server.URL + "/api/v1/accounts/1/events?last_event_id=abc")
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	_, response, err = websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/accounts/9/events/ws", nil)
+	assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestAccountEvents_WebSocketCarriesEventsAndClosesOnShutdown(t *testing.T) {
+	now := time.Now()
+	stored := []model.Transaction{
+		{TransactionID: 3, SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(3), CreatedAt: now},
+	}
+	server, feed := startAccountEventServer(t, stored, model.Account{AccountID: 1, Balance: decimal.NewFromInt(3)})
+
+	connection, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/accounts/1/events/ws?last_event_id=2", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer connection.Close()
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var event model.AccountEvent
+	assert.NoError(t, connection.ReadJSON(&event))
+	assert.Equal(t, model.AccountEventTransaction, event.Type)
+	assert.Equal(t, 3, event.EventID)
+	assert.Equal(t, "3", event.BalanceChange.String())
+	assert.NoError(t, connection.ReadJSON(&event))
+	assert.Equal(t, model.AccountEventBalance, event.Type)
+	assert.Equal(t, "3", event.Balance.String())
+
+	assert.Eventually(t, func() bool { return feed.Subscribers() == 1 }, time.Second, 10*time.Millisecond)
+	feed.Publish(model.Transaction{TransactionID: 4, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1), CreatedAt: now})
+	assert.NoError(t, connection.ReadJSON(&event))
+	assert.Equal(t, 4, event.EventID)
+	assert.Equal(t, "-1", event.BalanceChange.String())
+	assert.NoError(t, connection.ReadJSON(&event))
+	assert.Equal(t, model.AccountEventBalance, event.Type)
+
+	feed.Close()
+	_, _, err = connection.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), "got %v", err)
+}
@@ -57,3 +57,32 @@ func TestCreateAccount_AccountExists(t *testing.T) {
 
 	assert.Error(t, err, "Expected error because the account already exists")
 }
+
+func TestCreateAccount_RejectsInvalidAccounts(t *testing.T) {
+	created := false
+	mockRepo := &mocks.MockAccountRepository{
+		MockGetAccountByIDWithContext: func(ctx context.Context, accountID int) (*model.Account, error) {
+			return nil, nil
+		},
+		MockCreateAccountWithContext: func(ctx context.Context, account model.Account) error {
+			created = true
+			return nil
+		},
+	}
+	accountService := service.NewAccountService(mockRepo, &common.AuditLogger{})
+
+	invalid := map[string]model.Account{
+		"missing ID":        {InitialBalance: decimal.NewFromInt(100)},
+		"negative balance":  {AccountID: 1, InitialBalance: decimal.NewFromInt(-5)},
+		"too many decimals": {AccountID: 1, InitialBalance: decimal.RequireFromString("1.000001")},
+		"too large":         {AccountID: 1, InitialBalance: decimal.New(1, 10)},
+		"account type":      {AccountID: 1, AccountType: "Premium Plus"},
+	}
+	for name, account := range invalid {
+		err := accountService.CreateAccount(account)
+		assert.IsType(t, &common.ValidationError{}, err, name)
+	}
+	assert.False(t, created, "invalid accounts are not stored")
+
+	assert.NoError(t, accountService.CreateAccount(model.Account{AccountID: 1, AccountType: "business_savings", InitialBalance: decimal.RequireFromString("9999999999.99999")}))
+}
@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"context"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAdjustment_PostsAgainstSuspense(t *testing.T) {
+	accountRepo := newAccountRepository(model.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+
+	var posted *model.Transaction
+	var entries []model.LedgerEntry
+	adjustmentRepo := &mocks.MockAdjustmentRepository{
+		MockCreateAdjustmentWithContext: func(ctx context.Context, adjustment *model.Adjustment, transaction *model.Transaction, legs []model.LedgerEntry) error {
+			transaction.TransactionID = 11
+			adjustment.TransactionID = 11
+			adjustment.AdjustmentID = 4
+			posted, entries = transaction, legs
+			return nil
+		},
+	}
+
+	adjustmentService := service.NewAdjustmentService(adjustmentRepo, accountRepo, nil, 900)
+	adjustment, err := adjustmentService.CreateAdjustment(context.Background(), 1, model.CreateAdjustmentInput{
+		Amount: decimal.NewFromInt(-40), ReasonCode: "chargeback", Reference: "CB-2291", OperatorID: "ops-7",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, adjustment.AdjustmentID)
+	assert.Equal(t, "ops-7", adjustment.OperatorID)
+	assert.Equal(t, model.TransactionTypeAdjustment, posted.TransactionType)
+	assert.Equal(t, 1, posted.SourceAccountID)
+	assert.Equal(t, 900, posted.DestinationAccountID)
+	assert.True(t, decimal.NewFromInt(40).Equal(posted.Amount))
+	assert.Len(t, entries, 2)
+	assert.True(t, decimal.NewFromInt(-40).Equal(entries[0].Amount))
+	assert.False(t, entries[0].AllowNegative)
+	assert.True(t, entries[0].Amount.Add(entries[1].Amount).IsZero())
+	assert.True(t, entries[1].AllowNegative)
+}
+
+func TestCreateAdjustment_Validation(t *testing.T) {
+	accountRepo := newAccountRepository(model.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	adjustmentService := service.NewAdjustmentService(&mocks.MockAdjustmentRepository{}, accountRepo, nil, 900)
+	valid := model.CreateAdjustmentInput{Amount: decimal.NewFromInt(5), ReasonCode: "goodwill", Reference: "T-1", OperatorID: "ops-7"}
+
+	cases := map[string]func(input *model.CreateAdjustmentInput){
+		"zero amount":      func(input *model.CreateAdjustmentInput) { input.Amount = decimal.Zero },
+		"unknown reason":   func(input *model.CreateAdjustmentInput) { input.ReasonCode = "because" },
+		"missing ref":      func(input *model.CreateAdjustmentInput) { input.Reference = " " },
+		"missing operator": func(input *model.CreateAdjustmentInput) { input.OperatorID = "" },
+		"overdraws":        func(input *model.CreateAdjustmentInput) { input.Amount = decimal.NewFromInt(-101) },
+	}
+	for name, mutate := range cases {
+		input := valid
+		mutate(&input)
+		_, err := adjustmentService.CreateAdjustment(context.Background(), 1, input)
+		var validationError *common.ValidationError
+		assert.ErrorAs(t, err, &validationError, name)
+	}
+
+	_, err := adjustmentService.CreateAdjustment(context.Background(), 900, valid)
+	var validationError *common.ValidationError
+	assert.ErrorAs(t, err, &validationError, "suspense account")
+
+	adjustment, err := adjustmentService.CreateAdjustment(context.Background(), 2, valid)
+	assert.NoError(t, err)
+	assert.Nil(t, adjustment)
+}
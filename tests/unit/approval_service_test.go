@@ -0,0 +1,186 @@
+package unit
+
+import (
+	"context"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPendingTransfer(expiresAt time.Time) *model.PendingOperation {
+	request := model.TransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(5000)}
+	return &model.PendingOperation{
+		OperationID: 8, OperationType: model.OperationTypeTransfer, Status: model.OperationStatusPending,
+		Amount: request.Amount, Payload: &model.OperationPayload{Transfer: &request},
+		RequestedBy: "maker", ExpiresAt: expiresAt,
+	}
+}
+
+func TestRequiresApproval_Threshold(t *testing.T) {
+	approvalService := service.NewApprovalService(nil, nil, nil, nil, nil, decimal.NewFromInt(1000), time.Hour)
+	assert.False(t, approvalService.RequiresApproval(decimal.NewFromInt(1000)))
+	assert.True(t, approvalService.RequiresApproval(decimal.NewFromFloat(1000.01)))
+
+	disabled := service.NewApprovalService(nil, nil, nil, nil, nil, decimal.Zero, time.Hour)
+	assert.False(t, disabled.RequiresApproval(decimal.NewFromInt(1000000)))
+}
+
+func TestApprove_ExecutesTransferOnce(t *testing.T) {
+	operation := newPendingTransfer(time.Now().Add(time.Hour))
+	executions := 0
+	approvalRepo := &mocks.MockApprovalRepository{
+		MockGetOperationWithContext: func(ctx context.Context, operationID int) (*model.PendingOperation, error) {
+			return operation, nil
+		},
+		MockDecideOperationWithContext: func(ctx context.Context, operationID int, status string, decidedBy string, reason *string, decidedAt time.Time) (bool, error) {
+			assert.Equal(t, model.OperationStatusApproved, status)
+			assert.Equal(t, "checker", decidedBy)
+			return true, nil
+		},
+		MockExecuteTransferWithContext: func(ctx context.Context, operationID int, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+			assert.Equal(t, 8, operationID)
+			assert.Len(t, entries, 2)
+			executions++
+			transaction.TransactionID = 77
+			return true, nil
+		},
+	}
+
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, Balance: decimal.NewFromInt(10000)},
+		model.Account{AccountID: 2, Balance: decimal.Zero},
+	)
+	transactionService := service.NewTransactionService(accountRepo, &mocks.MockTransactionRepository{}, nil)
+	approvalService := service.NewApprovalService(approvalRepo, accountRepo, transactionService, nil, nil, decimal.NewFromInt(1000), time.Hour)
+
+	_, err := approvalService.Approve(context.Background(), 8, "checker")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, executions)
+}
+
+func TestApprove_RecordsFailureWhenOperationIsNoLongerApproved(t *testing.T) {
+	operation := newPendingTransfer(time.Now().Add(time.Hour))
+	var completedStatus string
+	var completedReason *string
+	approvalRepo := &mocks.MockApprovalRepository{
+		MockGetOperationWithContext: func(ctx context.Context, operationID int) (*model.PendingOperation, error) {
+			return operation, nil
+		},
+		MockDecideOperationWithContext: func(ctx context.Context, operationID int, status string, decidedBy string, reason *string, decidedAt time.Time) (bool, error) {
+			return true, nil
+		},
+		MockExecuteTransferWithContext: func(ctx context.Context, operationID int, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+			return false, nil
+		},
+		MockCompleteOperationWithContext: func(ctx context.Context, operationID int, status string, transactionID *int, reason *string) error {
+			completedStatus, completedReason = status, reason
+			assert.Nil(t, transactionID)
+			return nil
+		},
+	}
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, Balance: decimal.NewFromInt(10000)},
+		model.Account{AccountID: 2, Balance: decimal.Zero},
+	)
+	transactionService := service.NewTransactionService(accountRepo, &mocks.MockTransactionRepository{}, nil)
+	approvalService := service.NewApprovalService(approvalRepo, accountRepo, transactionService, nil, nil, decimal.NewFromInt(1000), time.Hour)
+
+	_, err := approvalService.Approve(context.Background(), 8, "checker")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.OperationStatusFailed, completedStatus)
+	assert.Contains(t, *completedReason, "no longer approved")
+}
+
+func TestExpireOperations_FailsOperationsLeftApproved(t *testing.T) {
+	stale := *newPendingTransfer(time.Now().Add(time.Hour))
+	stale.Status = model.OperationStatusFailed
+	var approvedBefore time.Time
+	approvalRepo := &mocks.MockApprovalRepository{
+		MockExpireOperationsWithContext: func(ctx context.Context, now time.Time) ([]model.PendingOperation, error) {
+			return nil, nil
+		},
+		MockFailStaleOperationsWithContext: func(ctx context.Context, before time.Time, reason string) ([]model.PendingOperation, error) {
+			approvedBefore = before
+			assert.NotEmpty(t, reason)
+			return []model.PendingOperation{stale}, nil
+		},
+	}
+	approvalService := service.NewApprovalService(approvalRepo, nil, nil, nil, nil, decimal.NewFromInt(1000), time.Hour)
+
+	err := approvalService.ExpireOperations(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, approvedBefore.Before(time.Now().Add(-5*time.Minute)))
+}
+
+func TestApprove_RequiresDifferentOperatorAndLiveOperation(t *testing.T) {
+	operation := newPendingTransfer(time.Now().Add(time.Hour))
+	approvalRepo := &mocks.MockApprovalRepository{
+		MockGetOperationWithContext: func(ctx context.Context, operationID int) (*model.PendingOperation, error) {
+			return operation, nil
+		},
+	}
+	approvalService := service.NewApprovalService(approvalRepo, nil, nil, nil, nil, decimal.NewFromInt(1000), time.Hour)
+	var validationError *common.ValidationError
+
+	_, err := approvalService.Approve(context.Background(), 8, "maker")
+	assert.ErrorAs(t, err, &validationError)
+
+	_, err = approvalService.Reject(context.Background(), 8, "maker", "duplicate")
+	assert.ErrorAs(t, err, &validationError)
+
+	operation.ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = approvalService.Approve(context.Background(), 8, "checker")
+	assert.ErrorAs(t, err, &validationError)
+
+	operation.ExpiresAt = time.Now().Add(time.Hour)
+	operation.Status = model.OperationStatusRejected
+	_, err = approvalService.Approve(context.Background(), 8, "checker")
+	assert.ErrorAs(t, err, &validationError)
+}
+
+func TestSubmitTransfer_RequiresRequester(t *testing.T) {
+	accountRepo := newAccountRepository(model.Account{AccountID: 1}, model.Account{AccountID: 2})
+	var created *model.PendingOperation
+	approvalRepo := &mocks.MockApprovalRepository{
+		MockCreateOperationWithContext: func(ctx context.Context, operation *model.PendingOperation) error {
+			created = operation
+			return nil
+		},
+	}
+	approvalService := service.NewApprovalService(approvalRepo, accountRepo, nil, nil, nil, decimal.NewFromInt(1000), 2*time.Hour)
+	request := model.TransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(5000)}
+
+	_, err := approvalService.SubmitTransfer(context.Background(), request, "")
+	var validationError *common.ValidationError
+	assert.ErrorAs(t, err, &validationError)
+
+	operation, err := approvalService.SubmitTransfer(context.Background(), request, "maker")
+	assert.NoError(t, err)
+	assert.Same(t, created, operation)
+	assert.Equal(t, model.OperationStatusPending, operation.Status)
+	assert.Equal(t, 2*time.Hour, operation.ExpiresAt.Sub(operation.RequestedAt))
+}
+
+func TestSubmitTransfer_ValidatesAmountsLikeTransfers(t *testing.T) {
+	accountRepo := newAccountRepository(model.Account{AccountID: 1, Balance: decimal.NewFromInt(50)}, model.Account{AccountID: 2})
+	transactionService := service.NewTransactionService(accountRepo, &mocks.MockTransactionRepository{}, &common.AuditLogger{})
+	approvalService := service.NewApprovalService(&mocks.MockApprovalRepository{}, accountRepo, transactionService, nil, nil, decimal.NewFromInt(1000), time.Hour)
+
+	for _, amount := range []string{"0", "-5", "0.000001"} {
+		_, submitErr := approvalService.SubmitTransfer(context.Background(),
+			model.TransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString(amount)}, "maker")
+		_, transferErr := transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.RequireFromString(amount)))
+
+		assert.IsType(t, &common.ValidationError{}, submitErr, amount)
+		assert.Equal(t, transferErr, submitErr, amount)
+	}
+}
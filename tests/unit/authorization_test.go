@@ -0,0 +1,73 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"internal-transfers/auth"
+	"internal-transfers/controller"
+	"internal-transfers/model"
+	"internal-transfers/tests/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// Serves a request as the principal through a router with the account and transaction routes
+func serveAs(router *mux.Router, principal *auth.Principal, method string, path string, body []byte) int {
+	request := httptest.NewRequest(method, path, bytes.NewReader(body))
+	request = request.WithContext(auth.WithPrincipal(request.Context(), principal))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func TestPrincipal_RolesAndScope(t *testing.T) {
+	viewer := &auth.Principal{Roles: []string{auth.RoleViewer}, AccountIDs: []int{1}}
+	assert.True(t, viewer.HasPermission(auth.PermissionAccountsRead))
+	assert.False(t, viewer.HasPermission(auth.PermissionTransfersCreate))
+	assert.True(t, viewer.CanAccessAccount(1))
+	assert.False(t, viewer.CanAccessAccount(2))
+
+	auditor := &auth.Principal{Roles: []string{auth.RoleAuditor}}
+	assert.True(t, auditor.CanAccessAccount(2))
+	assert.True(t, auditor.HasPermission(auth.PermissionAdminRead))
+	assert.False(t, auditor.HasPermission(auth.PermissionAdminWrite))
+
+	unknown := &auth.Principal{Roles: []string{"superuser"}}
+	assert.False(t, unknown.HasPermission(auth.PermissionAccountsRead))
+}
+
+func TestAuthorizer_AccountAndTransactionControllers(t *testing.T) {
+	mockService := new(mocks.MockAccountService)
+	mockService.On("GetAccountByID", 1).Return(&model.Account{AccountID: 1}, nil)
+	auditLogger := &mocks.MockAuditLogger{}
+	authorizer := auth.NewAuthorizer(auditLogger)
+
+	accountController := controller.NewAccountController(mockService, auditLogger)
+	accountController.Authorizer = authorizer
+	transactionController := &controller.TransactionController{Authorizer: authorizer}
+
+	router := mux.NewRouter()
+	router.Handle("/api/v1/accounts/{account_id:[0-9]+}", authorizer.Require(auth.PermissionAccountsRead, accountController.GetAccountHandler)).Methods("GET")
+	router.Handle("/api/v1/transactions", authorizer.Require(auth.PermissionTransfersCreate, transactionController.CreateTransactionHandler)).Methods("POST")
+
+	client := &auth.Principal{ID: "api-key:5", Roles: []string{auth.RoleOperator}, AccountIDs: []int{1}}
+	assert.Equal(t, http.StatusOK, serveAs(router, client, "GET", "/api/v1/accounts/1", nil))
+	assert.Equal(t, http.StatusForbidden, serveAs(router, client, "GET", "/api/v1/accounts/2", nil))
+
+	// Debiting an account that is not linked is refused before the transfer is attempted
+	transfer, _ := json.Marshal(model.TransactionRequest{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(10)})
+	assert.Equal(t, http.StatusForbidden, serveAs(router, client, "POST", "/api/v1/transactions", transfer))
+
+	viewer := &auth.Principal{ID: "api-key:6", Roles: []string{auth.RoleViewer}, AccountIDs: []int{1}}
+	assert.Equal(t, http.StatusForbidden, serveAs(router, viewer, "POST", "/api/v1/transactions", transfer))
+
+	assert.Len(t, auditLogger.Actions, 3)
+	for _, action := range auditLogger.Actions {
+		assert.Equal(t, "Authorization Denied", action)
+	}
+}
@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"context"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBalanceAsOf_FromSnapshot(t *testing.T) {
+	account := model.Account{AccountID: 1, InitialBalance: decimal.NewFromInt(100), CreatedAt: date(2025, time.January, 1)}
+	asOf := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	snapshotRepo := &mocks.MockBalanceSnapshotRepository{
+		MockGetLatestSnapshotBeforeWithContext: func(ctx context.Context, accountID int, before time.Time) (*model.BalanceSnapshot, error) {
+			assert.Equal(t, date(2025, time.March, 10), before)
+			return &model.BalanceSnapshot{AccountID: 1, SnapshotDate: date(2025, time.March, 9), Balance: decimal.NewFromInt(250)}, nil
+		},
+	}
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockSumLedgerEntriesBetweenWithContext: func(ctx context.Context, accountID int, from, to time.Time) (decimal.Decimal, error) {
+			assert.Equal(t, date(2025, time.March, 10), from)
+			assert.Equal(t, asOf, to)
+			return decimal.NewFromInt(-40), nil
+		},
+	}
+
+	balanceService := service.NewBalanceService(newAccountRepository(account), transactionRepo, snapshotRepo)
+	balance, err := balanceService.GetBalanceAsOf(1, asOf)
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(210).Equal(balance.Balance))
+	assert.NotNil(t, balance.SnapshotDate)
+}
+
+func TestGetBalanceAsOf_BeforeAccountCreated(t *testing.T) {
+	account := model.Account{AccountID: 1, InitialBalance: decimal.NewFromInt(100), CreatedAt: date(2025, time.January, 1)}
+
+	balanceService := service.NewBalanceService(newAccountRepository(account), &mocks.MockTransactionRepository{}, &mocks.MockBalanceSnapshotRepository{})
+	_, err := balanceService.GetBalanceAsOf(1, date(2024, time.December, 31))
+
+	assert.Error(t, err, "Expected error because the account did not exist yet")
+}
+
+func TestSnapshotBalances_CatchesUpFromLastSnapshot(t *testing.T) {
+	account := model.Account{AccountID: 1, InitialBalance: decimal.NewFromInt(100), CreatedAt: date(2025, time.January, 1)}
+	accountRepo := newAccountRepository(account)
+	accountRepo.MockListAccountsWithContext = func(ctx context.Context) ([]model.Account, error) {
+		return []model.Account{account}, nil
+	}
+
+	var saved []model.BalanceSnapshot
+	snapshotRepo := &mocks.MockBalanceSnapshotRepository{
+		MockGetLatestSnapshotBeforeWithContext: func(ctx context.Context, accountID int, before time.Time) (*model.BalanceSnapshot, error) {
+			return &model.BalanceSnapshot{AccountID: 1, SnapshotDate: date(2025, time.January, 5), Balance: decimal.NewFromInt(150)}, nil
+		},
+		MockSaveSnapshotsWithContext: func(ctx context.Context, snapshots []model.BalanceSnapshot) error {
+			saved = append(saved, snapshots...)
+			return nil
+		},
+	}
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockSumDailyLedgerEntriesWithContext: func(ctx context.Context, accountID int, from, to time.Time) (map[time.Time]decimal.Decimal, error) {
+			assert.Equal(t, date(2025, time.January, 6), from)
+			assert.Equal(t, date(2025, time.January, 9), to)
+			return map[time.Time]decimal.Decimal{date(2025, time.January, 7): decimal.NewFromInt(-30)}, nil
+		},
+	}
+
+	balanceService := service.NewBalanceService(accountRepo, transactionRepo, snapshotRepo)
+	err := balanceService.SnapshotBalances(context.Background(), date(2025, time.January, 8))
+
+	assert.NoError(t, err)
+	assert.Len(t, saved, 3)
+	assert.True(t, decimal.NewFromInt(150).Equal(saved[0].Balance))
+	assert.True(t, decimal.NewFromInt(120).Equal(saved[1].Balance))
+	assert.True(t, decimal.NewFromInt(120).Equal(saved[2].Balance))
+}
@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"internal-transfers/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	path := writeConfigFile(t, `
+db:
+  host: file-host
+  user: transfers
+  password: secret
+  name: transfers
+http:
+  addr: ":9000"
+retries:
+  attempts: 4
+features:
+  statement_formats: [csv, json]
+  approval_threshold: "1000.50"
+`)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("RETRY_ATTEMPTS", "5")
+	t.Setenv("APPROVAL_TIMEOUT", "2h")
+
+	cfg, args, err := config.Load([]string{"--config", path, "--retries-attempts", "6", "--auth-disabled", "Ops admin"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Ops admin"}, args)
+	assert.Equal(t, "env-host", cfg.DB.Host, "env overrides the file")
+	assert.Equal(t, ":9000", cfg.HTTP.Addr, "file overrides defaults")
+	assert.Equal(t, 6, cfg.Retries.Attempts, "flags override env")
+	assert.True(t, cfg.Auth.Disabled)
+	assert.Equal(t, 5432, cfg.DB.Port)
+	assert.Equal(t, []string{"csv", "json"}, cfg.Features.StatementFormats)
+	assert.Equal(t, "1000.5", cfg.Features.ApprovalThreshold.String())
+	assert.Equal(t, 2*time.Hour, cfg.Features.ApprovalTimeout)
+	assert.Equal(t, 30*time.Second, cfg.Timeouts.Operation)
+	assert.Equal(t, 5*time.Second, cfg.Shutdown.DrainDelay)
+}
+
+func TestLoadConfig_ReportsEveryProblem(t *testing.T) {
+	path := writeConfigFile(t, `
+db:
+  port: 70000
+tls:
+  cert_file: server.pem
+features:
+  statement_formats: [xml]
+`)
+	t.Setenv("HMAC_REQUIRED", "true")
+
+	_, _, err := config.Load([]string{"--config", path})
+
+	assert.Error(t, err)
+	for _, expected := range []string{
+		"db.user (DB_USER) is required",
+		"db.port (DB_PORT) must be between 1 and 65535",
+		"tls.cert_file (TLS_CERT_FILE) and tls.key_file (TLS_KEY_FILE) must be set together",
+		`unknown format "xml"`,
+		"auth.hmac_required (HMAC_REQUIRED) requires auth.hmac_clients_file",
+	} {
+		assert.Contains(t, err.Error(), expected)
+	}
+}
+
+func TestLoadConfig_RejectsBadInput(t *testing.T) {
+	_, _, err := config.Load([]string{"--config", writeConfigFile(t, "db:\n  hostname: x\n")})
+	assert.ErrorContains(t, err, "field hostname not found")
+
+	t.Setenv("DB_PORT", "five")
+	_, _, err = config.Load(nil)
+	assert.ErrorContains(t, err, "invalid DB_PORT")
+}
+
+func TestLoadConfig_DSNReplacesConnectionFields(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://transfers@db.internal/ledger?sslmode=verify-full")
+
+	cfg, _, err := config.Load([]string{"--db-max-open-conns", "50", "--db-max-idle-conns", "20"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 50, cfg.DB.MaxOpenConns)
+
+	_, _, err = config.Load([]string{"--db-max-open-conns", "5", "--db-max-idle-conns", "20"})
+	assert.ErrorContains(t, err, "db.max_idle_conns (DB_MAX_IDLE_CONNS) must not exceed db.max_open_conns")
+}
+
+func TestLoadConfig_ReadsNamedEnvFileWithoutExportingIt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.env")
+	assert.NoError(t, os.WriteFile(path, []byte("DB_HOST=dotenv-host\nDB_NAME=dotenv-name\nDB_USER=transfers\nDB_PASSWORD=secret\n"), 0o600))
+	t.Setenv("DB_NAME", "env-name")
+	t.Setenv("DB_HOST", "")
+	os.Unsetenv("DB_HOST")
+
+	cfg, _, err := config.Load([]string{"--env-file", path})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "dotenv-host", cfg.DB.Host)
+	assert.Equal(t, "env-name", cfg.DB.Name, "the environment overrides the env file")
+	_, exported := os.LookupEnv("DB_HOST")
+	assert.False(t, exported, "the env file is not loaded into the process environment")
+
+	_, _, err = config.Load([]string{"--env-file", filepath.Join(t.TempDir(), "missing.env")})
+	assert.ErrorContains(t, err, "could not read env file")
+}
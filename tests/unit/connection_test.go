@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"internal-transfers/config"
+	"internal-transfers/controller"
+	"internal-transfers/model"
+	"internal-transfers/persistence"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionString(t *testing.T) {
+	dbConfig := config.Default().DB
+	dbConfig.User = "transfers"
+	dbConfig.Password = `p@ss 'word`
+	dbConfig.Name = "ledger"
+	dbConfig.SSLMode = "verify-full"
+	dbConfig.SSLRootCert = "/etc/ssl/db-ca.pem"
+
+	assert.Equal(t, `host='localhost' port=5432 user='transfers' dbname='ledger' sslmode='verify-full' password='p@ss \'word' sslrootcert='/etc/ssl/db-ca.pem'`,
+		persistence.ConnectionString(dbConfig))
+
+	dbConfig.DSN = "postgres://transfers@db.internal/ledger?sslmode=require"
+	assert.Equal(t, dbConfig.DSN, persistence.ConnectionString(dbConfig), "a DSN is used as-is")
+}
+
+func TestConnectToDB_GivesUpAfterAttempts(t *testing.T) {
+	dbConfig := config.Default().DB
+	dbConfig.DSN = "postgres://transfers@127.0.0.1:1/ledger?sslmode=disable"
+	dbConfig.ConnectAttempts = 3
+	dbConfig.ConnectBackoff = 10 * time.Millisecond
+
+	started := time.Now()
+	db, err := persistence.ConnectToDB(dbConfig)
+
+	assert.Nil(t, db)
+	assert.ErrorContains(t, err, "after 3 attempts")
+	assert.GreaterOrEqual(t, time.Since(started), 30*time.Millisecond, "waits 10ms then 20ms between attempts")
+}
+
+func TestGetPoolStatsHandler(t *testing.T) {
+	databaseController := controller.NewDatabaseController(func() sql.DBStats {
+		return sql.DBStats{MaxOpenConnections: 25, OpenConnections: 4, InUse: 3, Idle: 1, WaitCount: 2, WaitDuration: 1500 * time.Millisecond}
+	})
+	recorder := httptest.NewRecorder()
+
+	databaseController.GetPoolStatsHandler(recorder, httptest.NewRequest("GET", "/api/v1/admin/database/pool", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var stats model.DBPoolStats
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&stats))
+	assert.Equal(t, model.DBPoolStats{MaxOpenConnections: 25, OpenConnections: 4, InUse: 3, Idle: 1, WaitCount: 2, WaitDurationMs: 1500}, stats)
+}
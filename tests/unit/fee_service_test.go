@@ -0,0 +1,120 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"internal-transfers/common"
+	"internal-transfers/controller"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFeeService(schedules []model.FeeSchedule) *service.FeeService {
+	mockRepo := &mocks.MockFeeScheduleRepository{
+		MockListEffectiveFeeSchedulesWithContext: func(ctx context.Context, asOf time.Time) ([]model.FeeSchedule, error) {
+			return schedules, nil
+		},
+	}
+	return service.NewFeeService(mockRepo, &common.AuditLogger{}, 999)
+}
+
+func TestCalculateFee_Percentage(t *testing.T) {
+	feeService := newFeeService([]model.FeeSchedule{
+		{FeeScheduleID: 1, Name: "default", FeeType: model.FeeTypePercentage, Percentage: decimal.RequireFromString("1.5")},
+	})
+
+	source := &model.Account{AccountID: 1, AccountType: "standard"}
+	destination := &model.Account{AccountID: 2, AccountType: "standard"}
+
+	breakdown, err := feeService.CalculateFee(context.Background(), source, destination, decimal.NewFromInt(200))
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(3).Equal(breakdown.ChargedFee))
+	assert.Equal(t, 999, breakdown.FeeAccountID)
+}
+
+func TestCalculateFee_TieredWithCaps(t *testing.T) {
+	feeService := newFeeService([]model.FeeSchedule{
+		{
+			FeeScheduleID: 1,
+			Name:          "tiered",
+			FeeType:       model.FeeTypeTiered,
+			Tiers: model.FeeTiers{
+				{UpTo: decimal.NewNullDecimal(decimal.NewFromInt(100)), FlatAmount: decimal.NewFromInt(1)},
+				{Percentage: decimal.NewFromInt(1)},
+			},
+			MinFee: decimal.NewNullDecimal(decimal.NewFromInt(2)),
+			MaxFee: decimal.NewNullDecimal(decimal.NewFromInt(50)),
+		},
+	})
+
+	source := &model.Account{AccountID: 1, AccountType: "standard"}
+	destination := &model.Account{AccountID: 2, AccountType: "standard"}
+
+	small, err := feeService.CalculateFee(context.Background(), source, destination, decimal.NewFromInt(50))
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(1).Equal(small.CalculatedFee))
+	assert.True(t, decimal.NewFromInt(2).Equal(small.ChargedFee))
+	assert.Equal(t, "min", small.CapApplied)
+
+	medium, err := feeService.CalculateFee(context.Background(), source, destination, decimal.NewFromInt(1000))
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(10).Equal(medium.ChargedFee))
+	assert.Equal(t, "", medium.CapApplied)
+
+	large, err := feeService.CalculateFee(context.Background(), source, destination, decimal.NewFromInt(10000))
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(50).Equal(large.ChargedFee))
+	assert.Equal(t, "max", large.CapApplied)
+}
+
+func TestSelectFeeSchedule_PairOutranksAccountType(t *testing.T) {
+	sourceID, destinationID := 1, 2
+	schedules := []model.FeeSchedule{
+		{FeeScheduleID: 1, Name: "default", FeeType: model.FeeTypeFlat},
+		{FeeScheduleID: 2, Name: "business", SourceAccountType: "business", FeeType: model.FeeTypeFlat},
+		{FeeScheduleID: 3, Name: "pair", SourceAccountID: &sourceID, DestinationAccountID: &destinationID, FeeType: model.FeeTypeFlat},
+	}
+
+	source := &model.Account{AccountID: 1, AccountType: "business"}
+	destination := &model.Account{AccountID: 2, AccountType: "standard"}
+	other := &model.Account{AccountID: 3, AccountType: "standard"}
+
+	assert.Equal(t, 3, service.SelectFeeSchedule(schedules, source, destination).FeeScheduleID)
+	assert.Equal(t, 2, service.SelectFeeSchedule(schedules, source, other).FeeScheduleID)
+	assert.Equal(t, 1, service.SelectFeeSchedule(schedules, other, source).FeeScheduleID)
+}
+
+func TestValidateFeeSchedule_MinAboveMax(t *testing.T) {
+	err := service.ValidateFeeSchedule(model.FeeSchedule{
+		Name:    "invalid",
+		FeeType: model.FeeTypeFlat,
+		MinFee:  decimal.NewNullDecimal(decimal.NewFromInt(10)),
+		MaxFee:  decimal.NewNullDecimal(decimal.NewFromInt(5)),
+	})
+
+	assert.Error(t, err, "Expected error because min_fee exceeds max_fee")
+}
+
+func TestListFeeSchedulesHandler_ServerErrorHidesTheCause(t *testing.T) {
+	mockRepo := &mocks.MockFeeScheduleRepository{
+		MockListFeeSchedulesWithContext: func(ctx context.Context) ([]model.FeeSchedule, error) {
+			return nil, errors.New(`pq: relation "fee_schedules" does not exist`)
+		},
+	}
+	feeController := controller.NewFeeController(service.NewFeeService(mockRepo, &common.AuditLogger{}, 999))
+
+	recorder := httptest.NewRecorder()
+	feeController.ListFeeSchedulesHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/admin/fee-schedules", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "Error fetching fee schedules\n", recorder.Body.String())
+}
@@ -0,0 +1,350 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	transfersv1 "internal-transfers/proto/transfers/v1"
+	"internal-transfers/ratelimit"
+	"internal-transfers/rpc"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Serves the gRPC API over an in-memory listener, without authentication when authenticator is nil
+func startGRPCServer(t *testing.T, authenticator *auth.Authenticator, accountRepo *mocks.MockAccountRepository,
+	transactionService *service.TransactionService) (transfersv1.AccountServiceClient, transfersv1.TransferServiceClient) {
+	return serveGRPC(t, rpc.NewInterceptor(authenticator, auth.NewAuthorizer(&mocks.MockAuditLogger{}), &mocks.MockAuditLogger{}), accountRepo, transactionService)
+}
+
+// Serves the gRPC API over an in-memory listener through the given interceptor
+func serveGRPC(t *testing.T, interceptor *rpc.Interceptor, accountRepo *mocks.MockAccountRepository,
+	transactionService *service.TransactionService) (transfersv1.AccountServiceClient, transfersv1.TransferServiceClient) {
+	authorizer := interceptor.Authorizer
+	server := rpc.NewServer(interceptor,
+		rpc.NewAccountServer(service.NewAccountService(accountRepo, &common.AuditLogger{}), &mocks.MockAuditLogger{}, authorizer),
+		rpc.NewTransferServer(transactionService, nil, authorizer), true)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	connection, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { connection.Close() })
+	return transfersv1.NewAccountServiceClient(connection), transfersv1.NewTransferServiceClient(connection)
+}
+
+func TestGRPC_AuthenticatesAndAuthorizesCalls(t *testing.T) {
+	key, err := auth.GenerateAPIKey()
+	assert.NoError(t, err)
+	apiKeys := &mocks.MockAPIKeyRepository{
+		MockGetAPIKeyByHashWithContext: func(ctx context.Context, keyHash string) (*model.APIKey, error) {
+			if keyHash == auth.HashAPIKey(key) {
+				return &model.APIKey{KeyID: 3, Name: "dashboard", Roles: []string{auth.RoleViewer}, AccountIDs: []int64{1}}, nil
+			}
+			return nil, nil
+		},
+	}
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, Balance: decimal.NewFromInt(100)},
+	)
+	transactionService := service.NewTransactionService(accountRepo, &mocks.MockTransactionRepository{}, &common.AuditLogger{})
+	accounts, transfers := startGRPCServer(t, auth.NewAuthenticator(apiKeys, nil, &mocks.MockAuditLogger{}), accountRepo, transactionService)
+
+	_, err = accounts.GetAccount(context.Background(), &transfersv1.GetAccountRequest{AccountId: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key, "x-request-id", "req-42")
+	var header metadata.MD
+	account, err := accounts.GetAccount(ctx, &transfersv1.GetAccountRequest{AccountId: 1}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, "100", account.GetBalance())
+	assert.Equal(t, []string{"req-42"}, header.Get("x-request-id"))
+
+	_, err = accounts.GetAccount(ctx, &transfersv1.GetAccountRequest{AccountId: 2})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "account 2 is not linked to the key")
+
+	_, err = transfers.PerformTransfer(ctx, &transfersv1.PerformTransferRequest{SourceAccountId: 1, DestinationAccountId: 2, Amount: "10"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "viewers cannot transfer")
+
+	_, err = accounts.CreateAccount(ctx, &transfersv1.CreateAccountRequest{AccountId: 3, InitialBalance: "0"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestGRPC_MapsDomainErrorsToStatusCodes(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, AccountType: "standard", Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, AccountType: "standard", Balance: decimal.NewFromInt(0)},
+	)
+	accountRepo.MockCreateAccountWithContext = func(ctx context.Context, account model.Account) error { return nil }
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			transaction.TransactionID = 10
+			transaction.CreatedAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			return nil
+		},
+	}
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+	accounts, transfers := startGRPCServer(t, nil, accountRepo, transactionService)
+	ctx := context.Background()
+
+	response, err := transfers.PerformTransfer(ctx, &transfersv1.PerformTransferRequest{SourceAccountId: 1, DestinationAccountId: 2, Amount: "40.50"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), response.GetTransaction().GetTransactionId())
+	assert.Equal(t, "40.5", response.GetTransaction().GetAmount())
+	assert.Equal(t, "transfer", response.GetTransaction().GetTransactionType())
+
+	cases := map[codes.Code]*transfersv1.PerformTransferRequest{
+		codes.FailedPrecondition: {SourceAccountId: 2, DestinationAccountId: 1, Amount: "1"},
+		codes.NotFound:           {SourceAccountId: 1, DestinationAccountId: 9, Amount: "1"},
+		codes.InvalidArgument:    {SourceAccountId: 1, DestinationAccountId: 2, Amount: "0"},
+	}
+	for code, request := range cases {
+		_, err := transfers.PerformTransfer(ctx, request)
+		assert.Equal(t, code, status.Code(err), "%v", request)
+	}
+	_, err = transfers.PerformTransfer(ctx, &transfersv1.PerformTransferRequest{SourceAccountId: 1, DestinationAccountId: 2, Amount: "ten"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = accounts.CreateAccount(ctx, &transfersv1.CreateAccountRequest{AccountId: 1, InitialBalance: "5"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = accounts.CreateAccount(ctx, &transfersv1.CreateAccountRequest{InitialBalance: "5"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = accounts.CreateAccount(ctx, &transfersv1.CreateAccountRequest{AccountId: 3, InitialBalance: "-5"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "negative initial balance")
+	_, err = accounts.CreateAccount(ctx, &transfersv1.CreateAccountRequest{AccountId: 3, InitialBalance: "5", AccountType: "DROP TABLE"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "account type outside the pattern")
+	_, err = accounts.GetAccount(ctx, &transfersv1.GetAccountRequest{AccountId: 9})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	transactionService.AbortTransfers()
+	_, err = transfers.PerformTransfer(ctx, &transfersv1.PerformTransferRequest{SourceAccountId: 1, DestinationAccountId: 2, Amount: "1"})
+	assert.Equal(t, codes.Unavailable, status.Code(err), "transfers are refused while shutting down")
+}
+
+func TestGRPC_VerifiesSignaturesAndLimitsTransfers(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, Balance: decimal.NewFromInt(100)},
+	)
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			return nil
+		},
+	}
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+	interceptor := rpc.NewInterceptor(nil, auth.NewAuthorizer(&mocks.MockAuditLogger{}), &mocks.MockAuditLogger{})
+	interceptor.SignatureVerifier = auth.NewSignatureVerifier(map[string][]byte{"payments-svc": signingSecret}, 5*time.Minute, true, &mocks.MockAuditLogger{})
+	store := ratelimit.NewMemoryStore()
+	interceptor.AccountLimiter = ratelimit.NewSourceAccountLimiter(store, ratelimit.PerMinute(60, 1))
+	interceptor.ClientLimiter = ratelimit.NewClientLimiter(store, ratelimit.PerMinute(60, 3))
+	accounts, transfers := serveGRPC(t, interceptor, accountRepo, transactionService)
+
+	signed := func(request *transfersv1.PerformTransferRequest, nonce string) context.Context {
+		body := []byte(fmt.Sprintf("%d\n%d\n%s", request.SourceAccountId, request.DestinationAccountId, request.Amount))
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		signature := auth.ComputeSignature(signingSecret, "POST", transfersv1.TransferService_PerformTransfer_FullMethodName, timestamp, nonce, body)
+		return metadata.AppendToOutgoingContext(context.Background(), "x-operator-id", "ops-1", "x-signature-client", "payments-svc",
+			"x-signature-timestamp", timestamp, "x-signature-nonce", nonce, "x-signature", signature)
+	}
+	transfer := &transfersv1.PerformTransferRequest{SourceAccountId: 1, DestinationAccountId: 2, Amount: "10"}
+
+	_, err := transfers.PerformTransfer(context.Background(), transfer)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "unsigned while required")
+	tampered := &transfersv1.PerformTransferRequest{SourceAccountId: 1, DestinationAccountId: 2, Amount: "1000"}
+	_, err = transfers.PerformTransfer(signed(transfer, "n-1"), tampered)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "the signature covers the request")
+
+	_, err = transfers.PerformTransfer(signed(transfer, "n-2"), transfer)
+	assert.NoError(t, err)
+	_, err = transfers.PerformTransfer(signed(transfer, "n-2"), transfer)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "replayed nonce")
+
+	var header metadata.MD
+	_, err = transfers.PerformTransfer(signed(transfer, "n-3"), transfer, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "account 1 is over its limit")
+	assert.Equal(t, []string{"1"}, header.Get("retry-after"))
+
+	_, err = accounts.GetAccount(metadata.AppendToOutgoingContext(context.Background(), "x-operator-id", "ops-1"), &transfersv1.GetAccountRequest{AccountId: 1})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "the client limit covers every method")
+}
+
+func TestGRPC_ListTransactionsPages(t *testing.T) {
+	var requestedLimit int
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockListTransactionsWithContext: func(ctx context.Context, accountID int, afterID int, limit int) ([]model.Transaction, error) {
+			requestedLimit = limit
+			var page []model.Transaction
+			for id := afterID + 1; id <= 5 && len(page) < limit; id++ {
+				page = append(page, model.Transaction{TransactionID: id, SourceAccountID: accountID, Amount: decimal.NewFromInt(1)})
+			}
+			return page, nil
+		},
+	}
+	transactionService := service.NewTransactionService(newAccountRepository(), transactionRepo, &common.AuditLogger{})
+	_, transfers := startGRPCServer(t, nil, newAccountRepository(), transactionService)
+
+	response, err := transfers.ListTransactions(context.Background(), &transfersv1.ListTransactionsRequest{AccountId: 1, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, response.GetTransactions(), 2)
+	assert.Equal(t, int64(2), response.GetNextAfterTransactionId())
+
+	response, err = transfers.ListTransactions(context.Background(), &transfersv1.ListTransactionsRequest{AccountId: 1, AfterTransactionId: 4, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, response.GetTransactions(), 1)
+	assert.Equal(t, int64(0), response.GetNextAfterTransactionId(), "the last page has no cursor")
+
+	_, err = transfers.ListTransactions(context.Background(), &transfersv1.ListTransactionsRequest{AccountId: 1, PageSize: 10000})
+	assert.NoError(t, err)
+	assert.Equal(t, service.MaxTransactionPageSize, requestedLimit)
+}
+
+func TestGRPC_StreamReplaysThenFollowsTheFeed(t *testing.T) {
+	now := time.Now()
+	stored := []model.Transaction{
+		{TransactionID: 5, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(5), CreatedAt: now},
+		{TransactionID: 6, SourceAccountID: 3, DestinationAccountID: 1, Amount: decimal.NewFromInt(6), CreatedAt: now},
+	}
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockListTransactionsWithContext: func(ctx context.Context, accountID int, afterID int, limit int) ([]model.Transaction, error) {
+			var page []model.Transaction
+			for _, transaction := range stored {
+				if transaction.TransactionID > afterID {
+					page = append(page, transaction)
+				}
+			}
+			return page, nil
+		},
+	}
+	transactionService := service.NewTransactionService(newAccountRepository(), transactionRepo, &common.AuditLogger{})
+	feed := service.NewTransactionFeed()
+	transactionService.Feed = feed
+	_, transfers := startGRPCServer(t, nil, newAccountRepository(), transactionService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := transfers.StreamTransactions(ctx, &transfersv1.StreamTransactionsRequest{AccountId: 1, AfterTransactionId: 4})
+	assert.NoError(t, err)
+
+	var received []int64
+	for len(received) < 2 {
+		transaction, err := stream.Recv()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		received = append(received, transaction.GetTransactionId())
+	}
+	assert.Equal(t, []int64{5, 6}, received, "stored transactions are replayed first")
+
+	assert.Eventually(t, func() bool { return feed.Subscribers() == 1 }, time.Second, 10*time.Millisecond)
+	feed.Publish(stored[1]) // committed while replaying, already sent
+	feed.Publish(model.Transaction{TransactionID: 7, SourceAccountID: 3, DestinationAccountID: 4, CreatedAt: now})
+	feed.Publish(model.Transaction{TransactionID: 8, SourceAccountID: 1, DestinationAccountID: 4, Amount: decimal.NewFromInt(8), CreatedAt: now})
+
+	transaction, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), transaction.GetTransactionId(), "duplicates and other accounts' transactions are skipped")
+	assert.Equal(t, "8", transaction.GetAmount())
+
+	feed.Close()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestTransactionFeed_DropsSubscribersThatFallBehind(t *testing.T) {
+	feed := service.NewTransactionFeed()
+	slow := feed.Subscribe(2)
+	fast := feed.Subscribe(10)
+
+	for id := 1; id <= 3; id++ {
+		feed.Publish(model.Transaction{TransactionID: id})
+	}
+
+	var slowReceived []int
+	for transaction := range slow.Events() {
+		slowReceived = append(slowReceived, transaction.TransactionID)
+	}
+	assert.Equal(t, []int{1, 2}, slowReceived)
+	assert.ErrorIs(t, slow.Err(), service.ErrFeedLagged)
+	assert.Equal(t, 1, feed.Subscribers())
+	assert.Len(t, fast.Events(), 3)
+
+	fast.Close()
+	fast.Close()
+	assert.NoError(t, fast.Err(), "closing a subscription is not an error")
+	assert.Equal(t, 0, feed.Subscribers())
+
+	feed.Close()
+	late := feed.Subscribe(1)
+	_, open := <-late.Events()
+	assert.False(t, open)
+	assert.ErrorIs(t, late.Err(), service.ErrFeedClosed)
+	feed.Publish(model.Transaction{TransactionID: 4})
+}
+
+func TestTransactionFeed_FollowsCommittedTransactionsIncludingLateOnes(t *testing.T) {
+	committed := []model.Transaction{{TransactionID: 6}, {TransactionID: 8}}
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockGetLastTransactionIDWithContext: func(ctx context.Context) (int, error) {
+			return 5, nil
+		},
+		MockListTransactionsWithContext: func(ctx context.Context, accountID int, afterID int, limit int) ([]model.Transaction, error) {
+			var page []model.Transaction
+			for _, transaction := range committed {
+				if transaction.TransactionID > afterID {
+					page = append(page, transaction)
+				}
+			}
+			return page, nil
+		},
+		MockListTransactionsByIDWithContext: func(ctx context.Context, transactionIDs []int) ([]model.Transaction, error) {
+			assert.Equal(t, []int{7}, transactionIDs, "only the ID skipped below the highest one is looked for again")
+			// Committed after transaction 8
+			return []model.Transaction{{TransactionID: 7}}, nil
+		},
+	}
+	feed := service.NewTransactionFeed()
+	subscription := feed.Subscribe(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		feed.Follow(ctx, transactionRepo, 5*time.Millisecond)
+	}()
+
+	var received []int
+	for len(received) < 3 {
+		select {
+		case transaction := <-subscription.Events():
+			received = append(received, transaction.TransactionID)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received only %v", received)
+		}
+	}
+	cancel()
+	<-done
+
+	assert.Equal(t, []int{6, 8, 7}, received)
+	assert.Empty(t, subscription.Events(), "transactions are published once")
+}
@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"internal-transfers/common"
+	"internal-transfers/controller"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveReadiness(healthService *service.HealthService) (int, model.HealthReport) {
+	recorder := httptest.NewRecorder()
+	controller.NewHealthController(healthService).ReadinessHandler(recorder, httptest.NewRequest("GET", "/readyz", nil))
+	var report model.HealthReport
+	json.Unmarshal(recorder.Body.Bytes(), &report)
+	return recorder.Code, report
+}
+
+func TestReadiness_ReportsEachDependency(t *testing.T) {
+	healthService := service.NewHealthService(time.Second)
+	healthService.Register("database", func(ctx context.Context) error { return nil })
+	healthService.Register("audit_log", func(ctx context.Context) error { return errors.New("disk full") })
+
+	status, report := serveReadiness(healthService)
+
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, model.HealthStatusFailing, report.Status)
+	assert.Equal(t, model.HealthStatusOK, report.Checks["database"].Status)
+	assert.Equal(t, model.HealthStatusOK, report.Checks["draining"].Status)
+	assert.Equal(t, model.HealthStatusFailing, report.Checks["audit_log"].Status)
+	assert.Equal(t, "disk full", report.Checks["audit_log"].Error)
+}
+
+func TestReadiness_TimesOutSlowChecks(t *testing.T) {
+	healthService := service.NewHealthService(20 * time.Millisecond)
+	healthService.Register("database", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	status, report := serveReadiness(healthService)
+
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+}
+
+func TestReadiness_FailsOnceDraining(t *testing.T) {
+	healthService := service.NewHealthService(time.Second)
+	healthService.Register("database", func(ctx context.Context) error { return nil })
+
+	status, _ := serveReadiness(healthService)
+	assert.Equal(t, http.StatusOK, status)
+
+	healthService.StartDraining()
+	status, report := serveReadiness(healthService)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, service.ErrDraining.Error(), report.Checks["draining"].Error)
+
+	recorder := httptest.NewRecorder()
+	controller.NewHealthController(healthService).LivenessHandler(recorder, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code, "a draining process is still alive")
+}
+
+func TestAuditLogger_CheckWritable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLogger, err := common.NewAuditLogger(path)
+	assert.NoError(t, err)
+	assert.NoError(t, auditLogger.CheckWritable())
+
+	assert.NoError(t, os.Remove(path))
+	assert.Error(t, auditLogger.CheckWritable())
+}
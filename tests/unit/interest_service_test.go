@@ -0,0 +1,182 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDayCountFraction_Actual365(t *testing.T) {
+	fraction, err := service.DayCountFraction(model.DayCountActual365, date(2024, time.February, 28), date(2024, time.March, 1))
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(2).Div(decimal.NewFromInt(365)).Equal(fraction))
+}
+
+func TestDayCountFraction_30360(t *testing.T) {
+	cases := []struct {
+		start, end time.Time
+		days       int64
+	}{
+		{date(2025, time.January, 15), date(2025, time.January, 16), 1},
+		{date(2025, time.January, 31), date(2025, time.February, 1), 1},
+		{date(2025, time.February, 28), date(2025, time.March, 1), 3},
+		{date(2025, time.March, 30), date(2025, time.March, 31), 0},
+		{date(2025, time.January, 1), date(2026, time.January, 1), 360},
+	}
+
+	for _, c := range cases {
+		fraction, err := service.DayCountFraction(model.DayCount30360, c.start, c.end)
+		assert.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(c.days).Div(decimal.NewFromInt(360)).Equal(fraction), "%s to %s", c.start, c.end)
+	}
+}
+
+func TestDailyInterest(t *testing.T) {
+	interest, err := service.DailyInterest(decimal.NewFromInt(36500), decimal.NewFromInt(5), model.DayCountActual365, date(2025, time.May, 10))
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(5).Equal(interest), "got %s", interest)
+}
+
+func TestDailyInterest_UnknownConvention(t *testing.T) {
+	_, err := service.DailyInterest(decimal.NewFromInt(100), decimal.NewFromInt(5), "ACT/ACT", date(2025, time.May, 10))
+
+	assert.Error(t, err)
+}
+
+func TestAccrueInterest_AccruesEachDayFromClosingBalances(t *testing.T) {
+	last := date(2025, time.May, 10)
+	var accruals []model.InterestAccrual
+	interestRepo := &mocks.MockInterestRepository{
+		MockListEnabledInterestConfigsWithContext: func(ctx context.Context) ([]model.InterestConfig, error) {
+			return []model.InterestConfig{{AccountID: 1, AnnualRate: decimal.NewFromInt(5), DayCountConvention: model.DayCountActual365,
+				Enabled: true, StartDate: date(2025, time.May, 1)}}, nil
+		},
+		MockGetLastAccrualDateWithContext: func(ctx context.Context, accountID int) (*time.Time, error) {
+			return &last, nil
+		},
+		MockCreateAccrualWithContext: func(ctx context.Context, accrual model.InterestAccrual) error {
+			accruals = append(accruals, accrual)
+			return nil
+		},
+	}
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockSumLedgerEntriesSinceWithContext: func(ctx context.Context, accountID int, since time.Time) (decimal.Decimal, error) {
+			// 36500 was deposited on May 12
+			if since.After(date(2025, time.May, 12)) {
+				return decimal.Zero, nil
+			}
+			return decimal.NewFromInt(36500), nil
+		},
+	}
+	accountRepo := newAccountRepository(model.Account{AccountID: 1, Balance: decimal.NewFromInt(73000)})
+	interestService := service.NewInterestService(interestRepo, accountRepo, transactionRepo, nil, &common.AuditLogger{}, 999)
+
+	assert.NoError(t, interestService.AccrueInterest(context.Background(), time.Date(2025, time.May, 12, 18, 0, 0, 0, time.UTC)))
+
+	assert.Len(t, accruals, 2, "days up to the last accrual are not accrued again")
+	assert.Equal(t, date(2025, time.May, 11), accruals[0].AccrualDate)
+	assert.Equal(t, "36500", accruals[0].ClosingBalance.String())
+	assert.Equal(t, "5", accruals[0].Amount.String())
+	assert.Equal(t, date(2025, time.May, 12), accruals[1].AccrualDate)
+	assert.Equal(t, "10", accruals[1].Amount.String())
+}
+
+func TestAccrueInterest_ContinuesAfterFailures(t *testing.T) {
+	var accrued []int
+	interestRepo := &mocks.MockInterestRepository{
+		MockListEnabledInterestConfigsWithContext: func(ctx context.Context) ([]model.InterestConfig, error) {
+			return []model.InterestConfig{
+				{AccountID: 1, AnnualRate: decimal.NewFromInt(5), DayCountConvention: model.DayCountActual365, Enabled: true, StartDate: date(2025, time.May, 12)},
+				{AccountID: 2, AnnualRate: decimal.NewFromInt(5), DayCountConvention: model.DayCountActual365, Enabled: true, StartDate: date(2025, time.May, 12)},
+				{AccountID: 3, AnnualRate: decimal.NewFromInt(5), DayCountConvention: model.DayCountActual365, Enabled: true, StartDate: date(2025, time.May, 12)},
+			}, nil
+		},
+		MockGetLastAccrualDateWithContext: func(ctx context.Context, accountID int) (*time.Time, error) {
+			if accountID == 2 {
+				return nil, errors.New("connection reset")
+			}
+			return nil, nil
+		},
+		MockCreateAccrualWithContext: func(ctx context.Context, accrual model.InterestAccrual) error {
+			accrued = append(accrued, accrual.AccountID)
+			return nil
+		},
+	}
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockSumLedgerEntriesSinceWithContext: func(ctx context.Context, accountID int, since time.Time) (decimal.Decimal, error) {
+			return decimal.Zero, nil
+		},
+	}
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 3, Balance: decimal.NewFromInt(100)},
+	)
+	interestService := service.NewInterestService(interestRepo, accountRepo, transactionRepo, nil, &common.AuditLogger{}, 999)
+
+	err := interestService.AccrueInterest(context.Background(), date(2025, time.May, 12))
+
+	assert.ErrorContains(t, err, "account 2")
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Equal(t, []int{1, 3}, accrued, "accounts after a failure are still accrued")
+}
+
+func TestPostInterest_PostsThroughTheTransferPathAndContinuesAfterFailures(t *testing.T) {
+	interestRepo := &mocks.MockInterestRepository{
+		MockListUnpostedInterestWithContext: func(ctx context.Context) ([]model.UnpostedInterest, error) {
+			return []model.UnpostedInterest{
+				{AccountID: 1, Total: decimal.RequireFromString("1.234567"), LastAccrualID: 10},
+				{AccountID: 2, Total: decimal.NewFromInt(-3), LastAccrualID: 11},
+				{AccountID: 3, Total: decimal.NewFromInt(4), LastAccrualID: 12},
+				{AccountID: 4, Total: decimal.NewFromInt(2), LastAccrualID: 13},
+			}, nil
+		},
+	}
+	posted := map[int][]model.LedgerEntry{}
+	interestRepo.MockPostInterestWithContext = func(ctx context.Context, pending model.UnpostedInterest, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+		if pending.AccountID == 3 {
+			// Another run claimed the accruals first
+			return false, nil
+		}
+		transaction.TransactionID = 100 + pending.AccountID
+		posted[pending.AccountID] = entries
+		return true, nil
+	}
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, Balance: decimal.NewFromInt(10)},
+		// Negative interest over the balance
+		model.Account{AccountID: 2, Balance: decimal.NewFromInt(1)},
+		model.Account{AccountID: 3, Balance: decimal.NewFromInt(10)},
+		model.Account{AccountID: 4, Balance: decimal.NewFromInt(10)},
+		model.Account{AccountID: 999, Balance: decimal.Zero},
+	)
+	transactionService := service.NewTransactionService(accountRepo, &mocks.MockTransactionRepository{}, &common.AuditLogger{})
+	transactionService.RegisterSystemAccount(999)
+	interestService := service.NewInterestService(interestRepo, accountRepo, &mocks.MockTransactionRepository{}, transactionService, &common.AuditLogger{}, 999)
+
+	err := interestService.PostInterest(context.Background())
+
+	assert.ErrorContains(t, err, "1 of 4 accounts")
+	assert.Len(t, posted, 2, "accounts after a failure are still posted")
+	assert.Equal(t, 999, posted[1][0].AccountID)
+	assert.Equal(t, "-1.23457", posted[1][0].Amount.String(), "posted amounts are rounded to the balance precision")
+	assert.True(t, posted[1][0].AllowNegative, "the expense account may go negative")
+	assert.Equal(t, model.TransactionTypeInterest, posted[1][0].EntryType)
+	assert.Equal(t, 1, posted[1][1].AccountID)
+	assert.Equal(t, "1.23457", posted[1][1].Amount.String())
+	assert.Equal(t, "2", posted[4][1].Amount.String())
+}
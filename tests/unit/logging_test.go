@@ -0,0 +1,143 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"internal-transfers/common"
+	"internal-transfers/controller"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Sends the default logger to a buffer for the duration of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var output bytes.Buffer
+	previousLogger, previousWriter, previousFlags := slog.Default(), log.Writer(), log.Flags()
+	assert.NoError(t, common.SetupLogging("json", "info", &output))
+	t.Cleanup(func() {
+		slog.SetDefault(previousLogger)
+		log.SetOutput(previousWriter)
+		log.SetFlags(previousFlags)
+		common.LogLevel.Set(slog.LevelInfo)
+	})
+	return &output
+}
+
+func TestRequestIDMiddleware_AcceptsOrGeneratesID(t *testing.T) {
+	var seen string
+	handler := common.RequestIDMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		seen = common.RequestID(request.Context())
+	}))
+	serve := func(requestID string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", "/api/v1/accounts/1", nil)
+		if requestID != "" {
+			request.Header.Set(common.RequestIDHeader, requestID)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve("checkout-42")
+	assert.Equal(t, "checkout-42", seen)
+	assert.Equal(t, "checkout-42", recorder.Header().Get(common.RequestIDHeader))
+
+	recorder = serve("")
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, recorder.Header().Get(common.RequestIDHeader))
+
+	serve("forged\nline")
+	assert.Len(t, seen, 32, "IDs that could forge log lines are replaced")
+}
+
+func TestAccessLogMiddleware_LogsRequestWithRequestID(t *testing.T) {
+	output := captureLogs(t)
+	handler := common.RequestIDMiddleware(common.AccessLogMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		common.LogInfoWithContext(request.Context(), "handling")
+		writer.WriteHeader(http.StatusCreated)
+		writer.Write([]byte("created"))
+	})))
+
+	request := httptest.NewRequest("POST", "/api/v1/accounts", nil)
+	request.Header.Set(common.RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Len(t, lines, 2)
+	var handling, access map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &handling))
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &access))
+	assert.Equal(t, "req-1", handling["request_id"])
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "req-1", access["request_id"])
+	assert.Equal(t, float64(201), access["status"])
+	assert.Equal(t, float64(7), access["bytes"])
+	assert.Equal(t, "/api/v1/accounts", access["path"])
+}
+
+func TestAuditLogger_AppendsRequestID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLogger, err := common.NewAuditLogger(path)
+	assert.NoError(t, err)
+
+	auditLogger.LogActionWithContext(common.WithRequestID(context.Background(), "req-7"), "CreateAccount", "Account created with ID: 1")
+	auditLogger.LogActionWithContext(context.Background(), "CreateAccount", "Account created with ID: 2")
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "CreateAccount: Account created with ID: 1, Request ID: req-7\n")
+	assert.Contains(t, string(content), "CreateAccount: Account created with ID: 2\n")
+}
+
+func TestServiceAuditRecords_CarryRequestID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLogger, err := common.NewAuditLogger(path)
+	assert.NoError(t, err)
+	webhookRepo := &mocks.MockWebhookRepository{
+		MockDisableEndpointWithContext: func(ctx context.Context, endpointID int) (bool, error) {
+			return true, nil
+		},
+	}
+	webhookService := service.NewWebhookService(webhookRepo, auditLogger, time.Second, 5, time.Minute, time.Hour)
+
+	disabled, err := webhookService.DisableEndpoint(common.WithRequestID(context.Background(), "req-9"), 3, "admin")
+
+	assert.NoError(t, err)
+	assert.True(t, disabled)
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "Webhook Endpoint Disabled: Endpoint ID: 3, Disabled By: admin, Request ID: req-9\n")
+}
+
+func TestSetLogLevelHandler_ChangesLevelAtRuntime(t *testing.T) {
+	level := new(slog.LevelVar)
+	auditLogger := &mocks.MockAuditLogger{}
+	loggingController := controller.NewLoggingController(level, auditLogger)
+
+	recorder := httptest.NewRecorder()
+	loggingController.SetLogLevelHandler(recorder, httptest.NewRequest("PUT", "/api/v1/admin/log-level", strings.NewReader(`{"level":"debug"}`)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	assert.Equal(t, []string{"Log Level Changed"}, auditLogger.Actions)
+
+	recorder = httptest.NewRecorder()
+	loggingController.SetLogLevelHandler(recorder, httptest.NewRequest("PUT", "/api/v1/admin/log-level", strings.NewReader(`{"level":"verbose"}`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	recorder = httptest.NewRecorder()
+	loggingController.GetLogLevelHandler(recorder, httptest.NewRequest("GET", "/api/v1/admin/log-level", nil))
+	assert.JSONEq(t, `{"level":"debug"}`, recorder.Body.String())
+}
@@ -0,0 +1,154 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"internal-transfers/common"
+	"internal-transfers/metrics"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_WritesTextFormat(t *testing.T) {
+	registry := metrics.NewRegistry()
+	requests := registry.NewCounter("requests_total", "Requests served.", "route")
+	requests.Inc("/b")
+	requests.Add(2, `/a"quoted"`)
+	registry.NewGaugeFunc("open_connections", "Open connections.", func() float64 { return 3 })
+
+	var output bytes.Buffer
+	registry.WriteText(&output)
+
+	assert.Equal(t, `# HELP open_connections Open connections.
+# TYPE open_connections gauge
+open_connections 3
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/a\"quoted\""} 2
+requests_total{route="/b"} 1
+`, output.String())
+}
+
+func TestHistogram_CumulativeBuckets(t *testing.T) {
+	registry := metrics.NewRegistry()
+	histogram := registry.NewHistogram("amount", "Amounts.", []float64{10, 1})
+	histogram.Observe(0.5)
+	histogram.Observe(1)
+	histogram.Observe(5)
+	histogram.Observe(50)
+
+	var output bytes.Buffer
+	registry.WriteText(&output)
+
+	assert.Equal(t, `# HELP amount Amounts.
+# TYPE amount histogram
+amount_bucket{le="1"} 2
+amount_bucket{le="10"} 3
+amount_bucket{le="+Inf"} 4
+amount_sum 56.5
+amount_count 4
+`, output.String())
+}
+
+func TestInstrumentRouter_LabelsRouteTemplateAndStatus(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/metrics-test/{account_id}", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusCreated)
+	}).Methods("POST")
+	handler := metrics.InstrumentRouter(router)
+
+	before := metrics.HTTPRequests.Value("POST", "/api/v1/metrics-test/{account_id}", "201")
+	beforeUnmatched := metrics.HTTPRequests.Value("GET", "unmatched", "404")
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/v1/metrics-test/1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/v1/metrics-test/2", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/no-such-route", nil))
+
+	assert.Equal(t, before+2, metrics.HTTPRequests.Value("POST", "/api/v1/metrics-test/{account_id}", "201"))
+	assert.Equal(t, beforeUnmatched+1, metrics.HTTPRequests.Value("GET", "unmatched", "404"))
+	assert.True(t, metrics.HTTPRequestDuration.Count("POST", "/api/v1/metrics-test/{account_id}", "201") >= 2)
+}
+
+func TestPerformTransaction_RecordsOutcomeMetrics(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, AccountType: "standard", Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, AccountType: "standard", Balance: decimal.NewFromInt(0)},
+	)
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			return nil
+		},
+	}
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+
+	successes := metrics.TransfersTotal.Value(model.TransactionTypeTransfer, "success")
+	insufficient := metrics.TransfersTotal.Value(model.TransactionTypeTransfer, "insufficient_funds")
+	notFound := metrics.TransfersTotal.Value(model.TransactionTypeTransfer, "account_not_found")
+	amounts := metrics.TransferAmount.Count(model.TransactionTypeTransfer)
+
+	_, err := transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(40)))
+	assert.NoError(t, err)
+	_, err = transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(500)))
+	assert.Error(t, err)
+	_, err = transactionService.PerformTransaction(*model.NewTransaction(1, 3, decimal.NewFromInt(10)))
+	assert.Error(t, err)
+
+	assert.Equal(t, successes+1, metrics.TransfersTotal.Value(model.TransactionTypeTransfer, "success"))
+	assert.Equal(t, insufficient+1, metrics.TransfersTotal.Value(model.TransactionTypeTransfer, "insufficient_funds"))
+	assert.Equal(t, notFound+1, metrics.TransfersTotal.Value(model.TransactionTypeTransfer, "account_not_found"))
+	assert.Equal(t, amounts+1, metrics.TransferAmount.Count(model.TransactionTypeTransfer))
+}
+
+func TestPerformTransaction_RetriesDeadlocksAndClassifiesWrappedErrors(t *testing.T) {
+	previousDelay := service.RetryDelay
+	service.RetryDelay = 0
+	t.Cleanup(func() { service.RetryDelay = previousDelay })
+
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, AccountType: "standard", Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, AccountType: "standard", Balance: decimal.NewFromInt(0)},
+	)
+	attempts := 0
+	postErrors := []error{
+		fmt.Errorf("failed to update balance for account 1: %w", &pq.Error{Code: "40P01", Message: "deadlock detected"}),
+		nil,
+		fmt.Errorf("failed to update balance for account 1: %w", &pq.Error{Code: "23505", Message: "duplicate key"}),
+		common.ErrInsufficientBalance,
+	}
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			err := postErrors[attempts]
+			attempts++
+			return err
+		},
+	}
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+	retries := metrics.ServiceRetries.Value("transfer")
+
+	_, err := transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(10)))
+	assert.NoError(t, err, "a deadlock is retried")
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, retries+1, metrics.ServiceRetries.Value("transfer"))
+
+	_, err = transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(10)))
+	assert.Equal(t, 3, attempts, "other database errors are not retried")
+	assert.ErrorIs(t, err, service.ErrPostingFailed)
+	assert.Equal(t, "posting_failed", service.TransferErrorClass(err))
+
+	_, err = transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(10)))
+	assert.Equal(t, "insufficient_funds", service.TransferErrorClass(err), "a guarded debit failing at posting")
+
+	_, err = transactionService.PerformTransaction(*model.NewTransaction(1, 9, decimal.NewFromInt(10)))
+	assert.ErrorIs(t, err, service.ErrAccountNotFound)
+	assert.Equal(t, "timeout", service.TransferErrorClass(fmt.Errorf("failed to save transaction: %w", context.DeadlineExceeded)))
+}
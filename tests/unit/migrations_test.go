@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"internal-transfers/migrations"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations_EmbeddedSetIsComplete(t *testing.T) {
+	loaded, err := migrations.Load()
+
+	assert.NoError(t, err)
+	assert.Len(t, loaded, 10)
+	for i, migration := range loaded {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Up, migration.Name)
+		assert.NotEmpty(t, migration.Down, migration.Name)
+	}
+	assert.Equal(t, "create_accounts_and_transactions", loaded[0].Name)
+
+	// Every table the repositories use is created by some migration
+	var allUp strings.Builder
+	for _, migration := range loaded {
+		allUp.WriteString(migration.Up)
+	}
+	for _, table := range []string{"accounts", "transactions", "ledger_entries", "fee_schedules", "interest_configs",
+		"interest_accruals", "account_balance_snapshots", "reconciliation_runs", "reconciliation_mismatches",
+		"balance_adjustments", "pending_operations", "api_keys", "outbox_events", "webhook_endpoints", "webhook_deliveries",
+		"account_event_store", "account_aggregate_snapshots"} {
+		assert.Contains(t, allUp.String(), "CREATE TABLE IF NOT EXISTS "+table+" (", table)
+	}
+}
+
+func TestPlanMigrations(t *testing.T) {
+	loaded, _ := migrations.Load()
+	versions := func(steps []migrations.Migration) []int {
+		result := []int{}
+		for _, step := range steps {
+			result = append(result, step.Version)
+		}
+		return result
+	}
+
+	steps, up, err := migrations.Plan(loaded, 5, 8)
+	assert.NoError(t, err)
+	assert.True(t, up)
+	assert.Equal(t, []int{6, 7, 8}, versions(steps))
+
+	steps, up, err = migrations.Plan(loaded, 5, 2)
+	assert.NoError(t, err)
+	assert.False(t, up)
+	assert.Equal(t, []int{5, 4, 3}, versions(steps), "rolls back newest first")
+
+	steps, _, err = migrations.Plan(loaded, 10, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, steps)
+
+	_, _, err = migrations.Plan(loaded, 0, 11)
+	assert.ErrorContains(t, err, "version 11 does not exist")
+	_, _, err = migrations.Plan(loaded, 11, 10)
+	assert.ErrorContains(t, err, "newer than the 10 migrations")
+}
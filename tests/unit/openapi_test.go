@@ -0,0 +1,255 @@
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"internal-transfers/api/v1"
+	"internal-transfers/auth"
+	"internal-transfers/model"
+	"internal-transfers/openapi"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadOpenAPI(t *testing.T) *openapi.Document {
+	document, err := v1.LoadOpenAPI()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return document
+}
+
+// Registers every v1 route the server registers, with services left nil since no request is served
+func registeredRoutes(t *testing.T) []string {
+	router := mux.NewRouter()
+	authorizer := auth.NewAuthorizer(nil)
+	v1.RegisterAccountRoutes(router, nil, nil, authorizer)
+	v1.RegisterTransactionRoutes(router, nil, nil, authorizer, nil, nil)
+	v1.RegisterFeeRoutes(router, nil, authorizer)
+	v1.RegisterInterestRoutes(router, nil, authorizer)
+	v1.RegisterBalanceRoutes(router, nil, authorizer)
+	v1.RegisterStatementRoutes(router, nil, authorizer)
+	v1.RegisterAccountEventRoutes(router, nil, nil, authorizer)
+	v1.RegisterAccountAggregateRoutes(router, nil, authorizer)
+	v1.RegisterReconciliationRoutes(router, nil, authorizer)
+	v1.RegisterAdjustmentRoutes(router, nil, nil, authorizer)
+	v1.RegisterApprovalRoutes(router, nil, authorizer)
+	v1.RegisterAPIKeyRoutes(router, nil, authorizer)
+	v1.RegisterWebhookRoutes(router, nil, authorizer)
+	v1.RegisterDatabaseRoutes(router, &sqlx.DB{}, authorizer)
+	v1.RegisterLoggingRoutes(router, nil, authorizer)
+	v1.RegisterOpenAPIRoutes(router)
+
+	var routes []string
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil // subrouter prefixes carry no methods
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			routes = append(routes, method+" "+openapi.PathTemplate(template))
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	return routes
+}
+
+func TestOpenAPI_DocumentsEveryRegisteredRoute(t *testing.T) {
+	document := loadOpenAPI(t)
+	routes := registeredRoutes(t)
+	assert.Contains(t, routes, "GET /api/v1/accounts/{account_id}")
+	assert.Contains(t, routes, "POST /api/v1/accounts")
+	assert.Contains(t, routes, "POST /api/v1/transactions")
+
+	for _, route := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		assert.NotNil(t, document.Operation(method, path), "%s is registered but missing from api/v1/openapi.json", route)
+	}
+
+	var documented []string
+	for path, item := range document.Paths {
+		for method := range item {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+	for _, operation := range documented {
+		assert.Contains(t, routes, operation, "%s is documented but not registered", operation)
+	}
+}
+
+// JSON names of the fields of a request type, including embedded structs
+func jsonFieldNames(inputType reflect.Type) []string {
+	var names []string
+	for i := 0; i < inputType.NumField(); i++ {
+		field := inputType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" {
+			names = append(names, jsonFieldNames(field.Type)...)
+			continue
+		}
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestOpenAPI_RequestSchemasMatchModelInputs(t *testing.T) {
+	document := loadOpenAPI(t)
+	inputs := map[string]any{
+		"createAccount":       model.CreateAccountInput{},
+		"createTransaction":   model.TransactionRequest{},
+		"createAdjustment":    model.CreateAdjustmentInput{},
+		"rejectOperation":     model.RejectOperationInput{},
+		"createAPIKey":        model.CreateAPIKeyInput{},
+		"setLogLevel":         model.LogLevel{},
+		"createFeeSchedule":   model.CreateFeeScheduleInput{},
+		"configureInterest":   model.InterestConfigInput{},
+		"approveCorrection":   model.ApproveCorrectionInput{},
+		"createWebhook":       model.CreateWebhookEndpointInput{},
+		"replayWebhookEvents": model.ReplayWebhookEventsInput{},
+	}
+
+	for path, item := range document.Paths {
+		for method, operation := range item {
+			if operation.RequestBody == nil {
+				continue
+			}
+			input, found := inputs[operation.OperationID]
+			if !assert.True(t, found, "%s %s has a request body but no model input to check it against", method, path) {
+				continue
+			}
+			schema := operation.RequestBody.Content["application/json"].Schema
+			if schema.Ref != "" {
+				schema = document.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+			}
+			var properties []string
+			for name := range schema.Properties {
+				properties = append(properties, name)
+			}
+			sort.Strings(properties)
+			assert.Equal(t, jsonFieldNames(reflect.TypeOf(input)), properties, "schema properties of %s", operation.OperationID)
+			delete(inputs, operation.OperationID)
+		}
+	}
+	assert.Empty(t, inputs, "model inputs without a documented request body")
+}
+
+func TestOpenAPI_PathTemplate(t *testing.T) {
+	assert.Equal(t, "/api/v1/accounts/{account_id}", openapi.PathTemplate("/api/v1/accounts/{account_id:[0-9]+}"))
+	assert.Equal(t, "/runs/{run_id}/mismatches/{mismatch_id}", openapi.PathTemplate("/runs/{run_id:[0-9]+}/mismatches/{mismatch_id:[0-9]{1,9}}"))
+	assert.Equal(t, "/api/v1/transactions", openapi.PathTemplate("/api/v1/transactions"))
+}
+
+func TestOpenAPI_LoadRejectsBrokenDocuments(t *testing.T) {
+	_, err := openapi.Load([]byte(`{"openapi": "3.1.0", "paths": {"/a": {"post": {"requestBody": {"content":
+		{"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}}}}}}`))
+	assert.ErrorContains(t, err, `unknown schema "Missing"`)
+
+	_, err = openapi.Load([]byte(`{"openapi": "3.1.0", "components": {"schemas": {"Code": {"type": "string", "pattern": "("}}}}`))
+	assert.ErrorContains(t, err, "invalid pattern")
+
+	_, err = openapi.Load([]byte(`{"swagger": "2.0"}`))
+	assert.ErrorContains(t, err, "unsupported OpenAPI version")
+}
+
+func TestOpenAPI_ValidationMiddleware(t *testing.T) {
+	document := loadOpenAPI(t)
+	var received string
+	handler := func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		received = string(body)
+		writer.WriteHeader(http.StatusOK)
+	}
+	router := mux.NewRouter()
+	router.Use(document.ValidationMiddleware)
+	router.HandleFunc("/api/v1/transactions", handler).Methods("POST")
+	router.HandleFunc("/api/v1/accounts/{account_id:[0-9]+}", handler).Methods("GET")
+	router.HandleFunc("/api/v1/admin/api-keys", handler).Methods("POST")
+
+	serve := func(method string, path string, body string) *httptest.ResponseRecorder {
+		received = ""
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	valid := `{"source_account_id": 1, "destination_account_id": 2, "amount": "10.50"}`
+	recorder := serve("POST", "/api/v1/transactions", valid)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, valid, received, "the handler reads the body the middleware validated")
+
+	recorder = serve("POST", "/api/v1/transactions", `{"source_account_id": 1, "destination_account_id": 2, "amount": 10.5}`)
+	assert.Equal(t, http.StatusOK, recorder.Code, "amounts may be JSON numbers")
+
+	cases := map[string]string{
+		`{"source_account_id": 1, "amount": "10"}`:                                           `missing required property "destination_account_id"`,
+		`{"source_account_id": "1", "destination_account_id": 2, "amount": "10"}`:            "body.source_account_id: expected integer",
+		`{"source_account_id": 1.5, "destination_account_id": 2, "amount": "10"}`:            "body.source_account_id: expected integer",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "ten"}`:             "body.amount: must match",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": true}`:              "body.amount: expected string or number",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "1", "ammount": 1}`: `unknown property "ammount"`,
+		`{"source_account_id": 0, "destination_account_id": 2, "amount": "10"}`:              "body.source_account_id: must be at least 1",
+		`{"source_account_id": 1,`: "not valid JSON",
+		`[]`:                       "body: expected object",
+		``:                         "body: required",
+	}
+	for body, expected := range cases {
+		recorder = serve("POST", "/api/v1/transactions", body)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, body)
+		assert.Contains(t, recorder.Body.String(), expected, body)
+		assert.Empty(t, received, "%s reached the handler", body)
+	}
+
+	recorder = serve("POST", "/api/v1/admin/api-keys", `{"name": "ci", "roles": ["viewer", "root"]}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "body.roles[1]: must be one of")
+
+	recorder = serve("POST", "/api/v1/admin/api-keys", `{"name": "ci", "roles": []}`)
+	assert.Contains(t, recorder.Body.String(), "body.roles: must have at least 1 items")
+
+	recorder = serve("GET", "/api/v1/accounts/7", "")
+	assert.Equal(t, http.StatusOK, recorder.Code, "operations without a request body are not validated")
+
+	request := httptest.NewRequest("POST", "/api/v1/transactions", strings.NewReader(valid))
+	request.Header.Set("Content-Type", "text/plain")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `unsupported Content-Type "text/plain"`)
+
+	large := fmt.Sprintf(`{"source_account_id": 1, "destination_account_id": 2, "amount": "1", "pad": %q}`, strings.Repeat("x", openapi.MaxBodyBytes))
+	recorder = serve("POST", "/api/v1/transactions", large)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
+
+func TestOpenAPI_ServesDocument(t *testing.T) {
+	router := mux.NewRouter()
+	v1.RegisterOpenAPIRoutes(router)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var document map[string]any
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+	assert.Equal(t, "3.1.0", document["openapi"])
+}
@@ -0,0 +1,148 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"internal-transfers/auth"
+	"internal-transfers/common"
+	"internal-transfers/controller"
+	"internal-transfers/model"
+	"internal-transfers/ratelimit"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientLimiter_RejectsOverBurstPerPrincipal(t *testing.T) {
+	limiter := ratelimit.NewClientLimiter(ratelimit.NewMemoryStore(), ratelimit.PerMinute(60, 2))
+	handler := limiter.Middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	serve := func(principalID string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", "/api/v1/accounts/1", nil)
+		request = request.WithContext(auth.WithPrincipal(request.Context(), &auth.Principal{ID: principalID}))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := serve("api-key:1")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "2", first.Header().Get(ratelimit.LimitHeader))
+	assert.Equal(t, "1", first.Header().Get(ratelimit.RemainingHeader))
+	assert.Equal(t, http.StatusOK, serve("api-key:1").Code)
+
+	limited := serve("api-key:1")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "1", limited.Header().Get(ratelimit.RetryAfterHeader))
+	assert.Equal(t, "0", limited.Header().Get(ratelimit.RemainingHeader))
+	assert.Equal(t, "2", limited.Header().Get(ratelimit.ResetHeader))
+
+	assert.Equal(t, http.StatusOK, serve("api-key:2").Code, "other clients have their own bucket")
+}
+
+func TestMemoryStore_Refills(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Rate: 20, Burst: 1}
+
+	decision, _ := store.Take(context.Background(), "key", limit)
+	assert.True(t, decision.Allowed)
+	decision, _ = store.Take(context.Background(), "key", limit)
+	assert.False(t, decision.Allowed)
+	assert.True(t, decision.RetryAfter > 0 && decision.RetryAfter <= 50*time.Millisecond)
+
+	time.Sleep(60 * time.Millisecond)
+	decision, _ = store.Take(context.Background(), "key", limit)
+	assert.True(t, decision.Allowed)
+}
+
+func TestSourceAccountLimiter_KeysByBodyAndKeepsBody(t *testing.T) {
+	limiter := ratelimit.NewSourceAccountLimiter(ratelimit.NewMemoryStore(), ratelimit.PerMinute(60, 1))
+	var received string
+	handler := limiter.Middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		received = string(body)
+	}))
+	serve := func(body string) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/transactions", bytes.NewBufferString(body)))
+		return recorder.Code
+	}
+
+	body := `{"source_account_id":1,"destination_account_id":2,"amount":"5"}`
+	assert.Equal(t, http.StatusOK, serve(body))
+	assert.Equal(t, body, received)
+	assert.Equal(t, http.StatusTooManyRequests, serve(`{"source_account_id":1,"destination_account_id":3,"amount":"5"}`))
+	assert.Equal(t, http.StatusOK, serve(`{"source_account_id":2,"destination_account_id":1,"amount":"5"}`))
+	assert.Equal(t, http.StatusOK, serve(`not json`), "malformed bodies are left to the handler")
+}
+
+func TestLimiter_AllowsWhenStoreFails(t *testing.T) {
+	store := &mocks.MockRateLimitStore{
+		MockTake: func(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+			return ratelimit.Decision{}, errors.New("store unavailable")
+		},
+	}
+	limiter := ratelimit.NewClientLimiter(store, ratelimit.PerMinute(60, 1))
+	recorder := httptest.NewRecorder()
+	limiter.Middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {})).
+		ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/accounts/1", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestTransactionController_LimitsSourceAccountAfterAccessCheck(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, Balance: decimal.NewFromInt(100)},
+	)
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			return nil
+		},
+	}
+	authorizer := auth.NewAuthorizer(&mocks.MockAuditLogger{})
+	transactionController := &controller.TransactionController{
+		Service:        service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{}),
+		Authorizer:     authorizer,
+		AccountLimiter: ratelimit.NewSourceAccountLimiter(ratelimit.NewMemoryStore(), ratelimit.PerMinute(60, 1)),
+	}
+	router := mux.NewRouter()
+	router.Handle("/api/v1/transactions", authorizer.Require(auth.PermissionTransfersCreate, transactionController.CreateTransactionHandler)).Methods("POST")
+	transfer, _ := json.Marshal(model.TransactionRequest{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(10)})
+
+	intruder := &auth.Principal{ID: "api-key:5", Roles: []string{auth.RoleOperator}, AccountIDs: []int{1}}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusForbidden, serveAs(router, intruder, "POST", "/api/v1/transactions", transfer))
+	}
+
+	owner := &auth.Principal{ID: "api-key:6", Roles: []string{auth.RoleOperator}, AccountIDs: []int{2}}
+	assert.Equal(t, http.StatusOK, serveAs(router, owner, "POST", "/api/v1/transactions", transfer), "denied transfers spend no tokens")
+	assert.Equal(t, http.StatusTooManyRequests, serveAs(router, owner, "POST", "/api/v1/transactions", transfer))
+}
+
+func TestIPLimiter_LimitsFailedAuthentication(t *testing.T) {
+	limiter := ratelimit.NewIPLimiter(ratelimit.NewMemoryStore(), ratelimit.PerMinute(60, 2))
+	handler := limiter.Middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+	}))
+	serve := func(remoteAddr string) int {
+		request := httptest.NewRequest("GET", "/api/v1/accounts/1", nil)
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve("203.0.113.7:5000"))
+	assert.Equal(t, http.StatusUnauthorized, serve("203.0.113.7:5001"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("203.0.113.7:5002"), "the port does not matter")
+	assert.Equal(t, http.StatusUnauthorized, serve("198.51.100.1:5000"))
+}
@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"context"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareAccountHistory(t *testing.T) {
+	consistent := model.AccountReconciliation{AccountID: 1, StoredBalance: decimal.NewFromInt(150), InitialBalance: decimal.NewFromInt(100),
+		TransactionTotal: decimal.NewFromInt(50), LedgerTotal: decimal.NewFromInt(50)}
+	assert.Nil(t, service.CompareAccountHistory(consistent))
+
+	drifted := consistent
+	drifted.StoredBalance = decimal.NewFromInt(160)
+	mismatch := service.CompareAccountHistory(drifted)
+	assert.NotNil(t, mismatch)
+	assert.True(t, decimal.NewFromInt(10).Equal(mismatch.Difference))
+	assert.Equal(t, model.MismatchStatusOpen, mismatch.Status)
+
+	// Transactions without ledger entries are reported even when the stored balance agrees
+	missingLedger := consistent
+	missingLedger.LedgerTotal = decimal.Zero
+	mismatch = service.CompareAccountHistory(missingLedger)
+	assert.NotNil(t, mismatch)
+	assert.True(t, mismatch.Difference.IsZero())
+	assert.True(t, decimal.NewFromInt(100).Equal(mismatch.LedgerBalance))
+}
+
+func TestApproveCorrection_BooksDifferenceAgainstSuspense(t *testing.T) {
+	mismatch := &model.ReconciliationMismatch{MismatchID: 3, RunID: 1, AccountID: 7, Difference: decimal.NewFromInt(-25), Status: model.MismatchStatusOpen}
+	var correctedBy string
+	reconciliationRepo := &mocks.MockReconciliationRepository{
+		MockGetMismatchWithContext: func(ctx context.Context, runID int, mismatchID int) (*model.ReconciliationMismatch, error) {
+			return mismatch, nil
+		},
+		MockGetAccountReconciliationWithContext: func(ctx context.Context, accountID int) (*model.AccountReconciliation, error) {
+			return &model.AccountReconciliation{AccountID: 7, StoredBalance: decimal.NewFromInt(75), InitialBalance: decimal.NewFromInt(100)}, nil
+		},
+	}
+
+	var posted []model.LedgerEntry
+	reconciliationRepo.MockCorrectMismatchWithContext = func(ctx context.Context, mismatchID int, approvedBy string, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+		assert.Equal(t, 3, mismatchID)
+		assert.Equal(t, model.TransactionTypeReconciliation, transaction.TransactionType)
+		assert.Equal(t, 7, transaction.SourceAccountID)
+		assert.Equal(t, 900, transaction.DestinationAccountID)
+		transaction.TransactionID = 42
+		correctedBy = approvedBy
+		posted = entries
+		return true, nil
+	}
+
+	reconciliationService := service.NewReconciliationService(reconciliationRepo, nil, 900)
+	_, err := reconciliationService.ApproveCorrection(context.Background(), 1, 3, "ops-lead")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ops-lead", correctedBy)
+	assert.Len(t, posted, 2)
+	assert.True(t, posted[0].RecordOnly)
+	assert.True(t, decimal.NewFromInt(-25).Equal(posted[0].Amount))
+	assert.Equal(t, 900, posted[1].AccountID)
+	assert.True(t, posted[1].AllowNegative)
+	assert.True(t, decimal.NewFromInt(25).Equal(posted[1].Amount))
+}
+
+func TestApproveCorrection_RejectsStaleDifference(t *testing.T) {
+	reconciliationRepo := &mocks.MockReconciliationRepository{
+		MockGetMismatchWithContext: func(ctx context.Context, runID int, mismatchID int) (*model.ReconciliationMismatch, error) {
+			return &model.ReconciliationMismatch{MismatchID: 3, AccountID: 7, Difference: decimal.NewFromInt(10), Status: model.MismatchStatusOpen}, nil
+		},
+		MockGetAccountReconciliationWithContext: func(ctx context.Context, accountID int) (*model.AccountReconciliation, error) {
+			return &model.AccountReconciliation{AccountID: 7, StoredBalance: decimal.NewFromInt(100), InitialBalance: decimal.NewFromInt(100)}, nil
+		},
+	}
+
+	reconciliationService := service.NewReconciliationService(reconciliationRepo, nil, 900)
+	_, err := reconciliationService.ApproveCorrection(context.Background(), 1, 3, "ops-lead")
+
+	var validationError *common.ValidationError
+	assert.ErrorAs(t, err, &validationError)
+}
+
+func TestApproveCorrection_BooksOnceWhenApprovedConcurrently(t *testing.T) {
+	reconciliationRepo := &mocks.MockReconciliationRepository{
+		MockGetMismatchWithContext: func(ctx context.Context, runID int, mismatchID int) (*model.ReconciliationMismatch, error) {
+			return &model.ReconciliationMismatch{MismatchID: 3, AccountID: 7, Difference: decimal.NewFromInt(10), Status: model.MismatchStatusOpen}, nil
+		},
+		MockGetAccountReconciliationWithContext: func(ctx context.Context, accountID int) (*model.AccountReconciliation, error) {
+			return &model.AccountReconciliation{AccountID: 7, StoredBalance: decimal.NewFromInt(110), InitialBalance: decimal.NewFromInt(100)}, nil
+		},
+		// Another approval moved the mismatch out of open between the read and the update
+		MockCorrectMismatchWithContext: func(ctx context.Context, mismatchID int, approvedBy string, transaction *model.Transaction, entries []model.LedgerEntry) (bool, error) {
+			return false, nil
+		},
+	}
+
+	reconciliationService := service.NewReconciliationService(reconciliationRepo, nil, 900)
+	_, err := reconciliationService.ApproveCorrection(context.Background(), 1, 3, "ops-lead")
+
+	var validationError *common.ValidationError
+	assert.ErrorAs(t, err, &validationError)
+}
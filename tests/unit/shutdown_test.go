@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"context"
+	"internal-transfers/common"
+	"internal-transfers/jobs"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionService_WaitsForAndAbortsInFlightTransfers(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, AccountType: "standard", Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, AccountType: "standard", Balance: decimal.NewFromInt(0)},
+	)
+	posting := make(chan struct{})
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			close(posting)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+
+	requestCtx, cancelRequest := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		_, err := transactionService.PerformTransactionWithContext(requestCtx, *model.NewTransaction(1, 2, decimal.NewFromInt(10)))
+		result <- err
+	}()
+	<-posting
+	cancelRequest()
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, transactionService.WaitForTransfers(waitCtx), context.DeadlineExceeded, "a cancelled request does not abandon its transfer")
+
+	transactionService.AbortTransfers()
+	assert.ErrorContains(t, <-result, "context canceled")
+	assert.NoError(t, transactionService.WaitForTransfers(context.Background()))
+
+	_, err := transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(10)))
+	assert.ErrorIs(t, err, service.ErrTransfersAborted)
+}
+
+func TestScheduler_ShutdownGivesUpAtDeadline(t *testing.T) {
+	release := make(chan struct{})
+	scheduler := jobs.NewScheduler()
+	scheduler.Register(jobs.Job{
+		Name:       "stuck",
+		Interval:   time.Hour,
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			<-release
+			return nil
+		},
+	})
+	scheduler.Start(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Error(t, scheduler.Shutdown(ctx))
+
+	close(release)
+	assert.NoError(t, scheduler.Shutdown(context.Background()))
+}
+
+func TestAuditLogger_CloseFlushesRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLogger, err := common.NewAuditLogger(path)
+	assert.NoError(t, err)
+
+	auditLogger.LogAction("Transaction Completed", "Transaction from Account 1 to Account 2")
+	assert.NoError(t, auditLogger.Close())
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "Transaction Completed: Transaction from Account 1 to Account 2")
+	assert.Error(t, auditLogger.CheckWritable(), "writes after closing fail")
+}
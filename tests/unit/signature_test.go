@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"bytes"
+	"internal-transfers/auth"
+	"internal-transfers/tests/mocks"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var signingSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func signedTransferRequest(secret []byte, signedAt time.Time, nonce string, body string) *http.Request {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	request := httptest.NewRequest("POST", "/api/v1/transactions", bytes.NewBufferString(body))
+	request.Header.Set(auth.SignatureClientHeader, "payments-svc")
+	request.Header.Set(auth.SignatureTimestampHeader, timestamp)
+	request.Header.Set(auth.SignatureNonceHeader, nonce)
+	request.Header.Set(auth.SignatureHeader, auth.ComputeSignature(secret, "POST", "/api/v1/transactions", timestamp, nonce, []byte(body)))
+	return request
+}
+
+func TestSignatureVerifier(t *testing.T) {
+	verifier := auth.NewSignatureVerifier(map[string][]byte{"payments-svc": signingSecret}, 5*time.Minute, true, &mocks.MockAuditLogger{})
+	var received string
+	handler := verifier.Middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		received = string(body)
+	}))
+	serve := func(request *http.Request) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+	body := `{"source_account_id":1,"destination_account_id":2,"amount":"10"}`
+	now := time.Now()
+
+	assert.Equal(t, http.StatusOK, serve(signedTransferRequest(signingSecret, now, "n-1", body)))
+	assert.Equal(t, body, received, "handlers still see the body")
+
+	assert.Equal(t, http.StatusUnauthorized, serve(signedTransferRequest(signingSecret, now, "n-1", body)), "replayed nonce")
+	assert.Equal(t, http.StatusUnauthorized, serve(signedTransferRequest(signingSecret, now.Add(-10*time.Minute), "n-2", body)), "stale timestamp")
+	assert.Equal(t, http.StatusUnauthorized, serve(signedTransferRequest([]byte("wrong-secret-wrong-secret-wrong!!"), now, "n-3", body)), "wrong secret")
+
+	tampered := signedTransferRequest(signingSecret, now, "n-4", body)
+	tampered.Body = io.NopCloser(bytes.NewBufferString(`{"source_account_id":1,"destination_account_id":3,"amount":"10"}`))
+	assert.Equal(t, http.StatusUnauthorized, serve(tampered), "tampered body")
+
+	unsigned := httptest.NewRequest("POST", "/api/v1/transactions", bytes.NewBufferString(body))
+	assert.Equal(t, http.StatusUnauthorized, serve(unsigned), "unsigned while required")
+
+	verifier.Required = false
+	assert.Equal(t, http.StatusOK, serve(httptest.NewRequest("POST", "/api/v1/transactions", bytes.NewBufferString(body))), "unsigned while optional")
+}
+
+func TestLoadSigningSecrets_WrapsTheReadError(t *testing.T) {
+	_, err := auth.LoadSigningSecrets(filepath.Join(t.TempDir(), "missing.json"))
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
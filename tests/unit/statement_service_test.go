@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStatementService(outputDir string) *service.StatementService {
+	account := model.Account{AccountID: 1, InitialBalance: decimal.NewFromInt(100), CreatedAt: date(2025, time.January, 1)}
+	accountRepo := newAccountRepository(account)
+	accountRepo.MockListAccountsWithContext = func(ctx context.Context) ([]model.Account, error) {
+		return []model.Account{account}, nil
+	}
+
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockSumLedgerEntriesBetweenWithContext: func(ctx context.Context, accountID int, from, to time.Time) (decimal.Decimal, error) {
+			return decimal.NewFromInt(50), nil
+		},
+		MockListStatementLinesWithContext: func(ctx context.Context, accountID int, from, to time.Time) ([]model.StatementLine, error) {
+			return []model.StatementLine{
+				{EntryID: 1, TransactionID: 7, PostedAt: date(2025, time.February, 3), TransactionType: "transfer", EntryType: "transfer", CounterpartyAccountID: 2, Amount: decimal.NewFromInt(-30)},
+				{EntryID: 2, TransactionID: 7, PostedAt: date(2025, time.February, 3), TransactionType: "transfer", EntryType: "fee", CounterpartyAccountID: 9, Amount: decimal.NewFromInt(-1)},
+				{EntryID: 5, TransactionID: 8, PostedAt: date(2025, time.February, 10), TransactionType: "transfer", EntryType: "transfer", CounterpartyAccountID: 3, Amount: decimal.NewFromInt(20)},
+			}, nil
+		},
+	}
+	snapshotRepo := &mocks.MockBalanceSnapshotRepository{
+		MockGetLatestSnapshotBeforeWithContext: func(ctx context.Context, accountID int, before time.Time) (*model.BalanceSnapshot, error) {
+			return nil, nil
+		},
+	}
+
+	balanceService := service.NewBalanceService(accountRepo, transactionRepo, snapshotRepo)
+	return service.NewStatementService(accountRepo, transactionRepo, balanceService, nil, outputDir, []string{"csv", "pdf"})
+}
+
+func TestGenerateStatement_RunningBalance(t *testing.T) {
+	statementService := newStatementService("")
+
+	statement, err := statementService.GenerateStatement(1, date(2025, time.February, 1), date(2025, time.February, 28))
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(150).Equal(statement.OpeningBalance))
+	assert.True(t, decimal.NewFromInt(119).Equal(statement.Lines[1].RunningBalance))
+	assert.True(t, decimal.NewFromInt(139).Equal(statement.ClosingBalance))
+	assert.True(t, decimal.NewFromInt(31).Equal(statement.TotalDebits))
+	assert.True(t, decimal.NewFromInt(20).Equal(statement.TotalCredits))
+}
+
+func TestRenderStatement_CSVAndPDF(t *testing.T) {
+	statementService := newStatementService("")
+	statement, err := statementService.GenerateStatement(1, date(2025, time.February, 1), date(2025, time.February, 28))
+	assert.NoError(t, err)
+
+	var csvOut bytes.Buffer
+	assert.NoError(t, service.RenderStatement(&csvOut, statement, model.StatementFormatCSV))
+	reader := csv.NewReader(&csvOut)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"opening_balance", "150"}, records[3])
+	assert.Equal(t, []string{"closing_balance", "139"}, records[len(records)-1])
+
+	var pdfOut bytes.Buffer
+	assert.NoError(t, service.RenderStatement(&pdfOut, statement, model.StatementFormatPDF))
+	assert.True(t, strings.HasPrefix(pdfOut.String(), "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(pdfOut.String(), "%%EOF\n"))
+}
+
+func TestGenerateMonthlyStatements_WritesEachFormatOnce(t *testing.T) {
+	outputDir := t.TempDir()
+	statementService := newStatementService(outputDir)
+
+	now := date(2025, time.March, 2)
+	assert.NoError(t, statementService.GenerateMonthlyStatements(context.Background(), now))
+
+	for _, name := range []string{"account-1.csv", "account-1.pdf"} {
+		_, err := os.Stat(filepath.Join(outputDir, "2025-02", name))
+		assert.NoError(t, err, name)
+	}
+
+	// A second run for the same month leaves existing files alone
+	path := filepath.Join(outputDir, "2025-02", "account-1.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("kept"), 0o644))
+	assert.NoError(t, statementService.GenerateMonthlyStatements(context.Background(), now))
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, "kept", string(content))
+}
@@ -112,36 +112,3 @@ func TestCreateAccountHandler_AccountExists(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
-
-func TestUpdateAccountHandler_Success(t *testing.T) {
-	mockService := new(mocks.MockAccountService)
-	mockAccount := &model.Account{
-		AccountID: 1,
-		Balance:   decimal.NewFromInt(100),
-	}
-	mockService.On("GetAccountByID", 1).Return(mockAccount, nil)
-
-	mockService.On("UpdateAccountBalance", 1, decimal.NewFromInt(200)).Return(nil)
-
-	mockAuditLogger := new(mocks.MockAuditLogger)
-
-	controller := controller.NewAccountController(mockService, mockAuditLogger)
-
-	accountInput := model.UpdateAccountInput{
-		AccountID: 1,
-		Balance:   decimal.NewFromInt(200),
-	}
-	body, _ := json.Marshal(accountInput)
-
-	req, err := http.NewRequest("PUT", "/accounts/1", bytes.NewReader(body))
-	assert.NoError(t, err)
-
-	rr := httptest.NewRecorder()
-
-	handler := http.HandlerFunc(controller.UpdateAccountHandler)
-	handler.ServeHTTP(rr, req)
-
-	assert.Equal(t, http.StatusOK, rr.Code)
-
-	mockService.AssertExpectations(t)
-}
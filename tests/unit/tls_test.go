@@ -0,0 +1,165 @@
+package unit
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"internal-transfers/auth"
+	"internal-transfers/tests/mocks"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Issues a certificate for subject signed by parent, self-signed when parent is nil
+func issueCertificate(t *testing.T, subject pkix.Name, serial int64, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               subject,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return certificate, key
+}
+
+func writePEM(t *testing.T, path string, certificate *x509.Certificate, key *ecdsa.PrivateKey) {
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
+	assert.NoError(t, os.WriteFile(path, content, 0o600))
+	if key != nil {
+		der, err := x509.MarshalECPrivateKey(key)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(path+".key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+	}
+}
+
+func TestTLSReloader_MutualTLSAndReload(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := issueCertificate(t, pkix.Name{CommonName: "test-ca"}, 1, true, nil, nil)
+	serverCert, serverKey := issueCertificate(t, pkix.Name{CommonName: "server-1"}, 2, false, ca, caKey)
+	clientCert, clientKey := issueCertificate(t, pkix.Name{CommonName: "payments-svc", Organization: []string{"Acme"}}, 3, false, ca, caKey)
+	caFile, certFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "server.pem")
+	writePEM(t, caFile, ca, nil)
+	writePEM(t, certFile, serverCert, serverKey)
+
+	base, err := auth.NewServerTLSConfig("1.2", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", auth.ClientAuthRequire)
+	assert.NoError(t, err)
+	reloader, err := auth.NewTLSReloader(certFile, certFile+".key", caFile, base)
+	assert.NoError(t, err)
+
+	authenticator := auth.NewAuthenticator(&mocks.MockAPIKeyRepository{}, nil, &mocks.MockAuditLogger{})
+	authenticator.ClientCertificates = map[string]auth.ClientCertificateIdentity{
+		"CN=payments-svc,O=Acme": {Name: "payments", Roles: []string{auth.RoleOperator}, AccountIDs: []int{7}},
+	}
+	var seen *auth.Principal
+	server := httptest.NewUnstartedServer(authenticator.Middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		seen = auth.PrincipalFromContext(request.Context())
+	})))
+	server.TLS = reloader.TLSConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	var servedBy string
+	client := func(certificates []tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certificates,
+			VerifyConnection: func(state tls.ConnectionState) error {
+				servedBy = state.PeerCertificates[0].Subject.CommonName
+				return nil
+			},
+		}}}
+	}
+	clientPair := tls.Certificate{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey}
+
+	response, err := client([]tls.Certificate{clientPair}).Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "cert:CN=payments-svc,O=Acme", seen.ID)
+	assert.Equal(t, []int{7}, seen.AccountIDs)
+	assert.Equal(t, "server-1", servedBy)
+
+	_, err = client(nil).Get(server.URL)
+	assert.Error(t, err, "client certificate is required")
+
+	// A rotated certificate is served to new connections after Reload
+	rotatedCert, rotatedKey := issueCertificate(t, pkix.Name{CommonName: "server-2"}, 4, false, ca, caKey)
+	writePEM(t, certFile, rotatedCert, rotatedKey)
+	assert.NoError(t, reloader.Reload())
+	_, err = client([]tls.Certificate{clientPair}).Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "server-2", servedBy)
+
+	// A broken file keeps the current certificate
+	assert.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	assert.Error(t, reloader.Reload())
+	_, err = client([]tls.Certificate{clientPair}).Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "server-2", servedBy)
+}
+
+func TestNewServerTLSConfig_RejectsInvalidSettings(t *testing.T) {
+	_, err := auth.NewServerTLSConfig("1.1", "", "")
+	assert.Error(t, err)
+	_, err = auth.NewServerTLSConfig("1.2", "TLS_RSA_WITH_RC4_128_SHA", "")
+	assert.Error(t, err)
+	_, err = auth.NewServerTLSConfig("1.2", "", "optional")
+	assert.Error(t, err)
+}
+
+func TestTLSReloader_OffersNextProtosInEveryHandshake(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := issueCertificate(t, pkix.Name{CommonName: "server-1"}, 1, true, nil, nil)
+	certFile := filepath.Join(dir, "server.pem")
+	writePEM(t, certFile, serverCert, serverKey)
+	base, err := auth.NewServerTLSConfig("1.2", "", "")
+	assert.NoError(t, err)
+	reloader, err := auth.NewTLSReloader(certFile, certFile+".key", "", base)
+	assert.NoError(t, err)
+
+	config := reloader.TLSConfig()
+	config.NextProtos = []string{"h2"}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCert)
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{RootCAs: roots, NextProtos: []string{"h2"}})
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "h2", conn.ConnectionState().NegotiatedProtocol)
+}
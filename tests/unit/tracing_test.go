@@ -0,0 +1,130 @@
+package unit
+
+import (
+	"context"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"internal-transfers/tracing"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Records spans in memory for the duration of the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+		tracing.RedactAccountIDs, tracing.RedactAmounts = false, false
+	})
+	return recorder
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware_ContinuesIncomingTrace(t *testing.T) {
+	recorder := recordSpans(t)
+	router := mux.NewRouter()
+	router.Use(tracing.Middleware)
+	router.HandleFunc("/api/v1/accounts/{account_id}", func(writer http.ResponseWriter, request *http.Request) {
+		_, span := tracing.Start(request.Context(), "handler")
+		span.End()
+		writer.WriteHeader(http.StatusNotFound)
+	})
+
+	request := httptest.NewRequest("GET", "/api/v1/accounts/7", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	handlerSpan, serverSpan := spans[0], spans[1]
+	assert.Equal(t, "GET /api/v1/accounts/{account_id}", serverSpan.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverSpan.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan.Parent().SpanID().String())
+	assert.Equal(t, int64(404), spanAttribute(serverSpan, "http.response.status_code").AsInt64())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), handlerSpan.Parent().SpanID())
+}
+
+func TestMiddleware_RedactsAccountIDsInPath(t *testing.T) {
+	recorder := recordSpans(t)
+	router := mux.NewRouter()
+	router.Use(tracing.Middleware)
+	router.HandleFunc("/api/v1/accounts/{account_id}", func(writer http.ResponseWriter, request *http.Request) {})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/accounts/7", nil))
+	tracing.RedactAccountIDs = true
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/accounts/8", nil))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "/api/v1/accounts/7", spanAttribute(spans[0], "url.path").AsString())
+	assert.Equal(t, "/api/v1/accounts/{account_id}", spanAttribute(spans[1], "url.path").AsString())
+	assert.Equal(t, "/api/v1/accounts/{account_id}", spanAttribute(spans[1], "http.route").AsString())
+	for _, kv := range spans[1].Attributes() {
+		assert.NotContains(t, kv.Value.Emit(), "8", string(kv.Key))
+	}
+}
+
+func TestPerformTransactionWithContext_SpansServiceAndRepositoryCalls(t *testing.T) {
+	recorder := recordSpans(t)
+	accountRepo := &mocks.MockAccountRepository{
+		MockGetAccountByIDWithContext: func(ctx context.Context, accountID int) (*model.Account, error) {
+			_, span := tracing.Start(ctx, "AccountRepository.GetAccountByID")
+			span.End()
+			return &model.Account{AccountID: accountID, Balance: decimal.NewFromInt(100)}, nil
+		},
+	}
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			return nil
+		},
+	}
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+
+	ctx, parent := tracing.Start(context.Background(), "request")
+	_, err := transactionService.PerformTransactionWithContext(ctx, *model.NewTransaction(1, 2, decimal.NewFromInt(25)))
+	parent.End()
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 4)
+	serviceSpan := spans[2]
+	assert.Equal(t, "TransactionService.PerformTransaction", serviceSpan.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), serviceSpan.Parent().SpanID())
+	assert.Equal(t, serviceSpan.SpanContext().SpanID(), spans[0].Parent().SpanID(), "repository calls are children of the service span")
+	assert.Equal(t, "1", spanAttribute(serviceSpan, "transaction.source_account_id").AsString())
+	assert.Equal(t, "25", spanAttribute(serviceSpan, "transaction.amount").AsString())
+}
+
+func TestTracingAttributes_Redaction(t *testing.T) {
+	recordSpans(t)
+	assert.Equal(t, "42", tracing.AccountID("account.id", 42).Value.AsString())
+
+	tracing.RedactAccountIDs = true
+	tracing.RedactAmounts = true
+	assert.Equal(t, "[redacted]", tracing.AccountID("account.id", 42).Value.AsString())
+	assert.Equal(t, "[redacted]", tracing.Amount("amount", decimal.NewFromInt(10)).Value.AsString())
+}
@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"context"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAccountRepository(accounts ...model.Account) *mocks.MockAccountRepository {
+	return &mocks.MockAccountRepository{
+		MockGetAccountByIDWithContext: func(ctx context.Context, accountID int) (*model.Account, error) {
+			for _, account := range accounts {
+				if account.AccountID == accountID {
+					found := account
+					return &found, nil
+				}
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestPerformTransaction_PostsFeeLeg(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, AccountType: "standard", Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, AccountType: "standard", Balance: decimal.NewFromInt(0)},
+	)
+
+	var postedEntries []model.LedgerEntry
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			transaction.TransactionID = 10
+			postedEntries = entries
+			return nil
+		},
+	}
+
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+	transactionService.FeeService = newFeeService([]model.FeeSchedule{
+		{FeeScheduleID: 1, Name: "flat", FeeType: model.FeeTypeFlat, FlatAmount: decimal.NewFromInt(2)},
+	})
+
+	posted, err := transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(50)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, posted.TransactionID)
+	assert.True(t, decimal.NewFromInt(2).Equal(posted.FeeAmount))
+	assert.Equal(t, 1, posted.FeeBreakdown.FeeScheduleID)
+
+	total := decimal.Zero
+	for _, entry := range postedEntries {
+		total = total.Add(entry.Amount)
+	}
+	assert.Len(t, postedEntries, 4)
+	assert.True(t, total.IsZero(), "Expected ledger legs to balance")
+	assert.Equal(t, 999, postedEntries[3].AccountID)
+}
+
+func TestPerformTransaction_FeeCausesInsufficientBalance(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, AccountType: "standard", Balance: decimal.NewFromInt(50)},
+		model.Account{AccountID: 2, AccountType: "standard", Balance: decimal.NewFromInt(0)},
+	)
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			t.Fatal("transaction should not be posted")
+			return nil
+		},
+	}
+
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+	transactionService.FeeService = newFeeService([]model.FeeSchedule{
+		{FeeScheduleID: 1, Name: "flat", FeeType: model.FeeTypeFlat, FlatAmount: decimal.NewFromInt(1)},
+	})
+
+	_, err := transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(50)))
+
+	assert.Error(t, err, "Expected error because the fee exceeds the remaining balance")
+}
+
+func TestPerformTransaction_RejectsAmountsThatCannotBeStored(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, AccountType: "standard", Balance: decimal.NewFromInt(50)},
+		model.Account{AccountID: 2, AccountType: "standard", Balance: decimal.NewFromInt(0)},
+	)
+	transactionRepo := &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			t.Fatal("transaction should not be posted")
+			return nil
+		},
+	}
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo, &common.AuditLogger{})
+
+	_, err := transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.RequireFromString("0.000001")))
+
+	assert.IsType(t, &common.ValidationError{}, err)
+	assert.Equal(t, "invalid_amount", service.TransferErrorClass(err))
+}
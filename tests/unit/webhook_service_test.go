@@ -0,0 +1,311 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"internal-transfers/common"
+	"internal-transfers/model"
+	"internal-transfers/service"
+	"internal-transfers/tests/mocks"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// A webhook repository with nothing to fan out that hands out the given deliveries on the first claim
+func newDispatchRepository(due ...model.DueWebhookDelivery) *mocks.MockWebhookRepository {
+	claimed := false
+	return &mocks.MockWebhookRepository{
+		MockFanOutEventsWithContext: func(ctx context.Context, limit int) (int, error) {
+			return 0, nil
+		},
+		MockClaimDueDeliveriesWithContext: func(ctx context.Context, limit int, lease time.Duration) ([]model.DueWebhookDelivery, error) {
+			if claimed {
+				return nil, nil
+			}
+			claimed = true
+			return due, nil
+		},
+	}
+}
+
+func newDueDelivery(url string, attempts int) model.DueWebhookDelivery {
+	return model.DueWebhookDelivery{
+		OutboxEvent: model.OutboxEvent{
+			EventID: 42, EventType: model.EventTransferCompleted,
+			Payload: json.RawMessage(`{"transaction_id":7}`), CreatedAt: time.Now(),
+		},
+		DeliveryID: 9, EndpointID: 3, Attempts: attempts, URL: url, Secret: "whsec_test",
+	}
+}
+
+func TestDispatch_DeliversSignedEvent(t *testing.T) {
+	var received model.OutboxEvent
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(service.WebhookTimestampHeader)
+		assert.Equal(t, service.SignWebhook("whsec_test", timestamp, body), r.Header.Get(service.WebhookSignatureHeader))
+		assert.Equal(t, "42", r.Header.Get(service.WebhookEventIDHeader))
+		assert.Equal(t, model.EventTransferCompleted, r.Header.Get(service.WebhookEventTypeHeader))
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	var deliveredID int64
+	var deliveredStatus int
+	webhookRepo := newDispatchRepository(newDueDelivery(receiver.URL, 1))
+	webhookRepo.MockMarkDeliveredWithContext = func(ctx context.Context, deliveryID int64, statusCode int) error {
+		deliveredID, deliveredStatus = deliveryID, statusCode
+		return nil
+	}
+	webhookService := service.NewWebhookService(webhookRepo, nil, time.Second, 3, time.Minute, time.Hour)
+	webhookService.AllowPrivateEndpoints = true
+
+	assert.NoError(t, webhookService.Dispatch(context.Background()))
+
+	assert.Equal(t, int64(9), deliveredID)
+	assert.Equal(t, http.StatusNoContent, deliveredStatus)
+	assert.Equal(t, int64(42), received.EventID)
+	assert.JSONEq(t, `{"transaction_id":7}`, string(received.Payload))
+}
+
+func TestDispatch_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	type failure struct {
+		statusCode *int
+		retryAfter time.Duration
+		dead       bool
+	}
+	failures := map[int]failure{}
+	for _, attempts := range []int{1, 3, 5} {
+		webhookRepo := newDispatchRepository(newDueDelivery(receiver.URL, attempts))
+		webhookRepo.MockMarkFailedWithContext = func(ctx context.Context, deliveryID int64, statusCode *int, message string, retryAfter time.Duration, dead bool) error {
+			failures[attempts] = failure{statusCode, retryAfter, dead}
+			return nil
+		}
+		webhookService := service.NewWebhookService(webhookRepo, nil, time.Second, 5, time.Minute, 3*time.Minute)
+		webhookService.AllowPrivateEndpoints = true
+
+		assert.NoError(t, webhookService.Dispatch(context.Background()))
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, *failures[1].statusCode)
+	assert.Equal(t, time.Minute, failures[1].retryAfter)
+	assert.False(t, failures[1].dead)
+	assert.Equal(t, 3*time.Minute, failures[3].retryAfter, "doubled after each failure, capped at the maximum")
+	assert.False(t, failures[3].dead)
+	assert.True(t, failures[5].dead, "moved to the dead letters after the last attempt")
+}
+
+func TestDispatch_UnreachableEndpointHasNoStatusCode(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiver.Close()
+
+	var recordedStatus *int
+	var recordedMessage string
+	webhookRepo := newDispatchRepository(newDueDelivery(receiver.URL, 1))
+	webhookRepo.MockMarkFailedWithContext = func(ctx context.Context, deliveryID int64, statusCode *int, message string, retryAfter time.Duration, dead bool) error {
+		recordedStatus, recordedMessage = statusCode, message
+		return nil
+	}
+	webhookService := service.NewWebhookService(webhookRepo, nil, time.Second, 5, time.Minute, time.Hour)
+	webhookService.AllowPrivateEndpoints = true
+
+	assert.NoError(t, webhookService.Dispatch(context.Background()))
+
+	assert.Nil(t, recordedStatus)
+	assert.NotEmpty(t, recordedMessage)
+}
+
+func TestCreateWebhookEndpoint_ValidatesAndReturnsSecretOnce(t *testing.T) {
+	var stored model.WebhookEndpoint
+	webhookRepo := &mocks.MockWebhookRepository{
+		MockCreateEndpointWithContext: func(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+			endpoint.EndpointID = 4
+			stored = *endpoint
+			return nil
+		},
+	}
+	webhookService := service.NewWebhookService(webhookRepo, nil, time.Second, 5, time.Minute, time.Hour)
+
+	_, err := webhookService.CreateEndpoint(context.Background(), model.CreateWebhookEndpointInput{URL: "ftp://hooks.example.com"}, "admin")
+	assert.IsType(t, &common.ValidationError{}, err)
+	_, err = webhookService.CreateEndpoint(context.Background(), model.CreateWebhookEndpointInput{URL: "https://hooks.example.com", EventTypes: []string{"Unknown"}}, "admin")
+	assert.IsType(t, &common.ValidationError{}, err)
+
+	created, err := webhookService.CreateEndpoint(context.Background(), model.CreateWebhookEndpointInput{
+		URL:        "https://hooks.example.com/transfers",
+		EventTypes: []string{model.EventTransferFailed, model.EventTransferFailed},
+	}, "admin")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, created.EndpointID)
+	assert.Equal(t, stored.Secret, created.Secret)
+	assert.Contains(t, created.Secret, "whsec_")
+	assert.Equal(t, []string{model.EventTransferFailed}, []string(stored.EventTypes))
+
+	encoded, _ := json.Marshal(stored)
+	assert.NotContains(t, string(encoded), created.Secret, "the secret is not serialized with the endpoint")
+}
+
+func TestCreateWebhookEndpoint_RejectsInternalAddresses(t *testing.T) {
+	webhookRepo := &mocks.MockWebhookRepository{
+		MockCreateEndpointWithContext: func(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+			return nil
+		},
+	}
+	webhookService := service.NewWebhookService(webhookRepo, nil, time.Second, 5, time.Minute, time.Hour)
+
+	for _, url := range []string{
+		"http://hooks.example.com/transfers",
+		"https://127.0.0.1/hook",
+		"https://localhost:8443/hook",
+		"https://10.0.0.8/hook",
+		"https://192.168.1.20/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/hook",
+		"https://[::ffff:127.0.0.1]/hook",
+		"https://[fe80::1]/hook",
+		"https://100.64.0.1/hook",
+	} {
+		_, err := webhookService.CreateEndpoint(context.Background(), model.CreateWebhookEndpointInput{URL: url}, "admin")
+		assert.IsType(t, &common.ValidationError{}, err, url)
+	}
+
+	_, err := webhookService.CreateEndpoint(context.Background(), model.CreateWebhookEndpointInput{URL: "https://93.184.215.14/hook"}, "admin")
+	assert.NoError(t, err)
+
+	webhookService.AllowPrivateEndpoints = true
+	_, err = webhookService.CreateEndpoint(context.Background(), model.CreateWebhookEndpointInput{URL: "http://127.0.0.1:9000/hook"}, "admin")
+	assert.NoError(t, err)
+}
+
+func TestDispatch_RefusesInternalAddressesAndRedirects(t *testing.T) {
+	requests := 0
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer internal.Close()
+	redirecting := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer redirecting.Close()
+
+	var recordedStatus *int
+	webhookRepo := newDispatchRepository(newDueDelivery(internal.URL, 1))
+	webhookRepo.MockMarkFailedWithContext = func(ctx context.Context, deliveryID int64, statusCode *int, message string, retryAfter time.Duration, dead bool) error {
+		recordedStatus = statusCode
+		assert.Contains(t, message, "internal")
+		return nil
+	}
+	webhookService := service.NewWebhookService(webhookRepo, nil, time.Second, 5, time.Minute, time.Hour)
+
+	assert.NoError(t, webhookService.Dispatch(context.Background()))
+	assert.Nil(t, recordedStatus, "no connection is made to an internal address")
+	assert.Zero(t, requests)
+
+	webhookRepo = newDispatchRepository(newDueDelivery(redirecting.URL, 1))
+	webhookRepo.MockMarkFailedWithContext = func(ctx context.Context, deliveryID int64, statusCode *int, message string, retryAfter time.Duration, dead bool) error {
+		recordedStatus = statusCode
+		return nil
+	}
+	webhookService = service.NewWebhookService(webhookRepo, nil, time.Second, 5, time.Minute, time.Hour)
+	webhookService.AllowPrivateEndpoints = true
+
+	assert.NoError(t, webhookService.Dispatch(context.Background()))
+	assert.Equal(t, http.StatusFound, *recordedStatus, "the redirect is not followed")
+	assert.Zero(t, requests)
+}
+
+func TestReplayWebhookEvents_RequiresActiveEndpoint(t *testing.T) {
+	disabledAt := time.Now()
+	replayed := false
+	webhookRepo := &mocks.MockWebhookRepository{
+		MockListEndpointsWithContext: func(ctx context.Context) ([]model.WebhookEndpoint, error) {
+			return []model.WebhookEndpoint{{EndpointID: 1}, {EndpointID: 2, DisabledAt: &disabledAt}}, nil
+		},
+		MockReplayEventsWithContext: func(ctx context.Context, endpointID int, fromEventID int64, toEventID int64) (int, error) {
+			replayed = true
+			return 3, nil
+		},
+	}
+	webhookService := service.NewWebhookService(webhookRepo, nil, time.Second, 5, time.Minute, time.Hour)
+
+	_, _, err := webhookService.ReplayEvents(context.Background(), 1, model.ReplayWebhookEventsInput{FromEventID: 10, ToEventID: 5}, "admin")
+	assert.IsType(t, &common.ValidationError{}, err)
+
+	_, found, err := webhookService.ReplayEvents(context.Background(), 2, model.ReplayWebhookEventsInput{FromEventID: 10}, "admin")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.False(t, replayed)
+
+	queued, found, err := webhookService.ReplayEvents(context.Background(), 1, model.ReplayWebhookEventsInput{FromEventID: 10}, "admin")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 3, queued)
+}
+
+func TestPerformTransaction_RecordsFailedTransferEvent(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, Balance: decimal.NewFromInt(10)},
+		model.Account{AccountID: 2, Balance: decimal.Zero},
+	)
+	var eventType string
+	var payload any
+	transactionService := service.NewTransactionService(accountRepo, &mocks.MockTransactionRepository{}, nil)
+	transactionService.Outbox = &mocks.MockOutboxRepository{
+		MockAddEventWithContext: func(ctx context.Context, recordedType string, recordedPayload any) error {
+			eventType, payload = recordedType, recordedPayload
+			return nil
+		},
+	}
+
+	_, err := transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(50)))
+
+	assert.Error(t, err)
+	assert.Equal(t, model.EventTransferFailed, eventType)
+	failure, ok := payload.(model.TransferFailure)
+	assert.True(t, ok)
+	assert.Equal(t, 1, failure.SourceAccountID)
+	assert.Equal(t, "insufficient_funds", failure.Reason)
+	assert.Equal(t, "the source account has insufficient funds", failure.Error)
+}
+
+func TestPerformTransaction_FailedTransferEventOmitsErrorDetails(t *testing.T) {
+	accountRepo := newAccountRepository(
+		model.Account{AccountID: 1, Balance: decimal.NewFromInt(100)},
+		model.Account{AccountID: 2, Balance: decimal.Zero},
+	)
+	var payload any
+	transactionService := service.NewTransactionService(accountRepo, &mocks.MockTransactionRepository{
+		MockPostTransactionWithContext: func(ctx context.Context, transaction *model.Transaction, entries []model.LedgerEntry) error {
+			return errors.New(`pq: relation "ledger_entries" does not exist at 10.0.3.7:5432`)
+		},
+	}, nil)
+	transactionService.Outbox = &mocks.MockOutboxRepository{
+		MockAddEventWithContext: func(ctx context.Context, recordedType string, recordedPayload any) error {
+			payload = recordedPayload
+			return nil
+		},
+	}
+
+	_, err := transactionService.PerformTransaction(*model.NewTransaction(1, 2, decimal.NewFromInt(50)))
+
+	assert.Error(t, err)
+	failure := payload.(model.TransferFailure)
+	assert.Equal(t, "posting_failed", failure.Reason)
+	assert.Equal(t, "the transfer could not be posted", failure.Error)
+	encoded, _ := json.Marshal(failure)
+	assert.NotContains(t, string(encoded), "ledger_entries")
+	assert.NotContains(t, string(encoded), "10.0.3.7")
+}
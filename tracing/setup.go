@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"internal-transfers/config"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters selectable with tracing.exporter
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Installs W3C trace context propagation and, unless the exporter is none, a tracer provider sending spans to the
+// configured exporter. The returned function flushes buffered spans and closes the exporter.
+func Setup(tracingConfig config.TracingConfig) (func(ctx context.Context) error, error) {
+	RedactAccountIDs = tracingConfig.RedactAccountIDs
+	RedactAmounts = tracingConfig.RedactAmounts
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var closeOutput func() error
+	switch tracingConfig.Exporter {
+	case ExporterNone:
+		return func(ctx context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if tracingConfig.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(tracingConfig.OTLPEndpoint))
+		}
+		otlpExporter, err := otlptracehttp.New(context.Background(), options...)
+		if err != nil {
+			return nil, fmt.Errorf("could not create the OTLP exporter: %w", err)
+		}
+		exporter = otlpExporter
+	case ExporterStdout:
+		stdoutExporter, err := newWriterExporter(os.Stdout)
+		if err != nil {
+			return nil, err
+		}
+		exporter = stdoutExporter
+	case ExporterFile:
+		file, err := os.OpenFile(tracingConfig.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("could not open the trace file: %w", err)
+		}
+		fileExporter, err := newWriterExporter(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		exporter, closeOutput = fileExporter, file.Close
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, expected none, otlp, stdout or file", tracingConfig.Exporter)
+	}
+
+	serviceResource, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(tracingConfig.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("could not build the trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeOutput != nil {
+			closeOutput()
+		}
+		return err
+	}, nil
+}
+
+// Writes spans as one JSON object per line, for local development
+func newWriterExporter(writer io.Writer) (sdktrace.SpanExporter, error) {
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(writer))
+	if err != nil {
+		return nil, fmt.Errorf("could not create the trace writer: %w", err)
+	}
+	return exporter, nil
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Instrumentation name of the spans started by this service
+const tracerName = "internal-transfers"
+
+// Value recorded instead of a redacted attribute, so spans still show the attribute was there
+const redacted = "[redacted]"
+
+// Redaction of span attributes, set from the tracing configuration at startup
+var (
+	RedactAccountIDs = false
+	RedactAmounts    = false
+)
+
+// Starts a span as a child of the span in ctx, a root span when there is none.
+// Spans are dropped when no exporter is configured.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// Starts a server span for a call received over a transport other than HTTP (see Middleware for HTTP),
+// continuing the trace of ctx
+func StartServer(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attributes...))
+}
+
+// Ends the span, recording *err as its failure when set. Meant to be deferred with a named error result:
+//
+//	ctx, span := tracing.Start(ctx, "AccountRepository.GetAccountByID")
+//	defer tracing.End(span, &err)
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+// Account ID attribute, redacted when RedactAccountIDs is set
+func AccountID(key string, accountID int) attribute.KeyValue {
+	if RedactAccountIDs {
+		return attribute.String(key, redacted)
+	}
+	return attribute.String(key, strconv.Itoa(accountID))
+}
+
+// Amount attribute, redacted when RedactAmounts is set. Recorded as a string to keep the exact decimal.
+func Amount(key string, amount decimal.Decimal) attribute.KeyValue {
+	if RedactAmounts {
+		return attribute.String(key, redacted)
+	}
+	return attribute.String(key, amount.String())
+}